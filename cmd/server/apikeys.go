@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+)
+
+const apiKeysUsage = `Usage:
+  go-notify apikeys issue -name <name> -scopes <scope>[,<scope>...]
+  go-notify apikeys list
+  go-notify apikeys revoke <key ID>
+
+Scopes: messages:write, messages:read, scheduler:admin`
+
+// runAPIKeysCommand runs the apikeys subcommand, which issues, lists and revokes API keys, and returns its exit code.
+func runAPIKeysCommand(ctx context.Context, service *apikeys.Service, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, apiKeysUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "issue":
+		flags := flag.NewFlagSet("apikeys issue", flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		name := flags.String("name", "", "name of the client holding the key")
+		scopes := flags.String("scopes", "", "comma separated scopes granted to the key")
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		key, secret, err := service.IssueKey(ctx, *name, strings.Split(*scopes, ","))
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Issued API key %s (%s) with scopes %s.\n", key.ID, key.Name, joinScopes(key.Scopes))
+		fmt.Fprintf(stdout, "Key: %s\n", secret)
+		fmt.Fprintln(stdout, "Store it now, it cannot be shown again.")
+		return 0
+
+	case "list":
+		keys, err := service.ListKeys(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tREVOKED")
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\n", key.ID, key.Name, key.Prefix, joinScopes(key.Scopes),
+				key.CreatedAt.Format("2006-01-02 15:04"), key.Revoked)
+		}
+		tw.Flush()
+		return 0
+
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Fprintln(stderr, apiKeysUsage)
+			return 2
+		}
+		if err := service.RevokeKey(ctx, args[1]); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Revoked API key %s.\n", args[1])
+		return 0
+
+	default:
+		fmt.Fprintln(stderr, apiKeysUsage)
+		return 2
+	}
+}
+
+func joinScopes(scopes []apikeys.Scope) string {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	return strings.Join(names, ",")
+}
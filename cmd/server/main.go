@@ -11,15 +11,24 @@ import (
 	"time"
 
 	"github.com/akshaysangma/go-notify/external/redis"
+	"github.com/akshaysangma/go-notify/external/smtp"
 	"github.com/akshaysangma/go-notify/external/webhook"
 	"github.com/akshaysangma/go-notify/internal/api"
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+	"github.com/akshaysangma/go-notify/internal/breaker"
+	"github.com/akshaysangma/go-notify/internal/callbacks"
 	"github.com/akshaysangma/go-notify/internal/config"
 	"github.com/akshaysangma/go-notify/internal/database"
 	"github.com/akshaysangma/go-notify/internal/database/postgres"
 	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/akshaysangma/go-notify/internal/ratelimit"
 	"github.com/akshaysangma/go-notify/internal/scheduler"
+	"github.com/akshaysangma/go-notify/internal/templates"
 
 	"go.uber.org/zap"
+
+	// Time zone data for quiet hours, in case the image has none.
+	_ "time/tzdata"
 )
 
 // @title Go Notify API
@@ -36,6 +45,11 @@ import (
 
 // @host localhost:8080
 // @BasePath /
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued with `go-notify apikeys issue`; may also be sent as "Authorization: Bearer <key>".
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -68,27 +82,151 @@ func main() {
 	workerPoolSize = min(cfg.Scheduler.MessageRate, workerPoolSize)
 
 	// Intialize Repositories
-	msgRepo, err := database.NewPostgresMessageRepository(pgPool)
+	// Callbacks the repository queues itself, for cancellations and expired leases, are disabled with callbacks.
+	callbackAttempts := 0
+	if cfg.Callbacks.SigningSecret != "" {
+		callbackAttempts = cfg.Callbacks.MaxAttempts
+	}
+	msgRepo, err := database.NewPostgresMessageRepository(pgPool, callbackAttempts)
 	if err != nil {
 		logger.Fatal("failed to initialize message repository", zap.Error(err))
 	}
+	idempotencyRepo, err := database.NewPostgresIdempotencyRepository(pgPool)
+	if err != nil {
+		logger.Fatal("failed to initialize idempotency repository", zap.Error(err))
+	}
+	templateRepo, err := database.NewPostgresTemplateRepository(pgPool)
+	if err != nil {
+		logger.Fatal("failed to initialize template repository", zap.Error(err))
+	}
+	callbackRepo, err := database.NewPostgresCallbackRepository(pgPool)
+	if err != nil {
+		logger.Fatal("failed to initialize callback repository", zap.Error(err))
+	}
+	apiKeyRepo, err := database.NewPostgresAPIKeyRepository(pgPool)
+	if err != nil {
+		logger.Fatal("failed to initialize API key repository", zap.Error(err))
+	}
+	apiKeyService := apikeys.NewService(apiKeyRepo, logger)
+
+	// Manage API keys instead of serving when run as `go-notify apikeys ...`
+	if len(os.Args) > 1 && os.Args[1] == "apikeys" {
+		code := runAPIKeysCommand(context.Background(), apiKeyService, os.Args[2:], os.Stdout, os.Stderr)
+		pgPool.Close()
+		logger.Sync()
+		os.Exit(code)
+	}
 
 	// Intialize external clients
-	webhookSiteSenderClient := webhook.NewWebhookSiteSender(cfg.Webhook.URL, cfg.Webhook.CharacterLimit, cfg.Server.WriteTimeout)
+	webhookSigner := webhook.NewSigner(cfg.Webhook.SigningSecret, cfg.Webhook.PreviousSigningSecret)
+	webhookProviders := make([]webhook.Provider, 0, len(cfg.Webhook.Providers))
+	for _, p := range cfg.Webhook.Providers {
+		mapping, err := webhook.NewPayloadMapping(p.BodyTemplate, p.ContentType, p.SuccessStatusCodes, p.ExternalIDPath)
+		if err != nil {
+			logger.Fatal("invalid webhook provider payload", zap.String("provider", p.Name), zap.Error(err))
+		}
+		var providerSender messages.Sender = webhook.NewWebhookSiteSender(p.URL, cfg.Webhook.CharacterLimit, cfg.Server.WriteTimeout, webhookSigner, mapping)
+		if p.SendRate > 0 {
+			providerSender = ratelimit.NewSender(providerSender, ratelimit.NewTokenBucket(p.SendRate, p.SendBurst))
+		}
+		webhookProviders = append(webhookProviders, webhook.Provider{
+			Name:     p.Name,
+			Sender:   providerSender,
+			Weight:   p.Weight,
+			Priority: p.Priority,
+		})
+	}
+	smsSender := webhook.NewFailoverSender(webhookProviders, cfg.Webhook.UnhealthyAfter, cfg.Webhook.HealthCooldown)
+	smsBreaker := breaker.New(messages.ChannelSMS, cfg.Webhook.CircuitBreaker.FailureThreshold, cfg.Webhook.CircuitBreaker.Cooldown, cfg.Webhook.CircuitBreaker.HalfOpenRequests)
+	smsBreakerSender := breaker.NewSender(smsSender, smsBreaker)
+	senders := messages.NewSenderRegistry()
+	senders.Register(messages.ChannelSMS, smsBreakerSender)
+	if cfg.Chat.WebhookURL != "" {
+		senders.Register(messages.ChannelChat, webhook.NewChatSender(cfg.Chat.WebhookURL, cfg.Server.WriteTimeout))
+	}
+	if cfg.SMTP.Host != "" {
+		smtpSender, err := smtp.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From, cfg.SMTP.StartTLS, cfg.Server.WriteTimeout)
+		if err != nil {
+			logger.Fatal("failed to initialize smtp sender", zap.Error(err))
+		}
+		senders.Register(messages.ChannelEmail, smtpSender)
+	}
+	logger.Info("Registered delivery channels", zap.Strings("channels", senders.Channels()))
 	redisClient := redis.NewRedisService(cfg.Redis.Address, logger)
 
 	// Intialize services
-	msgService := messages.NewMessageService(msgRepo, webhookSiteSenderClient, logger, redisClient, workerPoolSize, cfg.Scheduler.JobTimeout)
+	retryPolicy := messages.RetryPolicy{
+		MaxAttempts: cfg.Scheduler.MaxAttempts,
+		BaseDelay:   cfg.Scheduler.RetryBaseDelay,
+		MaxDelay:    cfg.Scheduler.RetryMaxDelay,
+		Jitter:      cfg.Scheduler.RetryJitter,
+	}
+	var sendLimiter messages.RateLimiter
+	if cfg.Scheduler.SendRate > 0 {
+		sendLimiter = ratelimit.NewTokenBucket(cfg.Scheduler.SendRate, cfg.Scheduler.SendBurst)
+	}
+	quietHours, err := messages.ParseQuietHours(cfg.Recipients.QuietHoursStart, cfg.Recipients.QuietHoursEnd)
+	if err != nil {
+		logger.Fatal("invalid quiet hours", zap.Error(err))
+	}
+	defaultLocation, err := time.LoadLocation(cfg.Recipients.DefaultTimezone)
+	if err != nil {
+		logger.Fatal("invalid recipients default time zone", zap.Error(err))
+	}
+	recipientPolicy := messages.RecipientPolicy{
+		MaxPerWindow:    cfg.Recipients.MaxPerWindow,
+		Window:          cfg.Recipients.Window,
+		QuietHours:      quietHours,
+		DefaultLocation: defaultLocation,
+		DedupWindow:     cfg.Recipients.DedupWindow,
+	}
+	templateService := templates.NewService(templateRepo, logger)
+	// Without a signing secret callbacks are disabled, and messages asking for one are rejected.
+	var statusNotifier messages.StatusNotifier
+	var callbackService *callbacks.Service
+	if cfg.Callbacks.SigningSecret != "" {
+		callbackRetryPolicy := messages.RetryPolicy{
+			MaxAttempts: cfg.Callbacks.MaxAttempts,
+			BaseDelay:   cfg.Callbacks.RetryBaseDelay,
+			MaxDelay:    cfg.Callbacks.RetryMaxDelay,
+			Jitter:      cfg.Callbacks.RetryJitter,
+		}
+		callbackSigner := webhook.NewSigner(cfg.Callbacks.SigningSecret, cfg.Callbacks.PreviousSigningSecret)
+		callbackSender := webhook.NewCallbackSender(callbackSigner, cfg.Callbacks.Timeout)
+		callbackService = callbacks.NewService(callbackRepo, callbackSender, logger, cfg.Scheduler.InstanceID, cfg.Callbacks.LeaseDuration, callbackRetryPolicy)
+		statusNotifier = callbackService
+	}
+	msgService := messages.NewMessageService(msgRepo, senders, logger, redisClient, workerPoolSize, cfg.Scheduler.JobTimeout, cfg.Scheduler.InstanceID, cfg.Scheduler.LeaseDuration, retryPolicy, cfg.Scheduler.ScheduleHorizon, cfg.Scheduler.PriorityReserve, sendLimiter, redisClient, recipientPolicy, templateService, statusNotifier)
 	msgdispatchScheduler := scheduler.NewMessageDispatchSchedulerImpl(msgService, logger, cfg.Scheduler)
 	logger.Info("Starting message dispatching scheduler...")
 	msgdispatchScheduler.Start()
+	recoverySweeper := scheduler.NewRecoverySweeper(msgService, idempotencyRepo, logger, cfg.Scheduler)
+	recoverySweeper.Start()
+	var callbackDispatcher *scheduler.CallbackDispatcher
+	if callbackService != nil {
+		callbackDispatcher = scheduler.NewCallbackDispatcher(callbackService, logger, cfg.Callbacks)
+		callbackDispatcher.Start()
+	}
 
 	// Intialize http handlers
 	messageH := api.NewMessageHandler(msgService, cfg.Webhook.CharacterLimit, logger)
-	schedulerH := api.NewSchedulerHandler(msgdispatchScheduler, logger)
+	schedulerH := api.NewSchedulerHandler(msgdispatchScheduler, recoverySweeper, smsSender, smsBreakerSender, logger)
+	templateH := api.NewTemplateHandler(templateService, logger)
+	receiptSecrets := make(map[string][]string, len(cfg.Webhook.Providers))
+	for _, p := range cfg.Webhook.Providers {
+		if p.ReceiptSecret != "" {
+			receiptSecrets[p.Name] = []string{p.ReceiptSecret, p.PreviousReceiptSecret}
+		}
+	}
+	receiptH := api.NewReceiptHandler(msgService, receiptSecrets, logger)
+	idempotencyM := api.NewIdempotencyMiddleware(idempotencyRepo, cfg.Server.IdempotencyWindow, cfg.Server.IdempotencyLockTimeout, logger)
+	if cfg.Server.DisableAuth {
+		logger.Warn("API key authentication is disabled, every client API is open to anyone reaching the server")
+	}
+	authM := api.NewAuthMiddleware(apiKeyService, cfg.Server.DisableAuth, logger)
 
 	mux := http.NewServeMux()
-	routes := api.NewRouterDependecies(mux, messageH, schedulerH, logger)
+	routes := api.NewRouterDependecies(mux, messageH, schedulerH, templateH, receiptH, idempotencyM, authM, logger)
 	routes.RegisterRoutes()
 
 	server := &http.Server{
@@ -127,6 +265,15 @@ func main() {
 		logger.Info("Message scheduler was not running.")
 	}
 
+	if err := recoverySweeper.Stop(); err != nil {
+		logger.Error("Error stopping recovery sweeper", zap.Error(err))
+	}
+	if callbackDispatcher != nil {
+		if err := callbackDispatcher.Stop(); err != nil {
+			logger.Error("Error stopping callback dispatcher", zap.Error(err))
+		}
+	}
+
 	// Shut down the HTTP server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.GracePeriod)
 	defer shutdownCancel()
@@ -25,8 +25,113 @@ const docTemplate = `{
     "basePath": "{{.BasePath}}",
     "paths": {
         "/api/v1/messages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets messages matching the filters, most recently updated first, using keyset pagination on (updated_at, id).\nTime filters are RFC 3339 timestamps; the lower bounds are inclusive and the upper bounds exclusive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Query messages",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Statuses to include (pending, sending, sent, failed, cancelled, delivered, undelivered)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipient phone number",
+                        "name": "recipient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "External message ID returned by the provider",
+                        "name": "external_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Created at or after",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Created before",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Updated at or after",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Updated before",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of messages to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "A page of messages",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListMessagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve messages",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Creates a new message with the same content for a list of recipient phone numbers.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new message with the same content for a list of recipient phone numbers.\nReturns the batch ID and the ID of the message created for each recipient, for later status polling.\nMessages with a send_at are held until that time, which may not be in the past or beyond the scheduling horizon.\nWith a template_id, the template is rendered with the variables of each recipient and the result must fit the character limit.\nWith deduplication enabled, recipients that already have a message with the same content are listed under deduplicated instead.\nWith a callback_url, a signed status event is posted to it once each message is sent or has failed for good.",
                 "consumes": [
                     "application/json"
                 ],
@@ -38,6 +143,12 @@ const docTemplate = `{
                 ],
                 "summary": "Create a message for multiple recipients",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Message Content and Recipients",
                         "name": "message",
@@ -52,7 +163,7 @@ const docTemplate = `{
                     "202": {
                         "description": "Messages have been accepted for processing",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "$ref": "#/definitions/api.CreateMessagesResponse"
                         }
                     },
                     "400": {
@@ -61,6 +172,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was already used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Failed to save messages to the database",
                         "schema": {
@@ -70,16 +193,73 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/messages/sent": {
+        "/api/v1/messages/batches/{batch_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancels every pending or scheduled message created by the same request. Messages already being sent are left alone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Cancel a batch of messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch ID",
+                        "name": "batch_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of cancelled messages",
+                        "schema": {
+                            "$ref": "#/definitions/api.CancelBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid batch ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Batch not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to cancel batch",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/dead-letter": {
             "get": {
-                "description": "Gets a paginated list of all messages that have been successfully sent.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a paginated list of messages that exhausted their send attempts, including their attempt history.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "messages"
                 ],
-                "summary": "Retrieve a list of sent messages",
+                "summary": "Retrieve a list of dead-lettered messages",
                 "parameters": [
                     {
                         "type": "integer",
@@ -98,16 +278,16 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "A list of sent messages",
+                        "description": "A list of dead-lettered messages",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/messages.Message"
+                                "$ref": "#/definitions/messages.DeadLetter"
                             }
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve sent messages",
+                        "description": "Failed to retrieve dead-letter messages",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
@@ -115,99 +295,828 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/scheduler": {
+        "/api/v1/messages/dead-letter/{id}/requeue": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a message from the dead-letter queue and returns it to 'pending' with a fresh set of attempts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Requeue a dead-lettered message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Message requeued for delivery",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Message is not in the dead-letter queue",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to requeue message",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/sent": {
             "get": {
-                "description": "Returns whether the scheduler is currently running or stopped.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a paginated list of all messages that have been successfully sent.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduler"
+                    "messages"
+                ],
+                "summary": "Retrieve a list of sent messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of messages to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get the current status of the scheduler",
                 "responses": {
                     "200": {
-                        "description": "Current status of the scheduler",
+                        "description": "A list of sent messages",
                         "schema": {
-                            "$ref": "#/definitions/api.SchedulerStatusResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/messages.Message"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve sent messages",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Activates or deactivates the scheduler based on the 'action' query parameter.",
+            }
+        },
+        "/api/v1/messages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a single message with its current status, external ID, last failure reason, timestamps and delivery attempt history.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduler"
+                    "messages"
                 ],
-                "summary": "Control the message sending scheduler (start/stop)",
+                "summary": "Retrieve a message by ID",
                 "parameters": [
                     {
-                        "enum": [
-                            "start",
-                            "stop"
-                        ],
                         "type": "string",
-                        "description": "The action to perform: 'start' or 'stop'",
-                        "name": "action",
-                        "in": "query",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Scheduler has stopped sucessfully.",
-                        "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
-                        }
-                    },
-                    "202": {
-                        "description": "Scheduler start signal sent.",
+                        "description": "The requested message",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "$ref": "#/definitions/messages.Message"
                         }
                     },
                     "400": {
-                        "description": "Invalid or missing 'action' parameter",
+                        "description": "Invalid message ID",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
-                    "409": {
-                        "description": "Scheduler is already in the desired state",
+                    "404": {
+                        "description": "Message not found",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     },
                     "500": {
-                        "description": "Internal server error while performing the action",
+                        "description": "Failed to retrieve message",
                         "schema": {
                             "$ref": "#/definitions/api.HTTPError"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "api.CreateMessagesRequest": {
-            "type": "object",
-            "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "This is a message for multiple users."
-                },
-                "recipients": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "['+15551112222'",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancels a pending or scheduled message. Messages that are being sent, sent or failed cannot be cancelled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Cancel a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The cancelled message",
+                        "schema": {
+                            "$ref": "#/definitions/messages.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid message ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is no longer pending",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to cancel message",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/providers/{provider}/receipts": {
+            "post": {
+                "description": "Moves the sent message the provider accepted under external_message_id to delivered or undelivered.\nThe request must carry X-Timestamp (Unix seconds, within 5 minutes of now) and X-Signature, the hex HMAC-SHA256\nof \"\u003ctimestamp\u003e.\u003cbody\u003e\" keyed with a receipt secret of the provider, or a comma separated list of such signatures.\nA repeated receipt is accepted without changes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "Apply a delivery receipt from a provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix time the receipt was signed at",
+                        "name": "X-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hex HMAC-SHA256 of the timestamp and body",
+                        "name": "X-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Delivery receipt",
+                        "name": "receipt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DeliveryReceiptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Receipt applied",
+                        "schema": {
+                            "$ref": "#/definitions/api.DeliveryReceiptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or receipt",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown provider or message",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is not awaiting a delivery receipt",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to apply receipt",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduler": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns whether the scheduler is currently running or stopped, along with recovery sweeper counters, the health of the sms providers and the state of their circuit breaker.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Get the current status of the scheduler",
+                "responses": {
+                    "200": {
+                        "description": "Current status of the scheduler",
+                        "schema": {
+                            "$ref": "#/definitions/api.SchedulerStatusResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Activates or deactivates the scheduler based on the 'action' query parameter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Control the message sending scheduler (start/stop)",
+                "parameters": [
+                    {
+                        "enum": [
+                            "start",
+                            "stop"
+                        ],
+                        "type": "string",
+                        "description": "The action to perform: 'start' or 'stop'",
+                        "name": "action",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Scheduler has stopped sucessfully.",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Scheduler start signal sent.",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or missing 'action' parameter",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduler is already in the desired state",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error while performing the action",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/templates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a paginated list of templates ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Retrieve a list of message templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of templates to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "A list of templates",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/templates.Template"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve templates",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stores a template that messages can be created from by passing its ID as template_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create a message template",
+                "parameters": [
+                    {
+                        "description": "Template name, subject and body",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created template",
+                        "schema": {
+                            "$ref": "#/definitions/templates.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or template",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Template name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create template",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Retrieve a message template by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The requested template",
+                        "schema": {
+                            "$ref": "#/definitions/templates.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid template ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve template",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the name, subject and body of a template. Messages already created from it are not changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Replace a message template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template name, subject and body",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The updated template",
+                        "schema": {
+                            "$ref": "#/definitions/templates.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid template ID, request body or template",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Template name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update template",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a template. Messages already created from it are not affected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Delete a message template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Template deleted",
+                        "schema": {
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid template ID",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete template",
+                        "schema": {
+                            "$ref": "#/definitions/api.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.CancelBatchResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string",
+                    "example": "f0e1d2c3-b4a5-6789-0123-456789abcdef"
+                },
+                "cancelled": {
+                    "description": "Number of pending messages cancelled. Messages already being sent or sent are not affected.",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.CreateMessagesRequest": {
+            "type": "object",
+            "properties": {
+                "callback_url": {
+                    "description": "Optional http(s) URL that is posted a signed event once each message is sent or has failed for good.",
+                    "type": "string",
+                    "example": "https://example.com/hooks/notify"
+                },
+                "channel": {
+                    "description": "Optional delivery channel: sms (default), email or chat. Recipients are addresses on that channel.",
+                    "type": "string",
+                    "example": "sms"
+                },
+                "content": {
+                    "description": "The content sent to every recipient. Leave empty when template_id is set.",
+                    "type": "string",
+                    "example": "This is a message for multiple users."
+                },
+                "priority": {
+                    "description": "Optional dispatch priority: critical, high, normal (default) or bulk.",
+                    "type": "string",
+                    "enum": [
+                        "critical",
+                        "high",
+                        "normal",
+                        "bulk"
+                    ],
+                    "example": "normal"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "['+15551112222'",
                         " '+15553334444']"
                     ]
+                },
+                "send_at": {
+                    "description": "Optional RFC 3339 time before which the messages are not sent.",
+                    "type": "string",
+                    "example": "2025-07-10T09:00:00Z"
+                },
+                "subject": {
+                    "description": "Optional subject line, used by channels that carry one such as email.",
+                    "type": "string",
+                    "example": "Appointment confirmed"
+                },
+                "template_id": {
+                    "description": "Optional ID of a template to render the content, and the subject if none is given, from.",
+                    "type": "string",
+                    "example": "c3d4e5f6-a7b8-9012-3456-7890abcdef12"
+                },
+                "timezone": {
+                    "description": "Optional IANA time zone of the recipients, used for quiet hours. The configured default applies if empty.",
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
+                "variables": {
+                    "description": "Template variables of each recipient, keyed by recipient.",
+                    "type": "object"
+                }
+            }
+        },
+        "api.CreateMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string",
+                    "example": "f0e1d2c3-b4a5-6789-0123-456789abcdef"
+                },
+                "deduplicated": {
+                    "description": "Recipients skipped by deduplication, when it is enabled.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.DeduplicatedMessage"
+                    }
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CreatedMessage"
+                    }
+                }
+            }
+        },
+        "api.CreatedMessage": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "+15551112222"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                }
+            }
+        },
+        "api.DeduplicatedMessage": {
+            "type": "object",
+            "properties": {
+                "duplicate_of": {
+                    "description": "ID of the existing message with the same content.",
+                    "type": "string",
+                    "example": "b2c3d4e5-f6a7-8901-2345-67890abcdef1"
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "+15553334444"
+                }
+            }
+        },
+        "api.DeliveryReceiptRequest": {
+            "type": "object",
+            "properties": {
+                "external_message_id": {
+                    "description": "The ID the provider returned when it accepted the message.",
+                    "type": "string",
+                    "example": "ext-msg-12345"
+                },
+                "reason": {
+                    "description": "Optional reason the message was not delivered.",
+                    "type": "string",
+                    "example": "handset unreachable"
+                },
+                "status": {
+                    "description": "The delivery state of the message: delivered or undelivered.",
+                    "type": "string",
+                    "enum": [
+                        "delivered",
+                        "undelivered"
+                    ],
+                    "example": "delivered"
+                }
+            }
+        },
+        "api.DeliveryReceiptResponse": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "delivered"
                 }
             }
         },
@@ -224,9 +1133,50 @@ const docTemplate = `{
                 }
             }
         },
+        "api.ListMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/messages.Message"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Pass as the cursor query parameter to fetch the next page. Omitted on the last page.",
+                    "type": "string",
+                    "example": "MjAyNS0wNy0wOVQxMDowMTowMFosYTFiMmMzZDQtZTVmNi03ODkwLTEyMzQtNTY3ODkwYWJjZGVm"
+                }
+            }
+        },
         "api.SchedulerStatusResponse": {
             "type": "object",
             "properties": {
+                "circuit_breaker": {
+                    "description": "State of the sms circuit breaker. While it is open, messages are left pending.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/breaker.Stats"
+                        }
+                    ]
+                },
+                "providers": {
+                    "description": "Health of the sms providers, by priority.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/messages.ProviderHealth"
+                    }
+                },
+                "recovered_messages": {
+                    "description": "Messages returned to 'pending' after their sending lease expired, since startup.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "recovery_failed_messages": {
+                    "description": "Messages marked 'failed' after exceeding the maximum number of recoveries, since startup.",
+                    "type": "integer",
+                    "example": 0
+                },
                 "status": {
                     "type": "string",
                     "example": "running"
@@ -242,9 +1192,188 @@ const docTemplate = `{
                 }
             }
         },
+        "api.TemplateRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Hi {{.name}}, see you on {{.date}}."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "appointment-reminder"
+                },
+                "subject": {
+                    "description": "Optional subject line, used by channels that carry one such as email.",
+                    "type": "string",
+                    "example": "Your appointment on {{.date}}"
+                }
+            }
+        },
+        "breaker.State": {
+            "type": "string",
+            "enum": [
+                "closed",
+                "open",
+                "half-open"
+            ],
+            "x-enum-varnames": [
+                "StateClosed",
+                "StateOpen",
+                "StateHalfOpen"
+            ]
+        },
+        "breaker.Stats": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "description": "The number of failures since the last success.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "description": "The name of the breaker.",
+                    "type": "string",
+                    "example": "sms"
+                },
+                "opened_at": {
+                    "description": "The timestamp the breaker last opened, if it ever did.",
+                    "type": "string",
+                    "example": "2025-07-09T10:01:00Z"
+                },
+                "opens": {
+                    "description": "The number of times the breaker opened since startup.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "state": {
+                    "description": "The current state: closed, open or half-open.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/breaker.State"
+                        }
+                    ],
+                    "example": "closed"
+                }
+            }
+        },
+        "messages.Attempt": {
+            "type": "object",
+            "properties": {
+                "attempt_number": {
+                    "description": "The attempt number, starting at 1.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "attempted_at": {
+                    "description": "The timestamp of the attempt.",
+                    "type": "string",
+                    "example": "2025-07-09T10:01:00Z"
+                },
+                "error": {
+                    "description": "The error returned by the provider, if any.",
+                    "type": "string",
+                    "example": "webhook responded with non-202 status code: 503"
+                },
+                "external_message_id": {
+                    "description": "The ID returned from the external webhook service, if any.",
+                    "type": "string",
+                    "example": "ext-msg-12345"
+                },
+                "instance_id": {
+                    "description": "The scheduler instance that made the attempt.",
+                    "type": "string",
+                    "example": "go-notify-7d9f-1"
+                },
+                "message_id": {
+                    "description": "The message the attempt belongs to.",
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "provider": {
+                    "description": "The provider the attempt ended on, for channels spread over several providers.",
+                    "type": "string",
+                    "example": "primary"
+                },
+                "succeeded": {
+                    "description": "Whether the provider accepted the message.",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "messages.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempt_count": {
+                    "description": "The number of attempts made before giving up.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "attempts": {
+                    "description": "The full history of send attempts.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/messages.Attempt"
+                    }
+                },
+                "content": {
+                    "description": "The content of the failed message.",
+                    "type": "string",
+                    "example": "Your appointment is confirmed."
+                },
+                "dead_lettered_at": {
+                    "description": "The timestamp when the message was moved to the dead-letter queue.",
+                    "type": "string",
+                    "example": "2025-07-09T11:00:00Z"
+                },
+                "failure_reason": {
+                    "description": "The reason of the final failure.",
+                    "type": "string",
+                    "example": "webhook send failed: 503 service unavailable"
+                },
+                "message_id": {
+                    "description": "The ID of the failed message.",
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+                },
+                "recipient": {
+                    "description": "The phone number of the recipient.",
+                    "type": "string",
+                    "example": "+15551234567"
+                }
+            }
+        },
         "messages.Message": {
             "type": "object",
             "properties": {
+                "attempt_count": {
+                    "description": "The number of send attempts made so far.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "attempts": {
+                    "description": "The history of send attempts, only populated when a single message is fetched.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/messages.Attempt"
+                    }
+                },
+                "batch_id": {
+                    "description": "The ID of the batch the message was created in.",
+                    "type": "string",
+                    "example": "f0e1d2c3-b4a5-6789-0123-456789abcdef"
+                },
+                "callback_url": {
+                    "description": "The URL notified when the message is sent or fails for good.",
+                    "type": "string",
+                    "example": "https://example.com/hooks/notify"
+                },
+                "channel": {
+                    "description": "The channel the message is delivered over, e.g. sms or chat.",
+                    "type": "string",
+                    "example": "sms"
+                },
                 "content": {
                     "description": "The content of the message to be sent. Should not exceed content length limit.",
                     "type": "string",
@@ -255,8 +1384,13 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-07-09T10:00:00Z"
                 },
+                "deferred_reason": {
+                    "description": "Why the pending message is held back without being attempted, e.g. quiet hours or a per-recipient limit.",
+                    "type": "string",
+                    "example": "quiet hours in Europe/Berlin until 2025-07-10T08:00:00+02:00"
+                },
                 "external_message_id": {
-                    "description": "The ID returned from the external webhook service.",
+                    "description": "The ID returned by the provider of the channel, if it returns one.",
                     "type": "string",
                     "example": "ext-msg-12345"
                 },
@@ -270,22 +1404,145 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Webhook provider timed out"
                 },
+                "max_attempts": {
+                    "description": "The number of send attempts allowed before the message is marked as failed.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "next_attempt_at": {
+                    "description": "The earliest time the next send attempt may happen, set when a retry is scheduled or the message is deferred.",
+                    "type": "string",
+                    "example": "2025-07-09T10:05:00Z"
+                },
+                "priority": {
+                    "description": "The dispatch priority: critical, high, normal or bulk.",
+                    "type": "string",
+                    "example": "normal"
+                },
+                "provider": {
+                    "description": "The name of the provider that accepted the message, for channels spread over several providers.",
+                    "type": "string",
+                    "example": "primary"
+                },
                 "recipient": {
-                    "description": "The phone number of the recipient.",
+                    "description": "The recipient address on the channel, e.g. a phone number for sms.",
                     "type": "string",
                     "example": "+15551234567"
                 },
+                "send_at": {
+                    "description": "The earliest time the message may be sent. Messages without it are sent on the next scheduler run.",
+                    "type": "string",
+                    "example": "2025-07-10T09:00:00Z"
+                },
                 "status": {
                     "description": "The current status of the message.",
                     "type": "string",
                     "example": "sent"
                 },
+                "subject": {
+                    "description": "The subject line, for channels that carry one such as email.",
+                    "type": "string",
+                    "example": "Appointment confirmed"
+                },
+                "timezone": {
+                    "description": "The IANA time zone of the recipient, used for quiet hours. The configured default applies if empty.",
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
                 "updated_at": {
                     "description": "The timestamp when the message was last updated.",
                     "type": "string",
                     "example": "2025-07-09T10:01:00Z"
                 }
             }
+        },
+        "messages.ProviderHealth": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "description": "The number of retryable failures since the last successful send.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "healthy": {
+                    "description": "Whether the provider is tried in its turn; unhealthy providers are only tried as a last resort.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_error": {
+                    "description": "The error of the last failure, if any.",
+                    "type": "string",
+                    "example": "webhook responded with non-202 status code: 503"
+                },
+                "last_failure_at": {
+                    "description": "The timestamp of the last failure, if any.",
+                    "type": "string",
+                    "example": "2025-07-09T10:01:00Z"
+                },
+                "last_success_at": {
+                    "description": "The timestamp of the last successful send, if any.",
+                    "type": "string",
+                    "example": "2025-07-09T10:02:00Z"
+                },
+                "name": {
+                    "description": "The name of the provider.",
+                    "type": "string",
+                    "example": "primary"
+                },
+                "priority": {
+                    "description": "Providers with the lowest priority are tried first.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "weight": {
+                    "description": "The share of traffic among providers of the same priority.",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "templates.Template": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "description": "The message content.",
+                    "type": "string",
+                    "example": "Hi {{.name}}, see you on {{.date}}."
+                },
+                "created_at": {
+                    "description": "The timestamp when the template was created.",
+                    "type": "string",
+                    "example": "2025-07-09T10:00:00Z"
+                },
+                "id": {
+                    "description": "The unique identifier for the template.",
+                    "type": "string",
+                    "example": "c3d4e5f6-a7b8-9012-3456-7890abcdef12"
+                },
+                "name": {
+                    "description": "The unique name of the template.",
+                    "type": "string",
+                    "example": "appointment-reminder"
+                },
+                "subject": {
+                    "description": "The optional subject line, for channels that carry one such as email.",
+                    "type": "string",
+                    "example": "Your appointment on {{.date}}"
+                },
+                "updated_at": {
+                    "description": "The timestamp when the template was last updated.",
+                    "type": "string",
+                    "example": "2025-07-09T10:01:00Z"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key issued with ` + "`" + `go-notify apikeys issue` + "`" + `; may also be sent as \"Authorization: Bearer \u003ckey\u003e\".",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
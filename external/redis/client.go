@@ -15,6 +15,9 @@ import (
 type RedisClientInterface interface {
 	Ping(ctx context.Context) *redis.StatusCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Incr(ctx context.Context, key string) *redis.IntCmd
+	Decr(ctx context.Context, key string) *redis.IntCmd
+	ExpireAt(ctx context.Context, key string, tm time.Time) *redis.BoolCmd
 }
 
 type RedisService struct {
@@ -67,3 +70,53 @@ func (r *RedisService) CacheSentMessage(ctx context.Context, messageID, external
 	r.logger.Debug("Successfully cached sent message", zap.String("message_id", messageID), zap.String("external_id", externalMessageID))
 	return nil
 }
+
+// CountRecipientSend increments the number of sends to recipient on channel in the window
+// starting at windowStart and returns the new count. The counter expires when the window ends.
+func (r *RedisService) CountRecipientSend(ctx context.Context, channel, recipient string, windowStart time.Time, window time.Duration) (int64, error) {
+	key := recipientSendsKey(channel, recipient, windowStart)
+
+	if r.client == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sends to recipient %s: %w", recipient, err)
+	}
+	if count == 1 {
+		// The key names its window, so a missing expiry only leaks memory; the count stays correct.
+		if err := r.client.ExpireAt(ctx, key, windowStart.Add(window)).Err(); err != nil {
+			r.logger.Warn("Failed to set expiry of recipient send counter", zap.String("key", key), zap.Error(err))
+		}
+	}
+	return count, nil
+}
+
+// UncountRecipientSend decrements the number of sends to recipient on channel in the window
+// starting at windowStart, for a counted send that was not made.
+func (r *RedisService) UncountRecipientSend(ctx context.Context, channel, recipient string, windowStart time.Time, window time.Duration) error {
+	key := recipientSendsKey(channel, recipient, windowStart)
+
+	if r.client == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+
+	count, err := r.client.Decr(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to uncount send to recipient %s: %w", recipient, err)
+	}
+	if count < 0 {
+		// The counter expired with its window before the decrement recreated it, so drop it again.
+		if err := r.client.ExpireAt(ctx, key, windowStart.Add(window)).Err(); err != nil {
+			r.logger.Warn("Failed to set expiry of recipient send counter", zap.String("key", key), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// recipientSendsKey returns the key counting the sends to recipient on channel in the window starting at windowStart.
+func recipientSendsKey(channel, recipient string, windowStart time.Time) string {
+	// Key format: recipient_sends:<channel>:<recipient>:<window_start_unix>
+	return fmt.Sprintf("recipient_sends:%s:%s:%d", channel, recipient, windowStart.Unix())
+}
@@ -30,6 +30,21 @@ func (m *MockRedisClientInterface) Set(ctx context.Context, key string, value in
 	return args.Get(0).(*redis.StatusCmd)
 }
 
+func (m *MockRedisClientInterface) Incr(ctx context.Context, key string) *redis.IntCmd {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*redis.IntCmd)
+}
+
+func (m *MockRedisClientInterface) Decr(ctx context.Context, key string) *redis.IntCmd {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*redis.IntCmd)
+}
+
+func (m *MockRedisClientInterface) ExpireAt(ctx context.Context, key string, tm time.Time) *redis.BoolCmd {
+	args := m.Called(ctx, key, tm)
+	return args.Get(0).(*redis.BoolCmd)
+}
+
 func TestRedisService_CacheSentMessage(t *testing.T) {
 	observerCore, recordedLogs := observer.New(zap.DebugLevel)
 	mockLogger := zap.New(observerCore)
@@ -98,3 +113,94 @@ func TestRedisService_CacheSentMessage(t *testing.T) {
 		recordedLogs.TakeAll()
 	})
 }
+
+func TestRedisService_CountRecipientSend(t *testing.T) {
+	ctx := context.Background()
+	windowStart := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	expectedKey := fmt.Sprintf("recipient_sends:sms:+15551234567:%d", windowStart.Unix())
+
+	t.Run("First Send Sets Expiry", func(t *testing.T) {
+		mockClient := new(MockRedisClientInterface)
+		service := &RedisService{client: mockClient, logger: zap.NewNop()}
+		intCmd := redis.NewIntCmd(ctx)
+		intCmd.SetVal(1)
+		boolCmd := redis.NewBoolCmd(ctx)
+		boolCmd.SetVal(true)
+		mockClient.On("Incr", ctx, expectedKey).Return(intCmd).Once()
+		mockClient.On("ExpireAt", ctx, expectedKey, windowStart.Add(time.Hour)).Return(boolCmd).Once()
+
+		count, err := service.CountRecipientSend(ctx, "sms", "+15551234567", windowStart, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Later Sends Keep Expiry", func(t *testing.T) {
+		mockClient := new(MockRedisClientInterface)
+		service := &RedisService{client: mockClient, logger: zap.NewNop()}
+		intCmd := redis.NewIntCmd(ctx)
+		intCmd.SetVal(4)
+		mockClient.On("Incr", ctx, expectedKey).Return(intCmd).Once()
+
+		count, err := service.CountRecipientSend(ctx, "sms", "+15551234567", windowStart, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+		mockClient.AssertNotCalled(t, "ExpireAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error - increment failed", func(t *testing.T) {
+		mockClient := new(MockRedisClientInterface)
+		service := &RedisService{client: mockClient, logger: zap.NewNop()}
+		intCmd := redis.NewIntCmd(ctx)
+		intCmd.SetErr(errors.New("redis incr error"))
+		mockClient.On("Incr", ctx, expectedKey).Return(intCmd).Once()
+
+		_, err := service.CountRecipientSend(ctx, "sms", "+15551234567", windowStart, time.Hour)
+		assert.ErrorContains(t, err, "redis incr error")
+	})
+}
+
+func TestRedisService_UncountRecipientSend(t *testing.T) {
+	ctx := context.Background()
+	windowStart := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	expectedKey := fmt.Sprintf("recipient_sends:sms:+15551234567:%d", windowStart.Unix())
+
+	t.Run("Decrements Counter", func(t *testing.T) {
+		mockClient := new(MockRedisClientInterface)
+		service := &RedisService{client: mockClient, logger: zap.NewNop()}
+		intCmd := redis.NewIntCmd(ctx)
+		intCmd.SetVal(2)
+		mockClient.On("Decr", ctx, expectedKey).Return(intCmd).Once()
+
+		err := service.UncountRecipientSend(ctx, "sms", "+15551234567", windowStart, time.Hour)
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "ExpireAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expired Counter Dropped Again", func(t *testing.T) {
+		mockClient := new(MockRedisClientInterface)
+		service := &RedisService{client: mockClient, logger: zap.NewNop()}
+		intCmd := redis.NewIntCmd(ctx)
+		intCmd.SetVal(-1)
+		boolCmd := redis.NewBoolCmd(ctx)
+		boolCmd.SetVal(true)
+		mockClient.On("Decr", ctx, expectedKey).Return(intCmd).Once()
+		mockClient.On("ExpireAt", ctx, expectedKey, windowStart.Add(time.Hour)).Return(boolCmd).Once()
+
+		err := service.UncountRecipientSend(ctx, "sms", "+15551234567", windowStart, time.Hour)
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Error - decrement failed", func(t *testing.T) {
+		mockClient := new(MockRedisClientInterface)
+		service := &RedisService{client: mockClient, logger: zap.NewNop()}
+		intCmd := redis.NewIntCmd(ctx)
+		intCmd.SetErr(errors.New("redis decr error"))
+		mockClient.On("Decr", ctx, expectedKey).Return(intCmd).Once()
+
+		err := service.UncountRecipientSend(ctx, "sms", "+15551234567", windowStart, time.Hour)
+		assert.ErrorContains(t, err, "redis decr error")
+	})
+}
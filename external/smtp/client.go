@@ -0,0 +1,170 @@
+// Package smtp delivers the email channel through an SMTP server.
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	netsmtp "net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/google/uuid"
+)
+
+// SMTPSender implements the messages.Sender and messages.SubjectSender interfaces for the email channel.
+type SMTPSender struct {
+	host      string
+	addr      string
+	username  string
+	password  string
+	from      *mail.Address
+	startTLS  bool
+	timeout   time.Duration
+	tlsConfig *tls.Config
+}
+
+// NewSMTPSender returns a sender submitting emails to host:port as from.
+// With startTLS set the connection is upgraded before authenticating, and sending fails if the server does not support it.
+// PLAIN authentication is used when username is not empty.
+func NewSMTPSender(host string, port int, username, password, from string, startTLS bool, timeout time.Duration) (*SMTPSender, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address %q: %w", from, err)
+	}
+	return &SMTPSender{
+		host:      host,
+		addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		username:  username,
+		password:  password,
+		from:      fromAddr,
+		startTLS:  startTLS,
+		timeout:   timeout,
+		tlsConfig: &tls.Config{ServerName: host},
+	}, nil
+}
+
+// Send emails content to the given address without a subject.
+// HTML content is detected and sent as text/html, anything else as text/plain.
+func (s *SMTPSender) Send(ctx context.Context, to, content string) (string, error) {
+	return s.SendEmail(ctx, newEmail(to, "", content))
+}
+
+// SendWithSubject emails content to the given address with a subject line.
+// There is a single SMTP server, so no provider is reported.
+func (s *SMTPSender) SendWithSubject(ctx context.Context, to, subject, content string) (string, string, error) {
+	messageID, err := s.SendEmail(ctx, newEmail(to, subject, content))
+	return messageID, "", err
+}
+
+// newEmail returns an email of content, as HTML if it looks like HTML and as plain text otherwise.
+func newEmail(to, subject, content string) Email {
+	email := Email{To: to, Subject: subject}
+	if isHTML(content) {
+		email.HTML = content
+	} else {
+		email.Text = content
+	}
+	return email
+}
+
+// SendEmail delivers email and returns its generated Message-ID header as the external ID.
+// Failures past request validation are returned as *SendError.
+func (s *SMTPSender) SendEmail(ctx context.Context, email Email) (string, error) {
+	if email.To == "" {
+		return "", messages.ErrRecipientEmpty
+	}
+	to, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return "", &SendError{Err: fmt.Errorf("invalid recipient address %q: %w", email.To, err)}
+	}
+	email.To = to.String()
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), domainOf(s.from.Address))
+	msg, err := buildMessage(s.from.String(), email, messageID, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to build email: %w", err)
+	}
+
+	if err := s.deliver(ctx, to.Address, msg); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+// deliver runs a single SMTP transaction submitting msg to rcpt.
+func (s *SMTPSender) deliver(ctx context.Context, rcpt string, msg []byte) error {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return classifyError(fmt.Errorf("failed to connect to smtp server: %w", err))
+	}
+	// net/smtp has no context support, so the whole transaction is bounded by a connection deadline.
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return classifyError(fmt.Errorf("failed to set smtp deadline: %w", err))
+	}
+
+	client, err := netsmtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return classifyError(fmt.Errorf("smtp handshake failed: %w", err))
+	}
+	defer client.Close()
+
+	if s.startTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return &SendError{Err: fmt.Errorf("smtp server %s does not support STARTTLS", s.addr)}
+		}
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return classifyError(fmt.Errorf("smtp STARTTLS failed: %w", err))
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(netsmtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return classifyError(fmt.Errorf("smtp authentication failed: %w", err))
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return classifyError(fmt.Errorf("smtp MAIL FROM rejected: %w", err))
+	}
+	if err := client.Rcpt(rcpt); err != nil {
+		return classifyError(fmt.Errorf("smtp RCPT TO rejected: %w", err))
+	}
+	w, err := client.Data()
+	if err != nil {
+		return classifyError(fmt.Errorf("smtp DATA rejected: %w", err))
+	}
+	if _, err := w.Write(msg); err != nil {
+		return classifyError(fmt.Errorf("failed to write email: %w", err))
+	}
+	if err := w.Close(); err != nil {
+		return classifyError(fmt.Errorf("smtp server did not accept the email: %w", err))
+	}
+	// The email was accepted once DATA completed, a failing QUIT does not matter.
+	_ = client.Quit()
+	return nil
+}
+
+// isHTML reports whether content looks like an HTML document or fragment.
+func isHTML(content string) bool {
+	return strings.HasPrefix(http.DetectContentType([]byte(content)), "text/html")
+}
+
+// domainOf returns the domain part of an email address.
+func domainOf(address string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
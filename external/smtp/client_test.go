@@ -0,0 +1,314 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedEmail is an email accepted by fakeSMTPServer.
+type receivedEmail struct {
+	From     string
+	To       string
+	Data     []byte
+	User     string
+	UsingTLS bool
+}
+
+// fakeSMTPServer is a minimal in-process SMTP server accepting a single recipient per transaction.
+type fakeSMTPServer struct {
+	listener net.Listener
+	// tlsConfig enables STARTTLS when set.
+	tlsConfig *tls.Config
+	// rcptReply answers RCPT TO, "250 OK" if empty.
+	rcptReply string
+
+	mu       sync.Mutex
+	received []receivedEmail
+}
+
+func newFakeSMTPServer(t *testing.T, tlsConfig *tls.Config, rcptReply string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{listener: listener, tlsConfig: tlsConfig, rcptReply: rcptReply}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) hostPort(t *testing.T) (string, int) {
+	host, port, err := net.SplitHostPort(s.listener.Addr().String())
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, portNum
+}
+
+func (s *fakeSMTPServer) emails() []receivedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedEmail(nil), s.received...)
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	tp := textproto.NewConn(conn)
+	var email receivedEmail
+	tp.PrintfLine("220 fake.test ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			lines := []string{"fake.test"}
+			if s.tlsConfig != nil && !email.UsingTLS {
+				lines = append(lines, "STARTTLS")
+			}
+			lines = append(lines, "AUTH PLAIN")
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, l)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(conn)
+			email.UsingTLS = true
+		case "AUTH":
+			_, encoded, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(encoded)
+			parts := strings.Split(string(decoded), "\x00")
+			if len(parts) != 3 || parts[1] != "user" || parts[2] != "secret" {
+				tp.PrintfLine("535 5.7.8 Authentication credentials invalid")
+				continue
+			}
+			email.User = parts[1]
+			tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			email.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			if s.rcptReply != "" {
+				tp.PrintfLine("%s", s.rcptReply)
+				continue
+			}
+			email.To = strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			email.Data = data
+			s.mu.Lock()
+			s.received = append(s.received, email)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK queued")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+// testTLSConfigs returns a server TLS config and a client config trusting it, valid for 127.0.0.1.
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	server = srv.TLS.Clone()
+	client = srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	srv.Close()
+	client.ServerName = "127.0.0.1"
+	return server, client
+}
+
+func TestSMTPSender_SendWithSubject(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - plain text email", func(t *testing.T) {
+		server := newFakeSMTPServer(t, nil, "")
+		host, port := server.hostPort(t)
+		sender, err := NewSMTPSender(host, port, "", "", "Go Notify <notify@example.com>", false, time.Second)
+		require.NoError(t, err)
+
+		messageID, provider, err := sender.SendWithSubject(ctx, "jane@example.org", "Appointment confirmed", "See you at 10:00.")
+		require.NoError(t, err)
+		assert.Empty(t, provider)
+		assert.True(t, strings.HasPrefix(messageID, "<") && strings.HasSuffix(messageID, "@example.com>"), messageID)
+
+		emails := server.emails()
+		require.Len(t, emails, 1)
+		assert.Equal(t, "notify@example.com", emails[0].From)
+		assert.Equal(t, "jane@example.org", emails[0].To)
+
+		parsed, err := mail.ReadMessage(strings.NewReader(string(emails[0].Data)))
+		require.NoError(t, err)
+		assert.Equal(t, messageID, parsed.Header.Get("Message-ID"))
+		assert.Equal(t, "Appointment confirmed", parsed.Header.Get("Subject"))
+		assert.Equal(t, "<jane@example.org>", parsed.Header.Get("To"))
+		assert.Equal(t, "text/plain; charset=utf-8", parsed.Header.Get("Content-Type"))
+		body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+		require.NoError(t, err)
+		assert.Equal(t, "See you at 10:00.", strings.TrimSpace(string(body)))
+	})
+
+	t.Run("Success - HTML content detected", func(t *testing.T) {
+		server := newFakeSMTPServer(t, nil, "")
+		host, port := server.hostPort(t)
+		sender, err := NewSMTPSender(host, port, "", "", "notify@example.com", false, time.Second)
+		require.NoError(t, err)
+
+		_, err = sender.Send(ctx, "jane@example.org", "<p>See you at <b>10:00</b>.</p>")
+		require.NoError(t, err)
+
+		emails := server.emails()
+		require.Len(t, emails, 1)
+		parsed, err := mail.ReadMessage(strings.NewReader(string(emails[0].Data)))
+		require.NoError(t, err)
+		assert.Equal(t, "text/html; charset=utf-8", parsed.Header.Get("Content-Type"))
+	})
+
+	t.Run("Success - STARTTLS and PLAIN auth", func(t *testing.T) {
+		serverTLS, clientTLS := testTLSConfigs(t)
+		server := newFakeSMTPServer(t, serverTLS, "")
+		host, port := server.hostPort(t)
+		sender, err := NewSMTPSender(host, port, "user", "secret", "notify@example.com", true, time.Second)
+		require.NoError(t, err)
+		sender.tlsConfig = clientTLS
+
+		_, _, err = sender.SendWithSubject(ctx, "jane@example.org", "Hello", "Hi there")
+		require.NoError(t, err)
+
+		emails := server.emails()
+		require.Len(t, emails, 1)
+		assert.True(t, emails[0].UsingTLS)
+		assert.Equal(t, "user", emails[0].User)
+	})
+
+	t.Run("Error - recipient empty", func(t *testing.T) {
+		sender, err := NewSMTPSender("127.0.0.1", 25, "", "", "notify@example.com", false, time.Second)
+		require.NoError(t, err)
+		_, err = sender.Send(ctx, "", "hello")
+		assert.ErrorIs(t, err, messages.ErrRecipientEmpty)
+	})
+
+	t.Run("Error - invalid from address", func(t *testing.T) {
+		_, err := NewSMTPSender("127.0.0.1", 25, "", "", "not an address", false, time.Second)
+		assert.Error(t, err)
+	})
+}
+
+func TestSMTPSender_Send_ErrorClassification(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		rcptReply     string
+		recipient     string
+		startTLS      bool
+		password      string
+		wantCode      int
+		wantRetryable bool
+	}{
+		{name: "rejected recipient is permanent", rcptReply: "550 5.1.1 No such user", recipient: "nobody@example.org", wantCode: 550},
+		{name: "greylisted recipient is retryable", rcptReply: "451 4.7.1 Try again later", recipient: "jane@example.org", wantCode: 451, wantRetryable: true},
+		{name: "invalid recipient address is permanent", recipient: "not an address"},
+		{name: "missing STARTTLS is permanent", recipient: "jane@example.org", startTLS: true},
+		{name: "wrong credentials are permanent", recipient: "jane@example.org", password: "wrong", wantCode: 535},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, nil, tt.rcptReply)
+			host, port := server.hostPort(t)
+			username := ""
+			if tt.password != "" {
+				username = "user"
+			}
+			sender, err := NewSMTPSender(host, port, username, tt.password, "notify@example.com", tt.startTLS, time.Second)
+			require.NoError(t, err)
+
+			_, err = sender.Send(ctx, tt.recipient, "hello")
+
+			var sendErr *SendError
+			require.ErrorAs(t, err, &sendErr)
+			assert.Equal(t, tt.wantCode, sendErr.Code)
+			assert.Equal(t, tt.wantRetryable, sendErr.Retryable())
+			assert.Empty(t, server.emails())
+		})
+	}
+
+	t.Run("unreachable server is retryable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().(*net.TCPAddr)
+		listener.Close()
+		sender, err := NewSMTPSender("127.0.0.1", addr.Port, "", "", "notify@example.com", false, time.Second)
+		require.NoError(t, err)
+
+		_, err = sender.Send(ctx, "jane@example.org", "hello")
+
+		var sendErr *SendError
+		require.ErrorAs(t, err, &sendErr)
+		assert.True(t, sendErr.Retryable())
+	})
+
+	t.Run("silent server times out", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() {
+			conn, err := listener.Accept()
+			if err == nil {
+				bufio.NewReader(conn).ReadString('\n')
+				conn.Close()
+			}
+		}()
+		addr := listener.Addr().(*net.TCPAddr)
+		sender, err := NewSMTPSender("127.0.0.1", addr.Port, "", "", "notify@example.com", false, 50*time.Millisecond)
+		require.NoError(t, err)
+
+		_, err = sender.Send(ctx, "jane@example.org", "hello")
+
+		var sendErr *SendError
+		require.ErrorAs(t, err, &sendErr)
+		assert.True(t, sendErr.Retryable())
+	})
+}
@@ -0,0 +1,45 @@
+package smtp
+
+import (
+	"errors"
+	"net/textproto"
+	"time"
+)
+
+// SendError is returned by SMTPSender for every failure after the email was built.
+// It implements messages.ClassifiedError so the message service can decide whether to retry.
+type SendError struct {
+	// SMTP reply code, zero if the server did not reply.
+	Code int
+	// Temporary is set for network failures and 4xx replies, which may succeed on a later attempt.
+	Temporary bool
+	Err       error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether sending the same email again may succeed.
+func (e *SendError) Retryable() bool {
+	return e.Temporary
+}
+
+// RetryDelay returns zero, SMTP servers do not request a specific delay.
+func (e *SendError) RetryDelay() time.Duration {
+	return 0
+}
+
+// classifyError wraps err, returned while talking to the server, in a *SendError.
+// 5xx replies are permanent; 4xx replies and connection failures are transient.
+func classifyError(err error) *SendError {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return &SendError{Code: protoErr.Code, Temporary: protoErr.Code < 500, Err: err}
+	}
+	return &SendError{Temporary: true, Err: err}
+}
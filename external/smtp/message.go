@@ -0,0 +1,95 @@
+package smtp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// Email is a single email to deliver. At least one of Text and HTML should be set;
+// when both are, the email is sent as multipart/alternative.
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// buildMessage renders email as an RFC 5322 message identified by messageID.
+func buildMessage(from string, email Email, messageID string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	var contentType string
+	switch {
+	case email.Text != "" && email.HTML != "":
+		mw := multipart.NewWriter(&body)
+		contentType = "multipart/alternative; boundary=" + mw.Boundary()
+		if err := writePart(mw, "text/plain; charset=utf-8", email.Text); err != nil {
+			return nil, err
+		}
+		if err := writePart(mw, "text/html; charset=utf-8", email.HTML); err != nil {
+			return nil, err
+		}
+		if err := mw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close multipart body: %w", err)
+		}
+	case email.HTML != "":
+		contentType = "text/html; charset=utf-8"
+		if err := writeQuotedPrintable(&body, email.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		contentType = "text/plain; charset=utf-8"
+		if err := writeQuotedPrintable(&body, email.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", email.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", email.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	}
+	if email.Text == "" || email.HTML == "" {
+		headers = append(headers, [2]string{"Content-Transfer-Encoding", "quoted-printable"})
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writePart adds a quoted-printable part holding content to a multipart body.
+func writePart(mw *multipart.Writer, contentType, content string) error {
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create multipart part: %w", err)
+	}
+	return writeQuotedPrintable(pw, content)
+}
+
+// writeQuotedPrintable writes content to w with quoted-printable encoding.
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	if err := qw.Close(); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return nil
+}
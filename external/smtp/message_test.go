@@ -0,0 +1,62 @@
+package smtp
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2025, 7, 21, 8, 0, 0, 0, time.UTC)
+
+	t.Run("Text and HTML - multipart alternative", func(t *testing.T) {
+		email := Email{To: "jane@example.org", Subject: "Rendez-vous confirmé", Text: "See you at 10:00.", HTML: "<p>See you at <b>10:00</b>.</p>"}
+		raw, err := buildMessage("notify@example.com", email, "<id@example.com>", date)
+		require.NoError(t, err)
+
+		parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		require.NoError(t, err)
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, email.Subject, subject)
+		assert.Equal(t, "<id@example.com>", parsed.Header.Get("Message-ID"))
+		assert.Equal(t, date.Format(time.RFC1123Z), parsed.Header.Get("Date"))
+
+		mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		reader := multipart.NewReader(parsed.Body, params["boundary"])
+		for _, want := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", email.Text},
+			{"text/html; charset=utf-8", email.HTML},
+		} {
+			part, err := reader.NextRawPart()
+			require.NoError(t, err)
+			assert.Equal(t, want.contentType, part.Header.Get("Content-Type"))
+			body, err := io.ReadAll(quotedprintable.NewReader(part))
+			require.NoError(t, err)
+			assert.Equal(t, want.body, string(body))
+		}
+		_, err = reader.NextPart()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Subject with line break cannot inject headers", func(t *testing.T) {
+		email := Email{To: "jane@example.org", Subject: "Hi\r\nBcc: attacker@example.net", Text: "hello"}
+		raw, err := buildMessage("notify@example.com", email, "<id@example.com>", date)
+		require.NoError(t, err)
+
+		parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+		require.NoError(t, err)
+		assert.Empty(t, parsed.Header.Get("Bcc"))
+	})
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+)
+
+// ErrNonPublicAddress is returned when a callback URL resolves to an address that is not public.
+var ErrNonPublicAddress = errors.New("callback address is not public")
+
+// CallbackSender posts signed status events to the callback URLs of messages.
+// It implements callbacks.Poster.
+type CallbackSender struct {
+	client *http.Client
+	signer *Signer
+}
+
+// NewCallbackSender returns a CallbackSender that only connects to public addresses.
+// The check is made on the address being dialed, so DNS records changed after the URL was accepted,
+// redirects and proxies cannot point callbacks at the network of the server.
+func NewCallbackSender(signer *Signer, timeout time.Duration) *CallbackSender {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   rejectNonPublicAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &CallbackSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		signer: signer,
+	}
+}
+
+// rejectNonPublicAddress is a net.Dialer Control function failing connections to addresses that are not public.
+func rejectNonPublicAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !messages.IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Post sends payload as JSON to url, signed with the SignatureHeader and TimestampHeader headers.
+// Any 2xx response is a success. Failures past request validation are returned as *SendError.
+func (s *CallbackSender) Post(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	s.signer.SignRequest(req, payload)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrNonPublicAddress) {
+			return &SendError{Category: CategoryPermanent, Err: fmt.Errorf("failed to send callback request: %w", err)}
+		}
+		return newTransportError(fmt.Errorf("failed to send callback request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return newStatusError(resp, fmt.Errorf("callback responded with status code: %d, body: %s", resp.StatusCode, string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCallbackSender returns a CallbackSender that may post to the loopback address of test servers.
+func newTestCallbackSender(signer *Signer) *CallbackSender {
+	sender := NewCallbackSender(signer, time.Second)
+	sender.client.Transport = http.DefaultTransport
+	return sender
+}
+
+func TestCallbackSender_Post(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte(`{"message_id":"a1b2c3d4","status":"sent"}`)
+
+	t.Run("Success - signed event posted", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, payload, body)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, Verify(r.Header, body, []string{"secret"}, time.Now(), DefaultMaxSkew))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		assert.NoError(t, newTestCallbackSender(NewSigner("secret", "")).Post(ctx, server.URL, payload))
+	})
+
+	t.Run("Error - status is classified", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := newTestCallbackSender(NewSigner("secret", "")).Post(ctx, server.URL, payload)
+		var sendErr *SendError
+		require.True(t, errors.As(err, &sendErr))
+		assert.Equal(t, CategoryServer, sendErr.Category)
+		assert.True(t, sendErr.Retryable())
+	})
+
+	t.Run("Error - non-public address refused", func(t *testing.T) {
+		var called bool
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		// The test server listens on loopback, which the default sender never connects to.
+		err := NewCallbackSender(NewSigner("secret", ""), time.Second).Post(ctx, server.URL, payload)
+		assert.ErrorIs(t, err, ErrNonPublicAddress)
+		var sendErr *SendError
+		require.True(t, errors.As(err, &sendErr))
+		assert.False(t, sendErr.Retryable())
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+)
+
+// ChatRequest represents the payload of a Slack-style incoming webhook.
+type ChatRequest struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// ChatSender posts messages to a Slack-style incoming webhook.
+// It implements messages.Sender for the chat channel; the recipient is the chat channel to post to.
+type ChatSender struct {
+	client     *http.Client
+	webhookURL string
+}
+
+func NewChatSender(url string, timeout time.Duration) *ChatSender {
+	return &ChatSender{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		webhookURL: url,
+	}
+}
+
+// Send posts the content to the chat webhook. Incoming webhooks do not identify the posted
+// message, so the returned external ID is always empty.
+// Failures past request validation are returned as *SendError.
+func (s *ChatSender) Send(ctx context.Context, to, content string) (string, error) {
+	if to == "" {
+		return "", messages.ErrRecipientEmpty
+	}
+
+	jsonBody, err := json.Marshal(ChatRequest{Text: content, Channel: to})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal chat request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", newTransportError(fmt.Errorf("failed to send chat request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", newStatusError(resp, fmt.Errorf("chat webhook responded with non-200 status code: %d, body: %s", resp.StatusCode, string(respBody)))
+	}
+
+	// Slack answers "ok"; anything else means the post was not accepted as expected.
+	if body := strings.TrimSpace(string(respBody)); body != "" && body != "ok" {
+		return "", &SendError{
+			Category:   CategoryMalformedResponse,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("chat webhook responded with unexpected body: %s", body),
+		}
+	}
+
+	return "", nil
+}
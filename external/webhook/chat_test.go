@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatSender_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success - message posted", func(t *testing.T) {
+		var received ChatRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+		sender := NewChatSender(server.URL, time.Second)
+
+		externalID, err := sender.Send(ctx, "#alerts", "Deploy finished")
+		require.NoError(t, err)
+		assert.Empty(t, externalID)
+		assert.Equal(t, ChatRequest{Text: "Deploy finished", Channel: "#alerts"}, received)
+	})
+
+	t.Run("Error - recipient empty", func(t *testing.T) {
+		sender := NewChatSender("http://example.com/chat", time.Second)
+		_, err := sender.Send(ctx, "", "hello")
+		assert.ErrorIs(t, err, messages.ErrRecipientEmpty)
+	})
+
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		wantCategory  ErrorCategory
+		wantRetryable bool
+	}{
+		{
+			name: "unknown channel is permanent",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("channel_not_found"))
+			},
+			wantCategory: CategoryPermanent,
+		},
+		{
+			name: "5xx is retryable",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantCategory:  CategoryServer,
+			wantRetryable: true,
+		},
+		{
+			name: "429 is throttled",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantCategory:  CategoryThrottled,
+			wantRetryable: true,
+		},
+		{
+			name: "unexpected body is malformed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("invalid_token"))
+			},
+			wantCategory: CategoryMalformedResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			sender := NewChatSender(server.URL, time.Second)
+
+			_, err := sender.Send(ctx, "#alerts", "hello")
+
+			var sendErr *SendError
+			require.ErrorAs(t, err, &sendErr)
+			assert.Equal(t, tt.wantCategory, sendErr.Category)
+			assert.Equal(t, tt.wantRetryable, sendErr.Retryable())
+		})
+	}
+}
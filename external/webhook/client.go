@@ -3,7 +3,6 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,37 +11,43 @@ import (
 	"github.com/akshaysangma/go-notify/internal/messages"
 )
 
-// WebhookRequest represents the payload for the webhook.
+// WebhookRequest represents the default payload for the webhook, used by providers without a body template.
 type WebhookRequest struct {
 	To      string `json:"to"`
 	Content string `json:"content"`
 }
 
-// WebhookResponse represents the expected response from the webhook.
+// WebhookResponse represents the default response expected from the webhook.
 type WebhookResponse struct {
 	MessageID string `json:"messageId"`
 	Status    string `json:"status"`
 	Error     string `json:"error"`
 }
 
-// WebhookSiteSender implements the WebhookSender interface.
+// WebhookSiteSender implements the messages.Sender interface for the sms channel.
 type WebhookSiteSender struct {
 	client         *http.Client
 	webhookURL     string
 	characterLimit int
+	signer         *Signer         // signs each request, nil to send them unsigned
+	mapping        *PayloadMapping // request and response shape of the provider, nil for the default
 }
 
-func NewWebhookSiteSender(url string, charLimit int, timeout time.Duration) *WebhookSiteSender {
+func NewWebhookSiteSender(url string, charLimit int, timeout time.Duration, signer *Signer, mapping *PayloadMapping) *WebhookSiteSender {
 	return &WebhookSiteSender{
 		client: &http.Client{
 			Timeout: timeout,
 		},
 		webhookURL:     url,
 		characterLimit: charLimit,
+		signer:         signer,
+		mapping:        mapping,
 	}
 }
 
-// Send sends the content to external webhook and returns external ID
+// Send sends the content to external webhook, shaped by the payload mapping, and returns external ID.
+// The request is signed when the sender has a Signer with a secret.
+// Failures past request validation are returned as *SendError.
 func (s *WebhookSiteSender) Send(ctx context.Context, to, content string) (string, error) {
 	if len(content) > s.characterLimit {
 		return "", messages.ErrContentTooLong
@@ -51,41 +56,49 @@ func (s *WebhookSiteSender) Send(ctx context.Context, to, content string) (strin
 		return "", messages.ErrRecipientEmpty
 	}
 
-	requestBody := WebhookRequest{
-		To:      to,
-		Content: content,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
+	requestBody, contentType, err := s.mapping.encode(to, content)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal webhook request body: %w", err)
+		return "", fmt.Errorf("failed to build webhook request body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create webhook request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	s.signer.SignRequest(req, requestBody)
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send webhook request: %w", err)
+		return "", newTransportError(fmt.Errorf("failed to send webhook request: %w", err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusAccepted {
+	if !s.mapping.accepts(resp.StatusCode) {
 		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("webhook responded with non-202 status code: %d, body: %s", resp.StatusCode, string(respBody))
+		return "", newStatusError(resp, fmt.Errorf("webhook responded with unexpected status code: %d, body: %s", resp.StatusCode, string(respBody)))
 	}
 
-	var webhookResp WebhookResponse
-	if err := json.NewDecoder(resp.Body).Decode(&webhookResp); err != nil {
-		return "", fmt.Errorf("failed to decode webhook response body: %w", err)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", newTransportError(fmt.Errorf("failed to read webhook response body: %w", err))
+	}
+	externalID, err := s.mapping.externalID(respBody)
+	if err != nil {
+		return "", &SendError{
+			Category:   CategoryMalformedResponse,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("failed to decode webhook response body: %w", err),
+		}
 	}
 
-	if webhookResp.MessageID == "" {
-		return "", fmt.Errorf("webhook response did not contain a message ID")
+	if externalID == "" {
+		return "", &SendError{
+			Category:   CategoryMalformedResponse,
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("webhook response did not contain a message ID"),
+		}
 	}
 
-	return webhookResp.MessageID, nil
+	return externalID, nil
 }
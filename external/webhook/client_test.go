@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -33,7 +34,7 @@ func TestWebhookSiteSender_Send(t *testing.T) {
 		Timeout:   5 * time.Second,
 	}
 
-	sender := NewWebhookSiteSender("http://example.com/webhook", 250, 5*time.Second)
+	sender := NewWebhookSiteSender("http://example.com/webhook", 250, 5*time.Second, nil, nil)
 	sender.client = mockClient
 
 	ctx := context.Background()
@@ -108,7 +109,7 @@ func TestWebhookSiteSender_Send(t *testing.T) {
 
 		_, err := sender.Send(ctx, to, content)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "webhook responded with non-202 status code: 400")
+		assert.Contains(t, err.Error(), "webhook responded with unexpected status code: 400")
 		assert.Contains(t, err.Error(), `body: {"error":"invalid recipient"}`)
 		mockRT.AssertExpectations(t)
 	})
@@ -147,3 +148,135 @@ func TestWebhookSiteSender_Send(t *testing.T) {
 		mockRT.AssertExpectations(t)
 	})
 }
+
+func TestWebhookSiteSender_Send_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		wantCategory   ErrorCategory
+		wantStatus     int
+		wantRetryable  bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name: "4xx is permanent",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			wantCategory: CategoryPermanent,
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name: "5xx is retryable",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			wantCategory:  CategoryServer,
+			wantStatus:    http.StatusServiceUnavailable,
+			wantRetryable: true,
+		},
+		{
+			name: "429 is throttled with Retry-After",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantCategory:   CategoryThrottled,
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryable:  true,
+			wantRetryAfter: 30 * time.Second,
+		},
+		{
+			name: "undecodable body is malformed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte("not json"))
+			},
+			wantCategory: CategoryMalformedResponse,
+			wantStatus:   http.StatusAccepted,
+		},
+		{
+			name: "missing message ID is malformed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"status":"accepted"}`))
+			},
+			wantCategory: CategoryMalformedResponse,
+			wantStatus:   http.StatusAccepted,
+		},
+		{
+			name: "slow provider is a timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.WriteHeader(http.StatusAccepted)
+			},
+			wantCategory:  CategoryTimeout,
+			wantRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			sender := NewWebhookSiteSender(server.URL, 250, 50*time.Millisecond, nil, nil)
+
+			_, err := sender.Send(context.Background(), "+1234567890", "hello")
+
+			var sendErr *SendError
+			assert.ErrorAs(t, err, &sendErr)
+			assert.Equal(t, tt.wantCategory, sendErr.Category)
+			assert.Equal(t, tt.wantStatus, sendErr.StatusCode)
+			assert.Equal(t, tt.wantRetryable, sendErr.Retryable())
+			assert.Equal(t, tt.wantRetryAfter, sendErr.RetryDelay())
+		})
+	}
+
+	t.Run("unreachable provider is a network error", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+		sender := NewWebhookSiteSender(url, 250, time.Second, nil, nil)
+
+		_, err := sender.Send(context.Background(), "+1234567890", "hello")
+
+		var sendErr *SendError
+		assert.ErrorAs(t, err, &sendErr)
+		assert.Equal(t, CategoryNetwork, sendErr.Category)
+		assert.True(t, sendErr.Retryable())
+	})
+}
+
+func TestWebhookSiteSender_Send_Signed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || Verify(r.Header, body, []string{"old"}, time.Now(), DefaultMaxSkew) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"messageId":"ext-1","status":"accepted"}`))
+	}))
+	defer server.Close()
+
+	// The receiver still only knows the previous secret.
+	sender := NewWebhookSiteSender(server.URL, 250, time.Second, NewSigner("new", "old"), nil)
+	messageID, err := sender.Send(context.Background(), "+1234567890", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "ext-1", messageID)
+
+	unsigned := NewWebhookSiteSender(server.URL, 250, time.Second, nil, nil)
+	_, err = unsigned.Send(context.Background(), "+1234567890", "hello")
+	var sendErr *SendError
+	assert.ErrorAs(t, err, &sendErr)
+	assert.Equal(t, http.StatusUnauthorized, sendErr.StatusCode)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrorCategory classifies why a webhook call failed.
+type ErrorCategory string
+
+const (
+	// CategoryNetwork is a connection level failure (DNS, refused, reset).
+	CategoryNetwork ErrorCategory = "network"
+	// CategoryTimeout is a request that did not complete in time.
+	CategoryTimeout ErrorCategory = "timeout"
+	// CategoryPermanent is a 4xx response; sending the same request again will fail again.
+	CategoryPermanent ErrorCategory = "permanent"
+	// CategoryServer is a 5xx response; the provider may recover.
+	CategoryServer ErrorCategory = "server"
+	// CategoryThrottled is a 429 response; the provider asked us to slow down.
+	CategoryThrottled ErrorCategory = "throttled"
+	// CategoryMalformedResponse is an unexpected response from a provider that may have accepted the message.
+	CategoryMalformedResponse ErrorCategory = "malformed_response"
+)
+
+// SendError is returned by the senders of this package for every failure after the request was built.
+// It implements messages.ClassifiedError so the message service can decide whether to retry.
+type SendError struct {
+	Category ErrorCategory
+	// HTTP status code of the response, zero if none was received.
+	StatusCode int
+	// Delay requested through the Retry-After header, zero if absent.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether sending the same message again may succeed.
+// Malformed responses are not retried as the provider may already have accepted the message.
+func (e *SendError) Retryable() bool {
+	switch e.Category {
+	case CategoryNetwork, CategoryTimeout, CategoryServer, CategoryThrottled:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryDelay returns the minimum delay requested by the provider before retrying.
+func (e *SendError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// newTransportError classifies an error returned by http.Client.Do.
+func newTransportError(err error) *SendError {
+	category := CategoryNetwork
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		category = CategoryTimeout
+	}
+	return &SendError{Category: category, Err: err}
+}
+
+// newStatusError classifies an unexpected HTTP response status.
+func newStatusError(resp *http.Response, err error) *SendError {
+	sendErr := &SendError{StatusCode: resp.StatusCode, Err: err}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		sendErr.Category = CategoryThrottled
+		sendErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	case resp.StatusCode == http.StatusRequestTimeout:
+		sendErr.Category = CategoryTimeout
+	case resp.StatusCode >= 500:
+		sendErr.Category = CategoryServer
+		sendErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	case resp.StatusCode >= 400:
+		sendErr.Category = CategoryPermanent
+	default:
+		sendErr.Category = CategoryMalformedResponse
+	}
+	return sendErr
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+)
+
+// Provider is one of the endpoints a FailoverSender spreads messages over.
+type Provider struct {
+	// Name identifies the provider in logs and on the messages it delivered.
+	Name   string
+	Sender messages.Sender
+	// Weight is the share of traffic the provider gets among providers of the same priority.
+	Weight int
+	// Priority orders the providers; lower priorities are tried first.
+	Priority int
+}
+
+// providerState tracks the recent outcomes of a provider. It is guarded by FailoverSender.mu.
+type providerState struct {
+	Provider
+	consecutiveFailures int
+	lastError           string
+	lastFailureAt       time.Time
+	lastSuccessAt       time.Time
+}
+
+// FailoverSender implements messages.Sender and messages.ProviderSender over several providers.
+// Each send tries the providers of the lowest priority first, picked at random by weight, and
+// moves on to the next provider when one fails with a retryable error. Timeouts are the exception:
+// the provider may have accepted the message, so the error is returned to the message service
+// and its retry schedule rather than risk sending the message twice. A provider that failed
+// unhealthyAfter times in a row is tried after the healthy ones until cooldown has passed.
+type FailoverSender struct {
+	mu             sync.Mutex
+	providers      []*providerState // sorted by priority
+	unhealthyAfter int
+	cooldown       time.Duration
+	now            func() time.Time
+	intN           func(n int) int
+}
+
+func NewFailoverSender(providers []Provider, unhealthyAfter int, cooldown time.Duration) *FailoverSender {
+	states := make([]*providerState, 0, len(providers))
+	for _, p := range providers {
+		if p.Weight <= 0 {
+			p.Weight = 1
+		}
+		states = append(states, &providerState{Provider: p})
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].Priority < states[j].Priority
+	})
+	return &FailoverSender{
+		providers:      states,
+		unhealthyAfter: unhealthyAfter,
+		cooldown:       cooldown,
+		now:            time.Now,
+		intN:           rand.IntN,
+	}
+}
+
+// Send sends the content through the first provider that accepts it and returns its external ID.
+func (s *FailoverSender) Send(ctx context.Context, to, content string) (string, error) {
+	externalID, _, err := s.SendWithProvider(ctx, to, content)
+	return externalID, err
+}
+
+// SendWithProvider sends the content through the first provider that accepts it and returns
+// its external ID along with the name of the provider. Errors that are not retryable are
+// returned straight away, as every provider would reject the message the same way, and so are
+// timeouts, as the provider may have accepted the message before the request gave up.
+// Providers refusing to send for now, e.g. because of their rate limit, are skipped without
+// counting as a failure. Their error is only returned if no provider made an attempt.
+func (s *FailoverSender) SendWithProvider(ctx context.Context, to, content string) (string, string, error) {
+	var lastErr error
+	var lastProvider string
+	for _, p := range s.order() {
+		externalID, err := p.Sender.Send(ctx, to, content)
+		if err == nil {
+			s.recordSuccess(p)
+			return externalID, p.Name, nil
+		}
+
+		unavailable := errors.Is(err, messages.ErrSenderUnavailable)
+		if lastErr == nil || !unavailable || errors.Is(lastErr, messages.ErrSenderUnavailable) {
+			lastErr = fmt.Errorf("provider %s: %w", p.Name, err)
+			lastProvider = p.Name
+		}
+		var classified messages.ClassifiedError
+		if !errors.As(err, &classified) || !classified.Retryable() {
+			return "", p.Name, fmt.Errorf("provider %s: %w", p.Name, err)
+		}
+		if !unavailable {
+			s.recordFailure(p, err)
+		}
+		var sendErr *SendError
+		if errors.As(err, &sendErr) && sendErr.Category == CategoryTimeout {
+			return "", p.Name, fmt.Errorf("provider %s: %w", p.Name, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return "", "", fmt.Errorf("no webhook provider configured")
+	}
+	return "", lastProvider, lastErr
+}
+
+// Health returns the health of every provider, by priority.
+func (s *FailoverSender) Health() []messages.ProviderHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	health := make([]messages.ProviderHealth, 0, len(s.providers))
+	for _, p := range s.providers {
+		h := messages.ProviderHealth{
+			Name:                p.Name,
+			Priority:            p.Priority,
+			Weight:              p.Weight,
+			Healthy:             s.healthy(p, now),
+			ConsecutiveFailures: p.consecutiveFailures,
+			LastError:           p.lastError,
+		}
+		if !p.lastFailureAt.IsZero() {
+			lastFailureAt := p.lastFailureAt.UTC()
+			h.LastFailureAt = &lastFailureAt
+		}
+		if !p.lastSuccessAt.IsZero() {
+			lastSuccessAt := p.lastSuccessAt.UTC()
+			h.LastSuccessAt = &lastSuccessAt
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// order returns the providers in the order a send should try them: healthy providers by priority,
+// shuffled by weight within a priority, then the unhealthy ones as a last resort.
+func (s *FailoverSender) order() []*providerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	healthy := make([]*providerState, 0, len(s.providers))
+	var unhealthy []*providerState
+	for start := 0; start < len(s.providers); {
+		end := start + 1
+		for end < len(s.providers) && s.providers[end].Priority == s.providers[start].Priority {
+			end++
+		}
+		for _, p := range s.shuffleByWeight(s.providers[start:end]) {
+			if s.healthy(p, now) {
+				healthy = append(healthy, p)
+			} else {
+				unhealthy = append(unhealthy, p)
+			}
+		}
+		start = end
+	}
+	return append(healthy, unhealthy...)
+}
+
+// shuffleByWeight returns the providers in random order, a provider being picked ahead of
+// the others in proportion to its weight.
+func (s *FailoverSender) shuffleByWeight(providers []*providerState) []*providerState {
+	remaining := append([]*providerState(nil), providers...)
+	total := 0
+	for _, p := range remaining {
+		total += p.Weight
+	}
+
+	shuffled := make([]*providerState, 0, len(remaining))
+	for len(remaining) > 0 {
+		pick := s.intN(total)
+		i := 0
+		for pick >= remaining[i].Weight {
+			pick -= remaining[i].Weight
+			i++
+		}
+		shuffled = append(shuffled, remaining[i])
+		total -= remaining[i].Weight
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return shuffled
+}
+
+// healthy reports whether p is below the failure threshold, or has cooled down since its last failure.
+func (s *FailoverSender) healthy(p *providerState, now time.Time) bool {
+	return p.consecutiveFailures < s.unhealthyAfter || now.Sub(p.lastFailureAt) >= s.cooldown
+}
+
+func (s *FailoverSender) recordSuccess(p *providerState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.consecutiveFailures = 0
+	p.lastSuccessAt = s.now()
+}
+
+func (s *FailoverSender) recordFailure(p *providerState, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.consecutiveFailures++
+	p.lastError = err.Error()
+	p.lastFailureAt = s.now()
+}
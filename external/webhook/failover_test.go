@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/akshaysangma/go-notify/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is a webhook endpoint answering every request with status, counting the requests.
+type fakeProvider struct {
+	server *httptest.Server
+	status atomic.Int32
+	calls  atomic.Int32
+}
+
+func newFakeProvider(t *testing.T, status int) *fakeProvider {
+	p := &fakeProvider{}
+	p.status.Store(int32(status))
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.calls.Add(1)
+		w.WriteHeader(int(p.status.Load()))
+		json.NewEncoder(w).Encode(WebhookResponse{MessageID: "ext-" + r.Host, Status: "accepted"})
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeProvider) provider(name string, weight, priority int) Provider {
+	return Provider{
+		Name:     name,
+		Sender:   NewWebhookSiteSender(p.server.URL, 250, time.Second, nil, nil),
+		Weight:   weight,
+		Priority: priority,
+	}
+}
+
+func TestFailoverSender_SendWithProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Primary Accepts", func(t *testing.T) {
+		primary := newFakeProvider(t, http.StatusAccepted)
+		secondary := newFakeProvider(t, http.StatusAccepted)
+		sender := NewFailoverSender([]Provider{
+			secondary.provider("secondary", 1, 1),
+			primary.provider("primary", 1, 0),
+		}, 3, time.Minute)
+
+		externalID, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "primary", provider)
+		assert.NotEmpty(t, externalID)
+		assert.Equal(t, int32(1), primary.calls.Load())
+		assert.Equal(t, int32(0), secondary.calls.Load())
+	})
+
+	t.Run("Fails Over On Retryable Error", func(t *testing.T) {
+		primary := newFakeProvider(t, http.StatusServiceUnavailable)
+		secondary := newFakeProvider(t, http.StatusAccepted)
+		sender := NewFailoverSender([]Provider{
+			primary.provider("primary", 1, 0),
+			secondary.provider("secondary", 1, 1),
+		}, 3, time.Minute)
+
+		_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "secondary", provider)
+
+		health := sender.Health()
+		require.Len(t, health, 2)
+		assert.Equal(t, "primary", health[0].Name)
+		assert.Equal(t, 1, health[0].ConsecutiveFailures)
+		assert.Contains(t, health[0].LastError, "503")
+		assert.NotNil(t, health[0].LastFailureAt)
+		assert.Equal(t, "secondary", health[1].Name)
+		assert.NotNil(t, health[1].LastSuccessAt)
+	})
+
+	t.Run("No Failover On Permanent Error", func(t *testing.T) {
+		primary := newFakeProvider(t, http.StatusBadRequest)
+		secondary := newFakeProvider(t, http.StatusAccepted)
+		sender := NewFailoverSender([]Provider{
+			primary.provider("primary", 1, 0),
+			secondary.provider("secondary", 1, 1),
+		}, 3, time.Minute)
+
+		_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.Error(t, err)
+		assert.Equal(t, "primary", provider)
+		var sendErr *SendError
+		require.True(t, errors.As(err, &sendErr))
+		assert.Equal(t, CategoryPermanent, sendErr.Category)
+		assert.Equal(t, int32(0), secondary.calls.Load())
+		assert.Equal(t, 0, sender.Health()[0].ConsecutiveFailures)
+	})
+
+	t.Run("No Failover On Timeout", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		t.Cleanup(slow.Close)
+		secondary := newFakeProvider(t, http.StatusAccepted)
+		sender := NewFailoverSender([]Provider{
+			{Name: "primary", Sender: NewWebhookSiteSender(slow.URL, 250, 50*time.Millisecond, nil, nil), Weight: 1},
+			secondary.provider("secondary", 1, 1),
+		}, 3, time.Minute)
+
+		// The primary may have accepted the message, so it is not sent through the secondary as well.
+		_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.Error(t, err)
+		assert.Equal(t, "primary", provider)
+		var sendErr *SendError
+		require.True(t, errors.As(err, &sendErr))
+		assert.Equal(t, CategoryTimeout, sendErr.Category)
+		assert.True(t, sendErr.Retryable())
+		assert.Equal(t, int32(0), secondary.calls.Load())
+		assert.Equal(t, 1, sender.Health()[0].ConsecutiveFailures)
+	})
+
+	t.Run("All Providers Fail", func(t *testing.T) {
+		primary := newFakeProvider(t, http.StatusServiceUnavailable)
+		secondary := newFakeProvider(t, http.StatusBadGateway)
+		sender := NewFailoverSender([]Provider{
+			primary.provider("primary", 1, 0),
+			secondary.provider("secondary", 1, 1),
+		}, 3, time.Minute)
+
+		_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.Error(t, err)
+		assert.Equal(t, "secondary", provider)
+		var sendErr *SendError
+		require.True(t, errors.As(err, &sendErr))
+		assert.True(t, sendErr.Retryable())
+		assert.Equal(t, int32(1), primary.calls.Load())
+		assert.Equal(t, int32(1), secondary.calls.Load())
+	})
+
+	t.Run("Fails Over When Rate Limited", func(t *testing.T) {
+		primary := newFakeProvider(t, http.StatusAccepted)
+		secondary := newFakeProvider(t, http.StatusAccepted)
+		// The primary's only token is taken, and the next one is a second away.
+		bucket := ratelimit.NewTokenBucket(1, 1)
+		require.True(t, bucket.Allow())
+		limited := primary.provider("primary", 1, 0)
+		limited.Sender = ratelimit.NewSender(limited.Sender, bucket)
+		sender := NewFailoverSender([]Provider{
+			limited,
+			secondary.provider("secondary", 1, 1),
+		}, 3, time.Minute)
+
+		sendCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, provider, err := sender.SendWithProvider(sendCtx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "secondary", provider)
+		assert.Equal(t, int32(0), primary.calls.Load())
+		assert.Equal(t, int32(1), secondary.calls.Load())
+		// Being rate limited is not a provider failure.
+		assert.Equal(t, 0, sender.Health()[0].ConsecutiveFailures)
+	})
+
+	t.Run("Every Provider Rate Limited", func(t *testing.T) {
+		primary := newFakeProvider(t, http.StatusAccepted)
+		bucket := ratelimit.NewTokenBucket(1, 1)
+		require.True(t, bucket.Allow())
+		limited := primary.provider("primary", 1, 0)
+		limited.Sender = ratelimit.NewSender(limited.Sender, bucket)
+		sender := NewFailoverSender([]Provider{limited}, 3, time.Minute)
+
+		sendCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, _, err := sender.SendWithProvider(sendCtx, "+15551234567", "hello")
+		assert.ErrorIs(t, err, messages.ErrSenderUnavailable)
+		// The message is deferred until the primary has a token again.
+		var classified messages.ClassifiedError
+		require.ErrorAs(t, err, &classified)
+		assert.True(t, classified.Retryable())
+		assert.Greater(t, classified.RetryDelay(), 900*time.Millisecond)
+		assert.Equal(t, int32(0), primary.calls.Load())
+	})
+}
+
+func TestFailoverSender_UnhealthyProvider(t *testing.T) {
+	ctx := context.Background()
+	primary := newFakeProvider(t, http.StatusServiceUnavailable)
+	secondary := newFakeProvider(t, http.StatusAccepted)
+	sender := NewFailoverSender([]Provider{
+		primary.provider("primary", 1, 0),
+		secondary.provider("secondary", 1, 1),
+	}, 2, time.Minute)
+	now := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	sender.now = func() time.Time { return now }
+
+	for range 2 {
+		_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "secondary", provider)
+	}
+	assert.False(t, sender.Health()[0].Healthy)
+
+	// An unhealthy provider is skipped while the others accept messages.
+	_, _, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), primary.calls.Load())
+
+	// Once the cooldown has passed it gets traffic again.
+	primary.status.Store(http.StatusAccepted)
+	now = now.Add(time.Minute)
+	assert.True(t, sender.Health()[0].Healthy)
+	_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", provider)
+	assert.Equal(t, 0, sender.Health()[0].ConsecutiveFailures)
+}
+
+func TestFailoverSender_UnhealthyProviderIsLastResort(t *testing.T) {
+	ctx := context.Background()
+	primary := newFakeProvider(t, http.StatusServiceUnavailable)
+	sender := NewFailoverSender([]Provider{primary.provider("primary", 1, 0)}, 1, time.Minute)
+
+	for range 2 {
+		_, _, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.Error(t, err)
+	}
+	assert.Equal(t, int32(2), primary.calls.Load())
+	assert.Equal(t, 2, sender.Health()[0].ConsecutiveFailures)
+}
+
+func TestFailoverSender_Weights(t *testing.T) {
+	ctx := context.Background()
+	light := newFakeProvider(t, http.StatusAccepted)
+	heavy := newFakeProvider(t, http.StatusAccepted)
+	sender := NewFailoverSender([]Provider{
+		light.provider("light", 1, 0),
+		heavy.provider("heavy", 3, 0),
+	}, 3, time.Minute)
+
+	// First picks in [0, 1) land on the first provider and first picks in [1, 4) on the second.
+	for pick, expected := range []string{"light", "heavy", "heavy", "heavy"} {
+		draws := 0
+		sender.intN = func(n int) int {
+			draws++
+			if draws > 1 {
+				return 0
+			}
+			assert.Equal(t, 4, n)
+			return pick
+		}
+		_, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, expected, provider)
+	}
+}
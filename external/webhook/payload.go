@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Content types of provider request bodies.
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+)
+
+// defaultExternalIDPath locates the message ID in webhook.site style responses.
+const defaultExternalIDPath = "messageId"
+
+// PayloadMapping describes the request a provider expects and where its response carries the message ID.
+// The zero value, or a nil *PayloadMapping, sends WebhookRequest as JSON and expects a 202 with WebhookResponse.
+type PayloadMapping struct {
+	body           *template.Template
+	contentType    string
+	successCodes   []int
+	externalIDPath []string
+}
+
+// PayloadData is what provider body templates are rendered with.
+type PayloadData struct {
+	To      string
+	Content string
+}
+
+// payloadFuncs escape values for the body formats providers take: {{json .Content}} writes a quoted
+// JSON string and {{form .Content}} a form-encoded value.
+var payloadFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"form": template.URLQueryEscaper,
+}
+
+// NewPayloadMapping compiles the mapping of a provider.
+//
+// bodyTemplate is a Go text/template rendered with PayloadData, e.g. {"to": {{json .To}}, "text": {{json .Content}}}
+// or To={{form .To}}&Body={{form .Content}}; empty keeps the default JSON body. contentType defaults to
+// application/json. successStatusCodes lists the responses that mean the message was accepted, 202 if empty.
+// externalIDPath is the dot separated path of the message ID in the JSON response, with numbers indexing
+// arrays, e.g. messages.0.message-id; it defaults to messageId.
+func NewPayloadMapping(bodyTemplate, contentType string, successStatusCodes []int, externalIDPath string) (*PayloadMapping, error) {
+	mapping := &PayloadMapping{
+		contentType:  contentType,
+		successCodes: successStatusCodes,
+	}
+	if bodyTemplate != "" {
+		body, err := template.New("body").Funcs(payloadFuncs).Option("missingkey=error").Parse(bodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+		mapping.body = body
+	}
+	for _, code := range successStatusCodes {
+		if code < 200 || code > 299 {
+			return nil, fmt.Errorf("success status code %d is not a 2xx code", code)
+		}
+	}
+	if externalIDPath != "" {
+		mapping.externalIDPath = strings.Split(externalIDPath, ".")
+		if slices.Contains(mapping.externalIDPath, "") {
+			return nil, fmt.Errorf("invalid external ID path %q", externalIDPath)
+		}
+	}
+	return mapping, nil
+}
+
+// encode renders the request body for a message and returns it with its content type.
+func (m *PayloadMapping) encode(to, content string) ([]byte, string, error) {
+	contentType := ContentTypeJSON
+	if m != nil && m.contentType != "" {
+		contentType = m.contentType
+	}
+	if m == nil || m.body == nil {
+		body, err := json.Marshal(WebhookRequest{To: to, Content: content})
+		return body, contentType, err
+	}
+
+	var body bytes.Buffer
+	if err := m.body.Execute(&body, PayloadData{To: to, Content: content}); err != nil {
+		return nil, "", fmt.Errorf("failed to render body template: %w", err)
+	}
+	return body.Bytes(), contentType, nil
+}
+
+// accepts reports whether the status code means the provider accepted the message.
+func (m *PayloadMapping) accepts(statusCode int) bool {
+	if m == nil || len(m.successCodes) == 0 {
+		return statusCode == http.StatusAccepted
+	}
+	return slices.Contains(m.successCodes, statusCode)
+}
+
+// externalID extracts the message ID from a JSON response body. Numbers found at the path are
+// returned in their decimal form. Returns an empty ID if the path does not lead to a string or number.
+func (m *PayloadMapping) externalID(body []byte) (string, error) {
+	path := []string{defaultExternalIDPath}
+	if m != nil && len(m.externalIDPath) > 0 {
+		path = m.externalIDPath
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	for _, key := range path {
+		switch node := value.(type) {
+		case map[string]any:
+			value = node[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", nil
+			}
+			value = node[index]
+		default:
+			return "", nil
+		}
+	}
+
+	switch id := value.(type) {
+	case string:
+		return id, nil
+	case json.Number:
+		return id.String(), nil
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSiteSender_Send_PayloadMapping(t *testing.T) {
+	tests := []struct {
+		name         string
+		bodyTemplate string
+		contentType  string
+		successCodes []int
+		idPath       string
+		wantBody     string
+		wantType     string
+		status       int
+		response     string
+		wantID       string
+	}{
+		{
+			name:     "Default",
+			wantBody: `{"to":"+1234567890","content":"Hi \"Bob\" \u0026 co"}`,
+			wantType: ContentTypeJSON,
+			status:   http.StatusAccepted,
+			response: `{"messageId":"ext-1","status":"accepted"}`,
+			wantID:   "ext-1",
+		},
+		{
+			name:         "Form Encoded",
+			bodyTemplate: `To={{form .To}}&From=GoNotify&Body={{form .Content}}`,
+			contentType:  ContentTypeForm,
+			successCodes: []int{http.StatusCreated},
+			idPath:       "sid",
+			wantBody:     `To=%2B1234567890&From=GoNotify&Body=Hi+%22Bob%22+%26+co`,
+			wantType:     ContentTypeForm,
+			status:       http.StatusCreated,
+			response:     `{"sid":"SM123","status":"queued"}`,
+			wantID:       "SM123",
+		},
+		{
+			name:         "Nested Array Path",
+			bodyTemplate: `{"to": {{json .To}}, "from": "GoNotify", "text": {{json .Content}}}`,
+			successCodes: []int{http.StatusOK},
+			idPath:       "messages.0.message-id",
+			wantBody:     `{"to": "+1234567890", "from": "GoNotify", "text": "Hi \"Bob\" \u0026 co"}`,
+			wantType:     ContentTypeJSON,
+			status:       http.StatusOK,
+			response:     `{"message-count":"1","messages":[{"to":"1234567890","message-id":"0A0000000123ABCD1","status":"0"}]}`,
+			wantID:       "0A0000000123ABCD1",
+		},
+		{
+			name:         "Numeric ID",
+			successCodes: []int{http.StatusOK, http.StatusAccepted},
+			idPath:       "data.id",
+			wantBody:     `{"to":"+1234567890","content":"Hi \"Bob\" \u0026 co"}`,
+			wantType:     ContentTypeJSON,
+			status:       http.StatusOK,
+			response:     `{"data":{"id":9007199254740993}}`,
+			wantID:       "9007199254740993",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody, gotType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotBody, gotType = string(body), r.Header.Get("Content-Type")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			mapping, err := NewPayloadMapping(tt.bodyTemplate, tt.contentType, tt.successCodes, tt.idPath)
+			require.NoError(t, err)
+			sender := NewWebhookSiteSender(server.URL, 250, time.Second, nil, mapping)
+
+			messageID, err := sender.Send(context.Background(), "+1234567890", `Hi "Bob" & co`)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, messageID)
+			assert.Equal(t, tt.wantBody, gotBody)
+			assert.Equal(t, tt.wantType, gotType)
+			if tt.wantType == ContentTypeForm {
+				form, err := url.ParseQuery(gotBody)
+				require.NoError(t, err)
+				assert.Equal(t, "+1234567890", form.Get("To"))
+				assert.Equal(t, `Hi "Bob" & co`, form.Get("Body"))
+			}
+		})
+	}
+}
+
+func TestWebhookSiteSender_Send_PayloadMappingErrors(t *testing.T) {
+	mapping, err := NewPayloadMapping("", "", []int{http.StatusOK}, "messages.0.message-id")
+	require.NoError(t, err)
+
+	send := func(status int, response string) error {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+		defer server.Close()
+		_, err := NewWebhookSiteSender(server.URL, 250, time.Second, nil, mapping).Send(context.Background(), "+1234567890", "hello")
+		return err
+	}
+
+	t.Run("Unexpected Status", func(t *testing.T) {
+		// A 202 is not a success for a provider that answers 200.
+		err := send(http.StatusAccepted, `{"messages":[{"message-id":"ext-1"}]}`)
+		var sendErr *SendError
+		require.ErrorAs(t, err, &sendErr)
+		assert.Equal(t, CategoryMalformedResponse, sendErr.Category)
+		assert.Contains(t, err.Error(), "unexpected status code: 202")
+	})
+
+	t.Run("Missing ID", func(t *testing.T) {
+		for _, response := range []string{`{"messages":[]}`, `{"messages":{"0":"ext-1"}}`, `{"messages":[{"message-id":null}]}`} {
+			err := send(http.StatusOK, response)
+			var sendErr *SendError
+			require.ErrorAs(t, err, &sendErr, response)
+			assert.Equal(t, CategoryMalformedResponse, sendErr.Category)
+			assert.Contains(t, err.Error(), "did not contain a message ID")
+		}
+	})
+}
+
+func TestNewPayloadMapping_Invalid(t *testing.T) {
+	_, err := NewPayloadMapping(`{"to": {{json .To}`, "", nil, "")
+	assert.Error(t, err)
+	_, err = NewPayloadMapping("", "", []int{404}, "")
+	assert.Error(t, err)
+	_, err = NewPayloadMapping("", "", nil, "messages..id")
+	assert.Error(t, err)
+
+	// Templates may only refer to the fields of PayloadData.
+	mapping, err := NewPayloadMapping(`{{.Recipient}}`, "", nil, "")
+	require.NoError(t, err)
+	_, _, err = mapping.encode("+1234567890", "hello")
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1752055200, 0)
+	body := []byte(`{"status":"sent"}`)
+
+	signature := Sign("secret", at, body)
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, Sign("secret", at, body))
+	assert.NotEqual(t, signature, Sign("other", at, body))
+	assert.NotEqual(t, signature, Sign("secret", at.Add(time.Second), body))
+	assert.NotEqual(t, signature, Sign("secret", at, []byte(`{"status":"failed"}`)))
+}
+
+// signedRequest returns a request with body signed by signer at the given time.
+func signedRequest(t *testing.T, signer *Signer, at time.Time, body []byte) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/hook", nil)
+	require.NoError(t, err)
+	signer.now = func() time.Time { return at }
+	signer.SignRequest(req, body)
+	return req
+}
+
+func TestSigner_SignRequest(t *testing.T) {
+	at := time.Unix(1752055200, 0)
+	body := []byte(`{"to":"+15551234567","content":"hello"}`)
+
+	single := signedRequest(t, NewSigner("new", ""), at, body)
+	assert.Equal(t, "1752055200", single.Header.Get(TimestampHeader))
+	assert.Equal(t, Sign("new", at, body), single.Header.Get(SignatureHeader))
+
+	// While rotating, the request carries a signature for each secret.
+	rotating := signedRequest(t, NewSigner("new", "old"), at, body)
+	assert.Equal(t, []string{Sign("new", at, body), Sign("old", at, body)},
+		strings.Split(rotating.Header.Get(SignatureHeader), ","))
+
+	unsigned := signedRequest(t, NewSigner("", ""), at, body)
+	assert.Empty(t, unsigned.Header.Get(SignatureHeader))
+	assert.Empty(t, unsigned.Header.Get(TimestampHeader))
+
+	var nilSigner *Signer
+	assert.False(t, nilSigner.Enabled())
+	assert.NotPanics(t, func() { nilSigner.SignRequest(single, body) })
+}
+
+func TestVerify(t *testing.T) {
+	at := time.Unix(1752055200, 0)
+	body := []byte(`{"external_message_id":"ext-1","status":"delivered"}`)
+	rotating := signedRequest(t, NewSigner("new", "old"), at, body)
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    []byte
+		secrets []string
+		now     time.Time
+		wantErr bool
+	}{
+		{"Current Secret", rotating.Header, body, []string{"new"}, at, false},
+		{"Previous Secret", rotating.Header, body, []string{"old"}, at, false},
+		{"Receiver Rotating", signedRequest(t, NewSigner("old", ""), at, body).Header, body, []string{"new", "old"}, at, false},
+		{"Within Skew", rotating.Header, body, []string{"new"}, at.Add(4 * time.Minute), false},
+		{"Unknown Secret", rotating.Header, body, []string{"other"}, at, true},
+		{"No Secrets", rotating.Header, body, nil, at, true},
+		{"Tampered Body", rotating.Header, []byte(`{"status":"undelivered"}`), []string{"new"}, at, true},
+		{"Replayed", rotating.Header, body, []string{"new"}, at.Add(6 * time.Minute), true},
+		{"From The Future", rotating.Header, body, []string{"new"}, at.Add(-6 * time.Minute), true},
+		{"Unsigned", http.Header{}, body, []string{"new"}, at, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.header, tt.body, tt.secrets, tt.now, DefaultMaxSkew)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
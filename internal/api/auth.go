@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader is the request header clients may send their API key in, instead of an
+// "Authorization: Bearer" header.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyContextKey is the request context key of the authenticated API key.
+type apiKeyContextKey struct{}
+
+// APIKeyFromContext returns the API key a request passed through AuthMiddleware was made with.
+// It reports false when the request was not authenticated, e.g. because authentication is disabled.
+func APIKeyFromContext(ctx context.Context) (*apikeys.Key, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*apikeys.Key)
+	return key, ok
+}
+
+// Authenticator looks up the API key a secret belongs to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, secret string) (*apikeys.Key, error)
+}
+
+// AuthMiddleware only lets requests made with an API key holding the right scope through.
+type AuthMiddleware struct {
+	authenticator Authenticator
+	disabled      bool
+	logger        *zap.Logger
+}
+
+// NewAuthMiddleware returns an AuthMiddleware checking keys with authenticator.
+// A disabled middleware lets every request through, for local development.
+func NewAuthMiddleware(authenticator Authenticator, disabled bool, logger *zap.Logger) *AuthMiddleware {
+	return &AuthMiddleware{
+		authenticator: authenticator,
+		disabled:      disabled,
+		logger:        logger,
+	}
+}
+
+// Require returns a handler that runs next only for requests carrying a valid API key granted scope.
+// Requests without a key, or with an unknown or revoked one, get 401; keys lacking the scope get 403.
+// The key is added to the request context, see APIKeyFromContext.
+func (m *AuthMiddleware) Require(scope apikeys.Scope, next http.HandlerFunc) http.HandlerFunc {
+	if m.disabled {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret := apiKeyFromRequest(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			WriteJSONErrorResponse(w, http.StatusUnauthorized, "Missing API key", nil)
+			return
+		}
+
+		key, err := m.authenticator.Authenticate(r.Context(), secret)
+		if err != nil {
+			if errors.Is(err, apikeys.ErrInvalidKey) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				WriteJSONErrorResponse(w, http.StatusUnauthorized, "Invalid API key", nil)
+				return
+			}
+			m.logger.Error("Failed to authenticate API key", zap.Error(err))
+			WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to authenticate API key", err)
+			return
+		}
+
+		if !key.HasScope(scope) {
+			m.logger.Warn("API key lacks the scope of the request", zap.String("key_id", key.ID),
+				zap.String("scope", string(scope)), zap.String("method", r.Method), zap.String("path", r.URL.Path))
+			WriteJSONErrorResponse(w, http.StatusForbidden, "API key is not allowed to perform this request",
+				errors.New("missing scope "+string(scope)))
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	}
+}
+
+// apiKeyFromRequest returns the key of an "Authorization: Bearer" header, or else of the X-API-Key header.
+func apiKeyFromRequest(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(APIKeyHeader))
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockAuthenticator is a mock of the Authenticator Interface.
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, secret string) (*apikeys.Key, error) {
+	args := m.Called(ctx, secret)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikeys.Key), args.Error(1)
+}
+
+func TestAuthMiddleware_Require(t *testing.T) {
+	mockAuth := new(MockAuthenticator)
+	middleware := NewAuthMiddleware(mockAuth, false, zap.NewNop())
+	handler := middleware.Require(apikeys.ScopeMessagesWrite, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	writer := &apikeys.Key{ID: "key-1", Scopes: []apikeys.Scope{apikeys.ScopeMessagesRead, apikeys.ScopeMessagesWrite}}
+	reader := &apikeys.Key{ID: "key-2", Scopes: []apikeys.Scope{apikeys.ScopeMessagesRead}}
+	mockAuth.On("Authenticate", mock.Anything, "gn_writer").Return(writer, nil)
+	mockAuth.On("Authenticate", mock.Anything, "gn_reader").Return(reader, nil)
+	mockAuth.On("Authenticate", mock.Anything, "gn_revoked").Return(nil, apikeys.ErrInvalidKey)
+	mockAuth.On("Authenticate", mock.Anything, "gn_unlucky").Return(nil, errors.New("connection refused"))
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"Bearer Token", "Authorization", "Bearer gn_writer", http.StatusCreated},
+		{"API Key Header", APIKeyHeader, "gn_writer", http.StatusCreated},
+		{"Missing Key", "", "", http.StatusUnauthorized},
+		{"Other Scheme", "Authorization", "Basic gn_writer", http.StatusUnauthorized},
+		{"Revoked Key", APIKeyHeader, "gn_revoked", http.StatusUnauthorized},
+		{"Missing Scope", APIKeyHeader, "gn_reader", http.StatusForbidden},
+		{"Authenticator Error", APIKeyHeader, "gn_unlucky", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/messages", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rr := httptest.NewRecorder()
+
+			handler(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_Disabled(t *testing.T) {
+	mockAuth := new(MockAuthenticator)
+	middleware := NewAuthMiddleware(mockAuth, true, zap.NewNop())
+	handler := middleware.Require(apikeys.ScopeSchedulerAdmin, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	rr := httptest.NewRecorder()
+
+	handler(rr, httptest.NewRequest(http.MethodPost, "/api/v1/scheduler?action=stop", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockAuth.AssertNotCalled(t, "Authenticate")
+}
+
+func TestRegisterRoutes_RequeueNeedsAdmin(t *testing.T) {
+	mockAuth := new(MockAuthenticator)
+	writer := &apikeys.Key{ID: "key-1", Scopes: []apikeys.Scope{apikeys.ScopeMessagesRead, apikeys.ScopeMessagesWrite}}
+	mockAuth.On("Authenticate", mock.Anything, "gn_writer").Return(writer, nil)
+	mux := http.NewServeMux()
+	NewRouterDependecies(mux, nil, nil, nil, nil, nil, NewAuthMiddleware(mockAuth, false, zap.NewNop()), zap.NewNop()).RegisterRoutes()
+
+	// Requeueing sends dead letters again, so write access to messages is not enough.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/messages/dead-letter/a1b2c3d4-e5f6-7890-1234-567890abcdef/requeue", nil)
+	req.Header.Set(APIKeyHeader, "gn_writer")
+	rr := httptest.NewRecorder()
+
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/idempotency"
+	"go.uber.org/zap"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header clients use to make retries of a request safe.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a previous request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize bounds the request body buffered to hash it.
+	maxIdempotentBodySize = 1 << 20
+)
+
+// IdempotencyMiddleware makes handlers safe to retry by remembering the response of
+// each Idempotency-Key for a configurable window.
+type IdempotencyMiddleware struct {
+	repo        idempotency.Repository
+	window      time.Duration
+	lockTimeout time.Duration // how long a key stays in progress if its request never completes
+	logger      *zap.Logger
+}
+
+// NewIdempotencyMiddleware returns an IdempotencyMiddleware keeping completed keys for window,
+// and keys of requests still in progress for lockTimeout.
+func NewIdempotencyMiddleware(repo idempotency.Repository, window, lockTimeout time.Duration, logger *zap.Logger) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		repo:        repo,
+		window:      window,
+		lockTimeout: lockTimeout,
+		logger:      logger,
+	}
+}
+
+// Wrap returns a handler that runs next at most once per Idempotency-Key of the authenticated API key.
+// Requests without the header are passed through unchanged. A replay with the same body gets the
+// original response, a reuse with a different body gets 422 and a replay while the original
+// request is still running gets 409. Server errors and panics release the key so the request can be retried,
+// and keys of requests that never complete, e.g. because the instance died, expire after the lock timeout.
+func (m *IdempotencyMiddleware) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid Idempotency-Key",
+				fmt.Errorf("key must be at most %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				WriteJSONErrorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large", err)
+				return
+			}
+			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		// Keys are chosen by clients, so each API key gets its own namespace of them.
+		var apiKeyID string
+		if apiKey, ok := APIKeyFromContext(r.Context()); ok {
+			apiKeyID = apiKey.ID
+		}
+		requestHash := hashRequest(r, apiKeyID, body)
+
+		record, reserved, err := m.repo.Reserve(r.Context(), apiKeyID, key, requestHash, m.lockTimeout)
+		if err != nil {
+			if errors.Is(err, idempotency.ErrKeyNotFound) {
+				WriteJSONErrorResponse(w, http.StatusConflict, "A request with this Idempotency-Key is in progress", err)
+				return
+			}
+			m.logger.Error("Failed to reserve idempotency key", zap.String("idempotency_key", key), zap.Error(err))
+			WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to process Idempotency-Key", err)
+			return
+		}
+
+		if !reserved {
+			m.replay(w, record, requestHash)
+			return
+		}
+
+		// The response is already written, so the outcome must be stored even if the client went away.
+		ctx := context.WithoutCancel(r.Context())
+		release := func() {
+			if err := m.repo.Release(ctx, apiKeyID, key); err != nil {
+				m.logger.Error("Failed to release idempotency key", zap.String("idempotency_key", key), zap.Error(err))
+			}
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+			next(rec, r)
+		}()
+
+		if rec.statusCode >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		responseBody := rec.body.Bytes()
+		if !json.Valid(responseBody) {
+			responseBody = nil
+		}
+		if err := m.repo.Complete(ctx, apiKeyID, key, rec.statusCode, responseBody, m.window); err != nil {
+			m.logger.Error("Failed to store idempotent response", zap.String("idempotency_key", key), zap.Error(err))
+		}
+	}
+}
+
+// replay answers a request whose key was already used.
+func (m *IdempotencyMiddleware) replay(w http.ResponseWriter, record *idempotency.Record, requestHash string) {
+	if record.RequestHash != requestHash {
+		WriteJSONErrorResponse(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request", nil)
+		return
+	}
+	if !record.Completed() {
+		WriteJSONErrorResponse(w, http.StatusConflict, "A request with this Idempotency-Key is in progress", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.ResponseBody)
+}
+
+// hashRequest returns the hex encoded SHA-256 of the API key ID, request method, path and body.
+func hashRequest(r *http.Request, apiKeyID string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(apiKeyID + "\n"))
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of its status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if !rr.wroteHeader {
+		rr.statusCode = statusCode
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+	"github.com/akshaysangma/go-notify/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockIdempotencyRepository is a mock of the idempotency.Repository Interface.
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, apiKeyID, key, requestHash string, lockTimeout time.Duration) (*idempotency.Record, bool, error) {
+	args := m.Called(ctx, apiKeyID, key, requestHash, lockTimeout)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*idempotency.Record), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, apiKeyID, key string, statusCode int, responseBody []byte, window time.Duration) error {
+	args := m.Called(ctx, apiKeyID, key, statusCode, responseBody, window)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, apiKeyID, key string) error {
+	args := m.Called(ctx, apiKeyID, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyMiddleware_Wrap(t *testing.T) {
+	const (
+		key         = "order-42"
+		body        = `{"content":"hello","recipients":["+1"]}`
+		window      = time.Hour
+		lockTimeout = time.Minute
+	)
+	created := `{"batch_id":"b1"}`
+
+	newRequest := func(key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		return req
+	}
+	requestHash := hashRequest(newRequest(key, body), "", []byte(body))
+
+	// newHandler returns a handler responding with statusCode that counts its calls.
+	newHandler := func(statusCode int, calls *int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			*calls++
+			WriteJSONResponse(w, statusCode, map[string]string{"batch_id": "b1"})
+		}
+	}
+
+	t.Run("No Key - Passed Through", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest("", body))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("First Request - Response Stored", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(nil, true, nil).Once()
+		repo.On("Complete", mock.Anything, "", key, http.StatusAccepted, mock.MatchedBy(func(b []byte) bool {
+			return strings.TrimSpace(string(b)) == created
+		}), window).Return(nil).Once()
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(key, body))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, 1, calls)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Replay - Original Response Returned", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		record := &idempotency.Record{Key: key, RequestHash: requestHash, StatusCode: http.StatusAccepted, ResponseBody: []byte(created)}
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(record, false, nil).Once()
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(key, body))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, created, rr.Body.String())
+		assert.Equal(t, "true", rr.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, 0, calls)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Different Body - Unprocessable Entity", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		record := &idempotency.Record{Key: key, RequestHash: "other-hash", StatusCode: http.StatusAccepted, ResponseBody: []byte(created)}
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(record, false, nil).Once()
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(key, body))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("In Progress - Conflict", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		record := &idempotency.Record{Key: key, RequestHash: requestHash}
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(record, false, nil).Once()
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(key, body))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Server Error - Key Released", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(nil, true, nil).Once()
+		repo.On("Release", mock.Anything, "", key).Return(nil).Once()
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusInternalServerError, &calls))(rr, newRequest(key, body))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Handler Panics - Key Released", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(nil, true, nil).Once()
+		repo.On("Release", mock.Anything, "", key).Return(nil).Once()
+		handler := NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+
+		assert.PanicsWithValue(t, "boom", func() { handler(httptest.NewRecorder(), newRequest(key, body)) })
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Body Too Large - Rejected Before Reserving", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(key, strings.Repeat("a", maxIdempotentBodySize+1)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		assert.Equal(t, 0, calls)
+		repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Repository Fails", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		repo.On("Reserve", mock.Anything, "", key, requestHash, lockTimeout).Return(nil, false, errors.New("db error")).Once()
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(key, body))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Key Too Long - Bad Request", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		calls := 0
+		rr := httptest.NewRecorder()
+
+		NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls))(rr, newRequest(strings.Repeat("k", 256), body))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Two API Keys - Same Key Scoped", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		mockAuth := new(MockAuthenticator)
+		mockAuth.On("Authenticate", mock.Anything, "gn_billing").Return(&apikeys.Key{ID: "key-1", Scopes: []apikeys.Scope{apikeys.ScopeMessagesWrite}}, nil)
+		mockAuth.On("Authenticate", mock.Anything, "gn_alerts").Return(&apikeys.Key{ID: "key-2", Scopes: []apikeys.Scope{apikeys.ScopeMessagesWrite}}, nil)
+		hash1 := hashRequest(newRequest(key, body), "key-1", []byte(body))
+		hash2 := hashRequest(newRequest(key, body), "key-2", []byte(body))
+		assert.NotEqual(t, hash1, hash2)
+		// Both clients reserve the key in their own namespace, so neither sees the other's response.
+		repo.On("Reserve", mock.Anything, "key-1", key, hash1, lockTimeout).Return(nil, true, nil).Once()
+		repo.On("Reserve", mock.Anything, "key-2", key, hash2, lockTimeout).Return(nil, true, nil).Once()
+		repo.On("Complete", mock.Anything, "key-1", key, http.StatusAccepted, mock.Anything, window).Return(nil).Once()
+		repo.On("Complete", mock.Anything, "key-2", key, http.StatusAccepted, mock.Anything, window).Return(nil).Once()
+		calls := 0
+		handler := NewAuthMiddleware(mockAuth, false, zap.NewNop()).Require(apikeys.ScopeMessagesWrite,
+			NewIdempotencyMiddleware(repo, window, lockTimeout, zap.NewNop()).Wrap(newHandler(http.StatusAccepted, &calls)))
+
+		for _, secret := range []string{"gn_billing", "gn_alerts"} {
+			req := newRequest(key, body)
+			req.Header.Set(APIKeyHeader, secret)
+			rr := httptest.NewRecorder()
+
+			handler(rr, req)
+
+			assert.Equal(t, http.StatusAccepted, rr.Code)
+			assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+		}
+		assert.Equal(t, 2, calls)
+		repo.AssertExpectations(t)
+	})
+}
@@ -6,28 +6,90 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/akshaysangma/go-notify/internal/templates"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // MessageServicer defines the interface for the message service accepted by message handler.
 type MessageServicer interface {
 	GetAllSentMessages(ctx context.Context, limit, offset int32) ([]messages.Message, error)
-	CreateMessages(ctx context.Context, content string, recipients []string, charLimit int) error
+	GetMessageByID(ctx context.Context, messageID string) (*messages.Message, error)
+	ListMessages(ctx context.Context, filter messages.MessageFilter) (messages.MessagePage, error)
+	CancelMessage(ctx context.Context, messageID string) (*messages.Message, error)
+	CancelBatch(ctx context.Context, batchID string) (int, error)
+	CreateMessages(ctx context.Context, req messages.BatchRequest, smsCharLimit int) (*messages.Batch, error)
+	GetDeadLetters(ctx context.Context, limit, offset int32) ([]messages.DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, messageID string) error
 }
 
 // CreateMessagesRequest defines the request body for creating a message for multiple recipients.
-// TODO: add validator for Recipients and content's length
 type CreateMessagesRequest struct {
-	Content    string   `json:"content" example:"This is a message for multiple users."`
+	// The content sent to every recipient. Leave empty when template_id is set.
+	Content    string   `json:"content,omitempty" example:"This is a message for multiple users."`
 	Recipients []string `json:"recipients" example:"['+15551112222', '+15553334444']"`
+	// Optional ID of a template to render the content, and the subject if none is given, from.
+	TemplateID string `json:"template_id,omitempty" example:"c3d4e5f6-a7b8-9012-3456-7890abcdef12"`
+	// Template variables of each recipient, keyed by recipient.
+	Variables map[string]map[string]any `json:"variables,omitempty" swaggertype:"object"`
+	// Optional delivery channel: sms (default), email or chat. Recipients are addresses on that channel.
+	Channel string `json:"channel,omitempty" example:"sms"`
+	// Optional subject line, used by channels that carry one such as email.
+	Subject string `json:"subject,omitempty" example:"Appointment confirmed"`
+	// Optional dispatch priority: critical, high, normal (default) or bulk.
+	Priority string `json:"priority,omitempty" example:"normal" enums:"critical,high,normal,bulk"`
+	// Optional RFC 3339 time before which the messages are not sent.
+	SendAt *time.Time `json:"send_at,omitempty" example:"2025-07-10T09:00:00Z"`
+	// Optional IANA time zone of the recipients, used for quiet hours. The configured default applies if empty.
+	Timezone string `json:"timezone,omitempty" example:"Europe/Berlin"`
+	// Optional http(s) URL that is posted a signed event once each message is sent or has failed for good.
+	CallbackURL string `json:"callback_url,omitempty" example:"https://example.com/hooks/notify"`
+}
+
+// CreatedMessage identifies a message created for one of the requested recipients.
+type CreatedMessage struct {
+	Recipient string `json:"recipient" example:"+15551112222"`
+	ID        string `json:"id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	Status    string `json:"status" example:"pending"`
+}
+
+// DeduplicatedMessage identifies a recipient skipped because it already has a message with the same content.
+type DeduplicatedMessage struct {
+	Recipient string `json:"recipient" example:"+15553334444"`
+	// ID of the existing message with the same content.
+	DuplicateOf string `json:"duplicate_of" example:"b2c3d4e5-f6a7-8901-2345-67890abcdef1"`
+}
+
+// CreateMessagesResponse defines the response body for messages accepted for delivery.
+type CreateMessagesResponse struct {
+	BatchID  string           `json:"batch_id" example:"f0e1d2c3-b4a5-6789-0123-456789abcdef"`
+	Messages []CreatedMessage `json:"messages"`
+	// Recipients skipped by deduplication, when it is enabled.
+	Deduplicated []DeduplicatedMessage `json:"deduplicated,omitempty"`
+}
+
+// ListMessagesResponse defines the response body for a page of messages.
+type ListMessagesResponse struct {
+	Messages []messages.Message `json:"messages"`
+	// Pass as the cursor query parameter to fetch the next page. Omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"MjAyNS0wNy0wOVQxMDowMTowMFosYTFiMmMzZDQtZTVmNi03ODkwLTEyMzQtNTY3ODkwYWJjZGVm"`
+}
+
+// CancelBatchResponse defines the response body for a cancelled batch.
+type CancelBatchResponse struct {
+	BatchID string `json:"batch_id" example:"f0e1d2c3-b4a5-6789-0123-456789abcdef"`
+	// Number of pending messages cancelled. Messages already being sent or sent are not affected.
+	Cancelled int `json:"cancelled" example:"42"`
 }
 
 // MessageHandler holds the dependencies for the message-related API handlers.
 type MessageHandler struct {
 	service              MessageServicer
-	allowedContentLength int
+	allowedContentLength int // content limit of sms messages, other channels have their own
 	logger               *zap.Logger
 }
 
@@ -50,6 +112,7 @@ func NewMessageHandler(service MessageServicer, contentLength int, logger *zap.L
 // @Param        offset  query      int    false  "Offset for pagination" default(0)
 // @Success      200     {array}    messages.Message "A list of sent messages"
 // @Failure      500     {object}   HTTPError "Failed to retrieve sent messages"
+// @Security     ApiKeyAuth
 // @Router /api/v1/messages/sent [get]
 func (h *MessageHandler) getSentMessages(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
@@ -72,16 +135,144 @@ func (h *MessageHandler) getSentMessages(w http.ResponseWriter, r *http.Request)
 	WriteJSONResponse(w, http.StatusOK, sentMessages)
 }
 
+// listMessages godoc
+// @Summary      Query messages
+// @Description  Gets messages matching the filters, most recently updated first, using keyset pagination on (updated_at, id).
+// @Description  Time filters are RFC 3339 timestamps; the lower bounds are inclusive and the upper bounds exclusive.
+// @Tags         messages
+// @Produce      json
+// @Param        status          query      []string false "Statuses to include (pending, sending, sent, failed, cancelled, delivered, undelivered)" collectionFormat(csv)
+// @Param        recipient       query      string   false "Recipient phone number"
+// @Param        external_id     query      string   false "External message ID returned by the provider"
+// @Param        created_after   query      string   false "Created at or after" format(date-time)
+// @Param        created_before  query      string   false "Created before" format(date-time)
+// @Param        updated_after   query      string   false "Updated at or after" format(date-time)
+// @Param        updated_before  query      string   false "Updated before" format(date-time)
+// @Param        limit           query      int      false "Number of messages to return" default(20)
+// @Param        cursor          query      string   false "next_cursor of the previous page"
+// @Success      200     {object}   ListMessagesResponse "A page of messages"
+// @Failure      400     {object}   HTTPError "Invalid filter or cursor"
+// @Failure      500     {object}   HTTPError "Failed to retrieve messages"
+// @Security     ApiKeyAuth
+// @Router /api/v1/messages [get]
+func (h *MessageHandler) listMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	filter := messages.MessageFilter{
+		Recipient:         query.Get("recipient"),
+		ExternalMessageID: query.Get("external_id"),
+		Limit:             int32(limit),
+	}
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+
+	timeFilters := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+	}
+	for _, tf := range timeFilters {
+		value := query.Get(tf.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid "+tf.param+", expected RFC 3339 timestamp", err)
+			return
+		}
+		*tf.dest = &t
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := messages.DecodeCursor(value)
+		if err != nil {
+			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid cursor", err)
+			return
+		}
+		filter.Cursor = cursor
+	}
+
+	page, err := h.service.ListMessages(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, messages.ErrInvalidStatus) || errors.Is(err, messages.ErrInvalidCursor) {
+			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid message filter", err)
+			return
+		}
+		h.logger.Error("Failed to list messages", zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve messages", err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, ListMessagesResponse{Messages: page.Messages, NextCursor: page.NextCursor})
+}
+
+// getMessage godoc
+// @Summary      Retrieve a message by ID
+// @Description  Gets a single message with its current status, external ID, last failure reason, timestamps and delivery attempt history.
+// @Tags         messages
+// @Produce      json
+// @Param        id      path       string true "Message ID"
+// @Success      200     {object}   messages.Message "The requested message"
+// @Failure      400     {object}   HTTPError "Invalid message ID"
+// @Failure      404     {object}   HTTPError "Message not found"
+// @Failure      500     {object}   HTTPError "Failed to retrieve message"
+// @Security     ApiKeyAuth
+// @Router /api/v1/messages/{id} [get]
+func (h *MessageHandler) getMessage(w http.ResponseWriter, r *http.Request) {
+	messageID := r.PathValue("id")
+	if _, err := uuid.Parse(messageID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	msg, err := h.service.GetMessageByID(r.Context(), messageID)
+	if err != nil {
+		if errors.Is(err, messages.ErrMessageNotFound) {
+			WriteJSONErrorResponse(w, http.StatusNotFound, "Message not found", err)
+			return
+		}
+		h.logger.Error("Failed to get message", zap.String("message_id", messageID), zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve message", err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, msg)
+}
+
 // createMessages godoc
 // @Summary      Create a message for multiple recipients
 // @Description  Creates a new message with the same content for a list of recipient phone numbers.
+// @Description  Returns the batch ID and the ID of the message created for each recipient, for later status polling.
+// @Description  Messages with a send_at are held until that time, which may not be in the past or beyond the scheduling horizon.
+// @Description  With a template_id, the template is rendered with the variables of each recipient and the result must fit the character limit.
+// @Description  With deduplication enabled, recipients that already have a message with the same content are listed under deduplicated instead.
+// @Description  With a callback_url, a signed status event is posted to it once each message is sent or has failed for good.
 // @Tags         messages
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Unique key making retries of this request safe"
 // @Param        message body       CreateMessagesRequest true "Message Content and Recipients"
-// @Success      202     {object}   SuccessResponse "Messages have been accepted for processing"
+// @Success      202     {object}   CreateMessagesResponse "Messages have been accepted for processing"
 // @Failure      400     {object}   HTTPError "Invalid request body or message content"
+// @Failure      409     {object}   HTTPError "A request with the same Idempotency-Key is in progress"
+// @Failure      422     {object}   HTTPError "Idempotency-Key was already used with a different request"
 // @Failure      500     {object}   HTTPError "Failed to save messages to the database"
+// @Security     ApiKeyAuth
 // @Router       /api/v1/messages [post]
 func (h *MessageHandler) createMessages(w http.ResponseWriter, r *http.Request) {
 	var req CreateMessagesRequest
@@ -90,9 +281,26 @@ func (h *MessageHandler) createMessages(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.service.CreateMessages(r.Context(), req.Content, req.Recipients, h.allowedContentLength)
+	batch, err := h.service.CreateMessages(r.Context(), messages.BatchRequest{
+		Content:     req.Content,
+		TemplateID:  req.TemplateID,
+		Variables:   req.Variables,
+		Channel:     req.Channel,
+		Subject:     req.Subject,
+		Recipients:  req.Recipients,
+		Priority:    req.Priority,
+		SendAt:      req.SendAt,
+		Timezone:    req.Timezone,
+		CallbackURL: req.CallbackURL,
+	}, h.allowedContentLength)
 	if err != nil {
-		if errors.Is(err, messages.ErrContentTooLong) || errors.Is(err, messages.ErrRecipientEmpty) {
+		if errors.Is(err, messages.ErrContentTooLong) || errors.Is(err, messages.ErrRecipientEmpty) ||
+			errors.Is(err, messages.ErrInvalidRecipient) ||
+			errors.Is(err, messages.ErrSendAtInPast) || errors.Is(err, messages.ErrSendAtTooFar) ||
+			errors.Is(err, messages.ErrInvalidPriority) || errors.Is(err, messages.ErrUnsupportedChannel) ||
+			errors.Is(err, messages.ErrInvalidTimezone) || errors.Is(err, messages.ErrContentAndTemplate) ||
+			errors.Is(err, templates.ErrTemplateNotFound) || errors.Is(err, templates.ErrInvalidTemplate) ||
+			errors.Is(err, templates.ErrRenderFailed) || errors.Is(err, messages.ErrInvalidCallbackURL) {
 			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid message data", err)
 			return
 		}
@@ -100,5 +308,148 @@ func (h *MessageHandler) createMessages(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	WriteJSONResponse(w, http.StatusAccepted, SuccessResponse{Message: "Messages accepted for creation."})
+	resp := CreateMessagesResponse{BatchID: batch.ID, Messages: []CreatedMessage{}}
+	for _, msg := range batch.Messages {
+		resp.Messages = append(resp.Messages, CreatedMessage{Recipient: msg.Recipient, ID: msg.ID, Status: msg.Status})
+	}
+	for _, duplicate := range batch.Deduplicated {
+		resp.Deduplicated = append(resp.Deduplicated, DeduplicatedMessage{Recipient: duplicate.Recipient, DuplicateOf: duplicate.MessageID})
+	}
+
+	WriteJSONResponse(w, http.StatusAccepted, resp)
+}
+
+// getDeadLetters godoc
+// @Summary      Retrieve a list of dead-lettered messages
+// @Description  Gets a paginated list of messages that exhausted their send attempts, including their attempt history.
+// @Tags         messages
+// @Produce      json
+// @Param        limit   query      int    false  "Number of messages to return" default(20)
+// @Param        offset  query      int    false  "Offset for pagination" default(0)
+// @Success      200     {array}    messages.DeadLetter "A list of dead-lettered messages"
+// @Failure      500     {object}   HTTPError "Failed to retrieve dead-letter messages"
+// @Security     ApiKeyAuth
+// @Router /api/v1/messages/dead-letter [get]
+func (h *MessageHandler) getDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = defaultOffset
+	}
+
+	deadLetters, err := h.service.GetDeadLetters(r.Context(), int32(limit), int32(offset))
+	if err != nil {
+		h.logger.Error("Failed to get dead-letter messages", zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve dead-letter messages", err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, deadLetters)
+}
+
+// requeueDeadLetter godoc
+// @Summary      Requeue a dead-lettered message
+// @Description  Removes a message from the dead-letter queue and returns it to 'pending' with a fresh set of attempts.
+// @Tags         messages
+// @Produce      json
+// @Param        id      path       string true "Message ID"
+// @Success      202     {object}   SuccessResponse "Message requeued for delivery"
+// @Failure      400     {object}   HTTPError "Invalid message ID"
+// @Failure      404     {object}   HTTPError "Message is not in the dead-letter queue"
+// @Failure      500     {object}   HTTPError "Failed to requeue message"
+// @Security     ApiKeyAuth
+// @Router /api/v1/messages/dead-letter/{id}/requeue [post]
+func (h *MessageHandler) requeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	messageID := r.PathValue("id")
+	if _, err := uuid.Parse(messageID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	err := h.service.RequeueDeadLetter(r.Context(), messageID)
+	if err != nil {
+		if errors.Is(err, messages.ErrDeadLetterNotFound) {
+			WriteJSONErrorResponse(w, http.StatusNotFound, "Message is not in the dead-letter queue", err)
+			return
+		}
+		h.logger.Error("Failed to requeue dead-letter message", zap.String("message_id", messageID), zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to requeue message", err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusAccepted, SuccessResponse{Message: "Message requeued for delivery."})
+}
+
+// cancelMessage godoc
+// @Summary      Cancel a message
+// @Description  Cancels a pending or scheduled message. Messages that are being sent, sent or failed cannot be cancelled.
+// @Tags         messages
+// @Produce      json
+// @Param        id      path       string true "Message ID"
+// @Success      200     {object}   messages.Message "The cancelled message"
+// @Failure      400     {object}   HTTPError "Invalid message ID"
+// @Failure      404     {object}   HTTPError "Message not found"
+// @Failure      409     {object}   HTTPError "Message is no longer pending"
+// @Failure      500     {object}   HTTPError "Failed to cancel message"
+// @Security     ApiKeyAuth
+// @Router /api/v1/messages/{id} [delete]
+func (h *MessageHandler) cancelMessage(w http.ResponseWriter, r *http.Request) {
+	messageID := r.PathValue("id")
+	if _, err := uuid.Parse(messageID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid message ID", err)
+		return
+	}
+
+	msg, err := h.service.CancelMessage(r.Context(), messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, messages.ErrMessageNotFound):
+			WriteJSONErrorResponse(w, http.StatusNotFound, "Message not found", err)
+		case errors.Is(err, messages.ErrNotCancellable):
+			WriteJSONErrorResponse(w, http.StatusConflict, "Message can no longer be cancelled", err)
+		default:
+			h.logger.Error("Failed to cancel message", zap.String("message_id", messageID), zap.Error(err))
+			WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to cancel message", err)
+		}
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, msg)
+}
+
+// cancelBatch godoc
+// @Summary      Cancel a batch of messages
+// @Description  Cancels every pending or scheduled message created by the same request. Messages already being sent are left alone.
+// @Tags         messages
+// @Produce      json
+// @Param        batch_id path      string true "Batch ID"
+// @Success      200     {object}   CancelBatchResponse "Number of cancelled messages"
+// @Failure      400     {object}   HTTPError "Invalid batch ID"
+// @Failure      404     {object}   HTTPError "Batch not found"
+// @Failure      500     {object}   HTTPError "Failed to cancel batch"
+// @Security     ApiKeyAuth
+// @Router /api/v1/messages/batches/{batch_id} [delete]
+func (h *MessageHandler) cancelBatch(w http.ResponseWriter, r *http.Request) {
+	batchID := r.PathValue("batch_id")
+	if _, err := uuid.Parse(batchID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid batch ID", err)
+		return
+	}
+
+	cancelled, err := h.service.CancelBatch(r.Context(), batchID)
+	if err != nil {
+		if errors.Is(err, messages.ErrBatchNotFound) {
+			WriteJSONErrorResponse(w, http.StatusNotFound, "Batch not found", err)
+			return
+		}
+		h.logger.Error("Failed to cancel batch", zap.String("batch_id", batchID), zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to cancel batch", err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, CancelBatchResponse{BatchID: batchID, Cancelled: cancelled})
 }
@@ -5,11 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/akshaysangma/go-notify/internal/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -29,8 +32,50 @@ func (m *MockMessageService) GetAllSentMessages(ctx context.Context, limit, offs
 	return args.Get(0).([]messages.Message), args.Error(1)
 }
 
-func (m *MockMessageService) CreateMessages(ctx context.Context, content string, recipients []string, charLimit int) error {
-	args := m.Called(ctx, content, recipients, charLimit)
+func (m *MockMessageService) GetMessageByID(ctx context.Context, messageID string) (*messages.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*messages.Message), args.Error(1)
+}
+
+func (m *MockMessageService) ListMessages(ctx context.Context, filter messages.MessageFilter) (messages.MessagePage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(messages.MessagePage), args.Error(1)
+}
+
+func (m *MockMessageService) CancelMessage(ctx context.Context, messageID string) (*messages.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*messages.Message), args.Error(1)
+}
+
+func (m *MockMessageService) CancelBatch(ctx context.Context, batchID string) (int, error) {
+	args := m.Called(ctx, batchID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageService) CreateMessages(ctx context.Context, req messages.BatchRequest, charLimit int) (*messages.Batch, error) {
+	args := m.Called(ctx, req, charLimit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*messages.Batch), args.Error(1)
+}
+
+func (m *MockMessageService) GetDeadLetters(ctx context.Context, limit, offset int32) ([]messages.DeadLetter, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]messages.DeadLetter), args.Error(1)
+}
+
+func (m *MockMessageService) RequeueDeadLetter(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
 	return args.Error(0)
 }
 
@@ -74,14 +119,156 @@ func TestMessageHandler_getSentMessages(t *testing.T) {
 	})
 }
 
+func TestMessageHandler_listMessages(t *testing.T) {
+	mockService := new(MockMessageService)
+	handler := NewMessageHandler(mockService, 250, zap.NewNop())
+
+	t.Run("Success - Filters Parsed", func(t *testing.T) {
+		cursor := messages.Cursor{UpdatedAt: time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC), ID: "a1b2c3d4-e5f6-7890-1234-567890abcdef"}
+		createdAfter := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		expectedFilter := messages.MessageFilter{
+			Statuses:     []string{"pending", "failed", "sent"},
+			Recipient:    "+15551234567",
+			CreatedAfter: &createdAfter,
+			Cursor:       &cursor,
+			Limit:        50,
+		}
+		page := messages.MessagePage{Messages: []messages.Message{{ID: "1", Status: "failed"}}, NextCursor: "next"}
+		mockService.On("ListMessages", mock.Anything, expectedFilter).Return(page, nil).Once()
+
+		url := "/api/v1/messages?status=pending,failed&status=sent&recipient=%2B15551234567&created_after=2025-07-01T00:00:00Z&limit=50&cursor=" + cursor.Encode()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rr := httptest.NewRecorder()
+
+		handler.listMessages(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body ListMessagesResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Equal(t, page.Messages, body.Messages)
+		assert.Equal(t, "next", body.NextCursor)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid Time", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages?updated_before=yesterday", nil)
+		rr := httptest.NewRecorder()
+
+		handler.listMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Bad Request - Invalid Cursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages?cursor=not-a-cursor", nil)
+		rr := httptest.NewRecorder()
+
+		handler.listMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Bad Request - Invalid Status", func(t *testing.T) {
+		mockService.On("ListMessages", mock.Anything, mock.Anything).Return(messages.MessagePage{}, fmt.Errorf("%w: %q", messages.ErrInvalidStatus, "done")).Once()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages?status=done", nil)
+		rr := httptest.NewRecorder()
+
+		handler.listMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Internal Server Error", func(t *testing.T) {
+		mockService.On("ListMessages", mock.Anything, mock.Anything).Return(messages.MessagePage{}, errors.New("db error")).Once()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+		rr := httptest.NewRecorder()
+
+		handler.listMessages(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestMessageHandler_getMessage(t *testing.T) {
+	mockService := new(MockMessageService)
+	handler := NewMessageHandler(mockService, 250, zap.NewNop())
+	messageID := "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages/"+id, nil)
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		failure := "webhook responded with non-202 status code: 503"
+		expected := &messages.Message{
+			ID:                messageID,
+			Status:            "pending",
+			LastFailureReason: &failure,
+			Attempts:          []messages.Attempt{{AttemptNumber: 1, InstanceID: "instance-1", Error: &failure}},
+		}
+		mockService.On("GetMessageByID", mock.Anything, messageID).Return(expected, nil).Once()
+		rr := httptest.NewRecorder()
+
+		handler.getMessage(rr, newRequest(messageID))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body messages.Message
+		err := json.Unmarshal(rr.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Equal(t, *expected, body)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		handler.getMessage(rr, newRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "GetMessageByID", mock.Anything, "not-a-uuid")
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("GetMessageByID", mock.Anything, messageID).Return(nil, fmt.Errorf("wrapped: %w", messages.ErrMessageNotFound)).Once()
+		rr := httptest.NewRecorder()
+
+		handler.getMessage(rr, newRequest(messageID))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Internal Server Error", func(t *testing.T) {
+		mockService.On("GetMessageByID", mock.Anything, messageID).Return(nil, errors.New("db error")).Once()
+		rr := httptest.NewRecorder()
+
+		handler.getMessage(rr, newRequest(messageID))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
 func TestMessageHandler_createMessages(t *testing.T) {
 	mockService := new(MockMessageService)
 	handler := NewMessageHandler(mockService, 250, zap.NewNop())
 
 	t.Run("Success - Accepted", func(t *testing.T) {
-		recipients := []string{"+12345"}
+		recipients := []string{"+12345", "+67890"}
 		content := "hello world"
-		mockService.On("CreateMessages", mock.Anything, content, recipients, 250).Return(nil).Once()
+		batch := &messages.Batch{
+			ID: "f0e1d2c3-b4a5-6789-0123-456789abcdef",
+			Messages: []messages.Message{
+				{ID: "a1b2c3d4-e5f6-7890-1234-567890abcdef", Recipient: "+12345", Status: "pending"},
+				{ID: "b1b2c3d4-e5f6-7890-1234-567890abcdef", Recipient: "+67890", Status: "pending"},
+			},
+		}
+		mockService.On("CreateMessages", mock.Anything, messages.BatchRequest{Content: content, Recipients: recipients}, 250).Return(batch, nil).Once()
 
 		reqBody := CreateMessagesRequest{
 			Content:    content,
@@ -95,10 +282,73 @@ func TestMessageHandler_createMessages(t *testing.T) {
 		handler.createMessages(rr, req)
 
 		assert.Equal(t, http.StatusAccepted, rr.Code)
-		var body SuccessResponse
+		var body CreateMessagesResponse
 		err := json.Unmarshal(rr.Body.Bytes(), &body)
 		assert.NoError(t, err)
-		assert.Equal(t, "Messages accepted for creation.", body.Message)
+		assert.Equal(t, CreateMessagesResponse{
+			BatchID: batch.ID,
+			Messages: []CreatedMessage{
+				{Recipient: "+12345", ID: "a1b2c3d4-e5f6-7890-1234-567890abcdef", Status: "pending"},
+				{Recipient: "+67890", ID: "b1b2c3d4-e5f6-7890-1234-567890abcdef", Status: "pending"},
+			},
+		}, body)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Success - Duplicates Reported", func(t *testing.T) {
+		batch := &messages.Batch{
+			ID:           "f0e1d2c3-b4a5-6789-0123-456789abcdef",
+			Messages:     []messages.Message{{ID: "a1b2c3d4-e5f6-7890-1234-567890abcdef", Recipient: "+12345", Status: "pending"}},
+			Deduplicated: []messages.Duplicate{{Recipient: "+67890", MessageID: "b2c3d4e5-f6a7-8901-2345-67890abcdef1"}},
+		}
+		mockService.On("CreateMessages", mock.Anything, messages.BatchRequest{Content: "dup", Recipients: []string{"+12345", "+67890"}}, 250).Return(batch, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"dup","recipients":["+12345","+67890"]}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		var body CreateMessagesResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Len(t, body.Messages, 1)
+		assert.Equal(t, []DeduplicatedMessage{{Recipient: "+67890", DuplicateOf: "b2c3d4e5-f6a7-8901-2345-67890abcdef1"}}, body.Deduplicated)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Success - Template", func(t *testing.T) {
+		batch := &messages.Batch{
+			ID:       "f0e1d2c3-b4a5-6789-0123-456789abcdef",
+			Messages: []messages.Message{{ID: "a1b2c3d4-e5f6-7890-1234-567890abcdef", Recipient: "+12345", Status: "pending"}},
+		}
+		mockService.On("CreateMessages", mock.Anything, messages.BatchRequest{
+			TemplateID: "c3d4e5f6-a7b8-9012-3456-7890abcdef12",
+			Recipients: []string{"+12345"},
+			Variables:  map[string]map[string]any{"+12345": {"name": "Ann"}},
+		}, 250).Return(batch, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"template_id":"c3d4e5f6-a7b8-9012-3456-7890abcdef12","recipients":["+12345"],"variables":{"+12345":{"name":"Ann"}}}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Template Render Error", func(t *testing.T) {
+		mockService.On("CreateMessages", mock.Anything, messages.BatchRequest{TemplateID: "c3d4e5f6-a7b8-9012-3456-7890abcdef12", Recipients: []string{"+67890"}}, 250).
+			Return(nil, fmt.Errorf("invalid message for recipient +67890: %w", templates.ErrRenderFailed)).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"template_id":"c3d4e5f6-a7b8-9012-3456-7890abcdef12","recipients":["+67890"]}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
@@ -114,7 +364,7 @@ func TestMessageHandler_createMessages(t *testing.T) {
 
 	t.Run("Bad Request - Service Validation Error", func(t *testing.T) {
 		validationErr := messages.ErrContentTooLong
-		mockService.On("CreateMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(validationErr).Once()
+		mockService.On("CreateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil, validationErr).Once()
 
 		reqBody := CreateMessagesRequest{Content: "too long", Recipients: []string{"+1"}}
 		jsonBody, _ := json.Marshal(reqBody)
@@ -133,9 +383,100 @@ func TestMessageHandler_createMessages(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Bad Request - Invalid send_at", func(t *testing.T) {
+		sendAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(req messages.BatchRequest) bool {
+			return req.SendAt != nil && req.SendAt.Equal(sendAt)
+		}), 250).Return(nil, fmt.Errorf("invalid message: %w", messages.ErrSendAtInPast)).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"hi","recipients":["+1"],"send_at":"2020-01-01T00:00:00Z"}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid priority", func(t *testing.T) {
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(req messages.BatchRequest) bool {
+			return req.Priority == "urgent"
+		}), 250).Return(nil, fmt.Errorf("%w: %q", messages.ErrInvalidPriority, "urgent")).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"hi","recipients":["+1"],"priority":"urgent"}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid timezone", func(t *testing.T) {
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(req messages.BatchRequest) bool {
+			return req.Timezone == "Mars/Olympus_Mons"
+		}), 250).Return(nil, fmt.Errorf("%w: %q", messages.ErrInvalidTimezone, "Mars/Olympus_Mons")).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"hi","recipients":["+1"],"timezone":"Mars/Olympus_Mons"}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid callback URL", func(t *testing.T) {
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(req messages.BatchRequest) bool {
+			return req.CallbackURL == "ftp://example.com"
+		}), 250).Return(nil, fmt.Errorf("%w: %q", messages.ErrInvalidCallbackURL, "ftp://example.com")).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"hi","recipients":["+1"],"callback_url":"ftp://example.com"}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Unsupported channel", func(t *testing.T) {
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(req messages.BatchRequest) bool {
+			return req.Channel == "pigeon"
+		}), 250).Return(nil, fmt.Errorf("%w: %q", messages.ErrUnsupportedChannel, "pigeon")).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"hi","recipients":["+1"],"channel":"pigeon"}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid recipient", func(t *testing.T) {
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(req messages.BatchRequest) bool {
+			return len(req.Recipients) == 1 && req.Recipients[0] == "jane@example.org"
+		}), 250).Return(nil, fmt.Errorf("%w: %q is not a phone number in E.164 format", messages.ErrInvalidRecipient, "jane@example.org")).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages",
+			bytes.NewBufferString(`{"content":"hi","recipients":["jane@example.org"]}`))
+		rr := httptest.NewRecorder()
+
+		handler.createMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("Internal Server Error", func(t *testing.T) {
 		serviceErr := errors.New("db insert failed")
-		mockService.On("CreateMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(serviceErr).Once()
+		mockService.On("CreateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil, serviceErr).Once()
 
 		reqBody := CreateMessagesRequest{Content: "content", Recipients: []string{"+1"}}
 		jsonBody, _ := json.Marshal(reqBody)
@@ -154,3 +495,164 @@ func TestMessageHandler_createMessages(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestMessageHandler_getDeadLetters(t *testing.T) {
+	mockService := new(MockMessageService)
+	handler := NewMessageHandler(mockService, 250, zap.NewNop())
+
+	t.Run("Success", func(t *testing.T) {
+		expected := []messages.DeadLetter{{MessageID: "1", FailureReason: "boom", Attempts: []messages.Attempt{}}}
+		mockService.On("GetDeadLetters", mock.Anything, int32(5), int32(10)).Return(expected, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages/dead-letter?limit=5&offset=10", nil)
+		rr := httptest.NewRecorder()
+
+		handler.getDeadLetters(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body []messages.DeadLetter
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, expected, body)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Internal Server Error", func(t *testing.T) {
+		mockService.On("GetDeadLetters", mock.Anything, int32(20), int32(0)).Return(nil, errors.New("db down")).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages/dead-letter", nil)
+		rr := httptest.NewRecorder()
+
+		handler.getDeadLetters(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestMessageHandler_requeueDeadLetter(t *testing.T) {
+	mockService := new(MockMessageService)
+	handler := NewMessageHandler(mockService, 250, zap.NewNop())
+	messageID := "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/messages/dead-letter/"+id+"/requeue", nil)
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	t.Run("Success - Accepted", func(t *testing.T) {
+		mockService.On("RequeueDeadLetter", mock.Anything, messageID).Return(nil).Once()
+		rr := httptest.NewRecorder()
+
+		handler.requeueDeadLetter(rr, newRequest(messageID))
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		handler.requeueDeadLetter(rr, newRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "RequeueDeadLetter", mock.Anything, "not-a-uuid")
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("RequeueDeadLetter", mock.Anything, messageID).Return(fmt.Errorf("wrapped: %w", messages.ErrDeadLetterNotFound)).Once()
+		rr := httptest.NewRecorder()
+
+		handler.requeueDeadLetter(rr, newRequest(messageID))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestMessageHandler_cancelMessage(t *testing.T) {
+	mockService := new(MockMessageService)
+	handler := NewMessageHandler(mockService, 250, zap.NewNop())
+	messageID := "a1b2c3d4-e5f6-7890-1234-567890abcdef"
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/messages/"+id, nil)
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "Success", id: messageID, wantStatus: http.StatusOK},
+		{name: "Bad Request - Invalid ID", id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+		{name: "Not Found", id: messageID, serviceErr: messages.ErrMessageNotFound, wantStatus: http.StatusNotFound},
+		{name: "Conflict - Not Pending", id: messageID, serviceErr: messages.ErrNotCancellable, wantStatus: http.StatusConflict},
+		{name: "Internal Server Error", id: messageID, serviceErr: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantStatus != http.StatusBadRequest {
+				var msg *messages.Message
+				if tt.serviceErr == nil {
+					msg = &messages.Message{ID: messageID, Status: "cancelled"}
+				}
+				mockService.On("CancelMessage", mock.Anything, tt.id).Return(msg, tt.serviceErr).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			handler.cancelMessage(rr, newRequest(tt.id))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageHandler_cancelBatch(t *testing.T) {
+	mockService := new(MockMessageService)
+	handler := NewMessageHandler(mockService, 250, zap.NewNop())
+	batchID := "f0e1d2c3-b4a5-6789-0123-456789abcdef"
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/messages/batches/"+id, nil)
+		req.SetPathValue("batch_id", id)
+		return req
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("CancelBatch", mock.Anything, batchID).Return(3, nil).Once()
+		rr := httptest.NewRecorder()
+
+		handler.cancelBatch(rr, newRequest(batchID))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body CancelBatchResponse
+		err := json.Unmarshal(rr.Body.Bytes(), &body)
+		assert.NoError(t, err)
+		assert.Equal(t, CancelBatchResponse{BatchID: batchID, Cancelled: 3}, body)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request - Invalid ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		handler.cancelBatch(rr, newRequest("not-a-uuid"))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("CancelBatch", mock.Anything, batchID).Return(0, fmt.Errorf("wrapped: %w", messages.ErrBatchNotFound)).Once()
+		rr := httptest.NewRecorder()
+
+		handler.cancelBatch(rr, newRequest(batchID))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
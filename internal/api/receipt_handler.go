@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/akshaysangma/go-notify/external/webhook"
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"go.uber.org/zap"
+)
+
+// maxReceiptBodySize bounds the receipt body read before its signature is checked.
+const maxReceiptBodySize = 64 << 10
+
+// ReceiptServicer defines the interface for the message service accepted by the receipt handler.
+type ReceiptServicer interface {
+	ApplyDeliveryReceipt(ctx context.Context, provider string, receipt messages.DeliveryReceipt) (*messages.Message, error)
+}
+
+// DeliveryReceiptRequest defines the request body of a provider's delivery receipt.
+type DeliveryReceiptRequest struct {
+	// The ID the provider returned when it accepted the message.
+	ExternalMessageID string `json:"external_message_id" example:"ext-msg-12345"`
+	// The delivery state of the message: delivered or undelivered.
+	Status string `json:"status" example:"delivered" enums:"delivered,undelivered"`
+	// Optional reason the message was not delivered.
+	Reason string `json:"reason,omitempty" example:"handset unreachable"`
+}
+
+// DeliveryReceiptResponse identifies the message a receipt was applied to.
+type DeliveryReceiptResponse struct {
+	MessageID string `json:"message_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	Status    string `json:"status" example:"delivered"`
+}
+
+// ReceiptHandler holds the dependencies for the delivery receipt API handler.
+type ReceiptHandler struct {
+	service ReceiptServicer
+	secrets map[string][]string // receipt signing secrets by provider name
+	logger  *zap.Logger
+}
+
+// NewReceiptHandler creates a new ReceiptHandler. Only providers with secrets can post receipts; a receipt
+// signed with any of its provider's secrets is accepted, so they can be rotated.
+func NewReceiptHandler(service ReceiptServicer, secrets map[string][]string, logger *zap.Logger) *ReceiptHandler {
+	return &ReceiptHandler{
+		service: service,
+		secrets: secrets,
+		logger:  logger,
+	}
+}
+
+// postReceipt godoc
+// @Summary      Apply a delivery receipt from a provider
+// @Description  Moves the sent message the provider accepted under external_message_id to delivered or undelivered.
+// @Description  The request must carry X-Timestamp (Unix seconds, within 5 minutes of now) and X-Signature, the hex HMAC-SHA256
+// @Description  of "<timestamp>.<body>" keyed with a receipt secret of the provider, or a comma separated list of such signatures.
+// @Description  A repeated receipt is accepted without changes.
+// @Tags         providers
+// @Accept       json
+// @Produce      json
+// @Param        provider     path       string true "Provider name"
+// @Param        X-Timestamp  header     string true "Unix time the receipt was signed at"
+// @Param        X-Signature  header     string true "Hex HMAC-SHA256 of the timestamp and body"
+// @Param        receipt      body       DeliveryReceiptRequest true "Delivery receipt"
+// @Success      200     {object}   DeliveryReceiptResponse "Receipt applied"
+// @Failure      400     {object}   HTTPError "Invalid request body or receipt"
+// @Failure      401     {object}   HTTPError "Missing or invalid signature"
+// @Failure      404     {object}   HTTPError "Unknown provider or message"
+// @Failure      409     {object}   HTTPError "Message is not awaiting a delivery receipt"
+// @Failure      500     {object}   HTTPError "Failed to apply receipt"
+// @Router       /api/v1/providers/{provider}/receipts [post]
+func (h *ReceiptHandler) postReceipt(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+	secrets, ok := h.secrets[provider]
+	if !ok {
+		WriteJSONErrorResponse(w, http.StatusNotFound, "Unknown provider", fmt.Errorf("no receipt secret configured for provider %q", provider))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxReceiptBodySize))
+	if err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := webhook.Verify(r.Header, body, secrets, time.Now(), webhook.DefaultMaxSkew); err != nil {
+		h.logger.Warn("Rejected delivery receipt", zap.String("provider", provider), zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusUnauthorized, "Invalid signature", err)
+		return
+	}
+
+	var req DeliveryReceiptRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	msg, err := h.service.ApplyDeliveryReceipt(r.Context(), provider, messages.DeliveryReceipt{
+		ExternalMessageID: req.ExternalMessageID,
+		Status:            req.Status,
+		Reason:            req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, messages.ErrInvalidReceipt):
+			WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid receipt", err)
+		case errors.Is(err, messages.ErrMessageNotFound):
+			WriteJSONErrorResponse(w, http.StatusNotFound, "Message not found", err)
+		case errors.Is(err, messages.ErrNotAwaitingReceipt):
+			WriteJSONErrorResponse(w, http.StatusConflict, "Message is not awaiting a delivery receipt", err)
+		default:
+			h.logger.Error("Failed to apply delivery receipt", zap.String("provider", provider), zap.Error(err))
+			WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to apply receipt", err)
+		}
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, DeliveryReceiptResponse{MessageID: msg.ID, Status: msg.Status})
+}
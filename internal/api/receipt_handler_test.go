@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/external/webhook"
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockReceiptService is a mock of the ReceiptServicer Interface.
+type MockReceiptService struct {
+	mock.Mock
+}
+
+func (m *MockReceiptService) ApplyDeliveryReceipt(ctx context.Context, provider string, receipt messages.DeliveryReceipt) (*messages.Message, error) {
+	args := m.Called(ctx, provider, receipt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*messages.Message), args.Error(1)
+}
+
+// newReceiptRequest builds a receipt request for provider signed with secret at signedAt.
+func newReceiptRequest(provider, secret string, signedAt time.Time, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/"+provider+"/receipts", bytes.NewBufferString(body))
+	req.SetPathValue("provider", provider)
+	req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(signedAt.Unix(), 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(secret, signedAt, []byte(body)))
+	return req
+}
+
+func TestReceiptHandler_PostReceipt(t *testing.T) {
+	mockService := new(MockReceiptService)
+	handler := NewReceiptHandler(mockService, map[string][]string{"primary": {"s3cret", "previous"}}, zap.NewNop())
+	body := `{"external_message_id":"ext-1","status":"delivered"}`
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("ApplyDeliveryReceipt", mock.Anything, "primary",
+			messages.DeliveryReceipt{ExternalMessageID: "ext-1", Status: "delivered"}).
+			Return(&messages.Message{ID: "msg-1", Status: "delivered"}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		handler.postReceipt(rr, newReceiptRequest("primary", "s3cret", time.Now(), body))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var resp DeliveryReceiptResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, DeliveryReceiptResponse{MessageID: "msg-1", Status: "delivered"}, resp)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Previous Secret", func(t *testing.T) {
+		mockService.On("ApplyDeliveryReceipt", mock.Anything, "primary", mock.Anything).
+			Return(&messages.Message{ID: "msg-1", Status: "delivered"}, nil).Once()
+		rr := httptest.NewRecorder()
+
+		handler.postReceipt(rr, newReceiptRequest("primary", "previous", time.Now(), body))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Provider", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.postReceipt(rr, newReceiptRequest("backup", "s3cret", time.Now(), body))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.postReceipt(rr, newReceiptRequest("primary", "guess", time.Now(), body))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Tampered Body", func(t *testing.T) {
+		req := newReceiptRequest("primary", "s3cret", time.Now(), body)
+		req.Body = http.NoBody
+		rr := httptest.NewRecorder()
+		handler.postReceipt(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Replayed Receipt", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.postReceipt(rr, newReceiptRequest("primary", "s3cret", time.Now().Add(-10*time.Minute), body))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Missing Signature", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/providers/primary/receipts", bytes.NewBufferString(body))
+		req.SetPathValue("provider", "primary")
+		rr := httptest.NewRecorder()
+		handler.postReceipt(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	errorCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Invalid Receipt", messages.ErrInvalidReceipt, http.StatusBadRequest},
+		{"Message Not Found", messages.ErrMessageNotFound, http.StatusNotFound},
+		{"Not Awaiting Receipt", messages.ErrNotAwaitingReceipt, http.StatusConflict},
+		{"Internal Error", assert.AnError, http.StatusInternalServerError},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService.On("ApplyDeliveryReceipt", mock.Anything, "primary", mock.Anything).Return(nil, tt.err).Once()
+			rr := httptest.NewRecorder()
+
+			handler.postReceipt(rr, newReceiptRequest("primary", "s3cret", time.Now(), body))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	_ "github.com/akshaysangma/go-notify/docs"
+	"github.com/akshaysangma/go-notify/internal/apikeys"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 )
@@ -12,29 +13,63 @@ type RouterDependecies struct {
 	mux              *http.ServeMux
 	messageHandler   *MessageHandler
 	schedulerHandler *SchedulerHandler
+	templateHandler  *TemplateHandler
+	receiptHandler   *ReceiptHandler
+	idempotency      *IdempotencyMiddleware
+	auth             *AuthMiddleware
 	logger           *zap.Logger
 }
 
 func NewRouterDependecies(mux *http.ServeMux,
 	msgHandler *MessageHandler,
 	schHandler *SchedulerHandler,
+	tmplHandler *TemplateHandler,
+	rcptHandler *ReceiptHandler,
+	idempotency *IdempotencyMiddleware,
+	auth *AuthMiddleware,
 	logger *zap.Logger) *RouterDependecies {
 	return &RouterDependecies{
 		mux:              mux,
 		logger:           logger,
 		messageHandler:   msgHandler,
 		schedulerHandler: schHandler,
+		templateHandler:  tmplHandler,
+		receiptHandler:   rcptHandler,
+		idempotency:      idempotency,
+		auth:             auth,
 	}
 }
 
+// RegisterRoutes registers every API route. Client APIs require an API key with the scope of the route;
+// provider callbacks are authenticated by their signature instead, and the Swagger UI is public.
 func (r *RouterDependecies) RegisterRoutes() {
+	read := func(next http.HandlerFunc) http.HandlerFunc { return r.auth.Require(apikeys.ScopeMessagesRead, next) }
+	write := func(next http.HandlerFunc) http.HandlerFunc { return r.auth.Require(apikeys.ScopeMessagesWrite, next) }
+	admin := func(next http.HandlerFunc) http.HandlerFunc { return r.auth.Require(apikeys.ScopeSchedulerAdmin, next) }
+
 	// Scheduler releated APIs
-	r.mux.HandleFunc("POST /api/v1/scheduler", r.schedulerHandler.schedulerControl)
-	r.mux.HandleFunc("GET /api/v1/scheduler", r.schedulerHandler.getSchedulerStatus)
+	r.mux.HandleFunc("POST /api/v1/scheduler", admin(r.schedulerHandler.schedulerControl))
+	r.mux.HandleFunc("GET /api/v1/scheduler", admin(r.schedulerHandler.getSchedulerStatus))
 
 	// Messages related APIs
-	r.mux.HandleFunc("GET /api/v1/messages/sent", r.messageHandler.getSentMessages)
-	r.mux.HandleFunc("POST /api/v1/messages", r.messageHandler.createMessages)
+	r.mux.HandleFunc("GET /api/v1/messages", read(r.messageHandler.listMessages))
+	r.mux.HandleFunc("GET /api/v1/messages/sent", read(r.messageHandler.getSentMessages))
+	r.mux.HandleFunc("POST /api/v1/messages", write(r.idempotency.Wrap(r.messageHandler.createMessages)))
+	r.mux.HandleFunc("GET /api/v1/messages/{id}", read(r.messageHandler.getMessage))
+	r.mux.HandleFunc("DELETE /api/v1/messages/{id}", write(r.messageHandler.cancelMessage))
+	r.mux.HandleFunc("DELETE /api/v1/messages/batches/{batch_id}", write(r.messageHandler.cancelBatch))
+	r.mux.HandleFunc("GET /api/v1/messages/dead-letter", read(r.messageHandler.getDeadLetters))
+	r.mux.HandleFunc("POST /api/v1/messages/dead-letter/{id}/requeue", admin(r.messageHandler.requeueDeadLetter))
+
+	// Templates related APIs
+	r.mux.HandleFunc("POST /api/v1/templates", write(r.templateHandler.createTemplate))
+	r.mux.HandleFunc("GET /api/v1/templates", read(r.templateHandler.listTemplates))
+	r.mux.HandleFunc("GET /api/v1/templates/{id}", read(r.templateHandler.getTemplate))
+	r.mux.HandleFunc("PUT /api/v1/templates/{id}", write(r.templateHandler.updateTemplate))
+	r.mux.HandleFunc("DELETE /api/v1/templates/{id}", write(r.templateHandler.deleteTemplate))
+
+	// Provider callbacks, signed by the provider
+	r.mux.HandleFunc("POST /api/v1/providers/{provider}/receipts", r.receiptHandler.postReceipt)
 
 	// Swagger UI
 	r.mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/akshaysangma/go-notify/internal/breaker"
+	"github.com/akshaysangma/go-notify/internal/messages"
 	"github.com/akshaysangma/go-notify/internal/scheduler"
 	"go.uber.org/zap"
 )
@@ -22,16 +24,37 @@ type SchedulerController interface {
 	IsRunning() bool
 }
 
+// RecoveryStatsProvider defines the interface for reading the recovery sweeper's counters.
+type RecoveryStatsProvider interface {
+	Stats() scheduler.RecoveryStats
+}
+
+// ProviderHealthReporter defines the interface for reading the health of the sms providers.
+type ProviderHealthReporter interface {
+	Health() []messages.ProviderHealth
+}
+
+// BreakerStatsProvider defines the interface for reading the state of the sms circuit breaker.
+type BreakerStatsProvider interface {
+	Stats() breaker.Stats
+}
+
 // SchedulerHandler holds the dependencies for the message-related API handlers.
 type SchedulerHandler struct {
 	scheduler SchedulerController
+	recovery  RecoveryStatsProvider
+	providers ProviderHealthReporter
+	breaker   BreakerStatsProvider
 	logger    *zap.Logger
 }
 
 // NewSchedulerHandler creates and configures a new SchedulerHandler using the standard library's ServeMux.
-func NewSchedulerHandler(scheduler SchedulerController, logger *zap.Logger) *SchedulerHandler {
+func NewSchedulerHandler(scheduler SchedulerController, recovery RecoveryStatsProvider, providers ProviderHealthReporter, breaker BreakerStatsProvider, logger *zap.Logger) *SchedulerHandler {
 	h := &SchedulerHandler{
 		scheduler: scheduler,
+		recovery:  recovery,
+		providers: providers,
+		breaker:   breaker,
 		logger:    logger,
 	}
 	return h
@@ -40,18 +63,32 @@ func NewSchedulerHandler(scheduler SchedulerController, logger *zap.Logger) *Sch
 // SchedulerStatusResponse represents the response for the scheduler status endpoint.
 type SchedulerStatusResponse struct {
 	Status string `json:"status" example:"running"`
+	// Messages returned to 'pending' after their sending lease expired, since startup.
+	RecoveredMessages int64 `json:"recovered_messages" example:"3"`
+	// Messages marked 'failed' after exceeding the maximum number of recoveries, since startup.
+	RecoveryFailedMessages int64 `json:"recovery_failed_messages" example:"0"`
+	// Health of the sms providers, by priority.
+	Providers []messages.ProviderHealth `json:"providers"`
+	// State of the sms circuit breaker. While it is open, messages are left pending.
+	CircuitBreaker breaker.Stats `json:"circuit_breaker"`
 }
 
 // getSchedulerStatus godoc
 // @Summary      Get the current status of the scheduler
-// @Description  Returns whether the scheduler is currently running or stopped.
+// @Description  Returns whether the scheduler is currently running or stopped, along with recovery sweeper counters, the health of the sms providers and the state of their circuit breaker.
 // @Tags         scheduler
 // @Produce      json
 // @Success      200 {object} SchedulerStatusResponse "Current status of the scheduler"
+// @Security     ApiKeyAuth
 // @Router /api/v1/scheduler [get]
 func (h *SchedulerHandler) getSchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	stats := h.recovery.Stats()
 	resp := SchedulerStatusResponse{
-		Status: "stopped",
+		Status:                 "stopped",
+		RecoveredMessages:      stats.Recovered,
+		RecoveryFailedMessages: stats.Failed,
+		Providers:              h.providers.Health(),
+		CircuitBreaker:         h.breaker.Stats(),
 	}
 	if h.scheduler.IsRunning() {
 		resp.Status = "running"
@@ -70,6 +107,7 @@ func (h *SchedulerHandler) getSchedulerStatus(w http.ResponseWriter, r *http.Req
 // @Failure      400  {object}  HTTPError "Invalid or missing 'action' parameter"
 // @Failure      409  {object}  HTTPError "Scheduler is already in the desired state"
 // @Failure      500  {object}  HTTPError "Internal server error while performing the action"
+// @Security     ApiKeyAuth
 // @Router /api/v1/scheduler [post]
 func (h *SchedulerHandler) schedulerControl(w http.ResponseWriter, r *http.Request) {
 	action := r.URL.Query().Get("action")
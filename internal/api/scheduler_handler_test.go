@@ -7,9 +7,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/akshaysangma/go-notify/internal/breaker"
+	"github.com/akshaysangma/go-notify/internal/messages"
 	"github.com/akshaysangma/go-notify/internal/scheduler"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -33,11 +36,50 @@ func (m *MockScheduler) IsRunning() bool {
 	return args.Bool(0)
 }
 
+// MockRecoveryStats is a mock of the RecoveryStatsProvider interface.
+type MockRecoveryStats struct {
+	mock.Mock
+}
+
+func (m *MockRecoveryStats) Stats() scheduler.RecoveryStats {
+	args := m.Called()
+	return args.Get(0).(scheduler.RecoveryStats)
+}
+
+// MockBreakerStats is a mock of the BreakerStatsProvider interface.
+type MockBreakerStats struct {
+	mock.Mock
+}
+
+func (m *MockBreakerStats) Stats() breaker.Stats {
+	args := m.Called()
+	return args.Get(0).(breaker.Stats)
+}
+
+// MockProviderHealth is a mock of the ProviderHealthReporter interface.
+type MockProviderHealth struct {
+	mock.Mock
+}
+
+func (m *MockProviderHealth) Health() []messages.ProviderHealth {
+	args := m.Called()
+	return args.Get(0).([]messages.ProviderHealth)
+}
+
 func TestSchedulerHandler_getSchedulerStatus(t *testing.T) {
 	t.Run("Status Running", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		mockRecovery := new(MockRecoveryStats)
+		mockProviders := new(MockProviderHealth)
+		mockBreaker := new(MockBreakerStats)
+		handler := NewSchedulerHandler(mockScheduler, mockRecovery, mockProviders, mockBreaker, zap.NewNop())
 		mockScheduler.On("IsRunning").Return(true).Once()
+		mockBreaker.On("Stats").Return(breaker.Stats{Name: "sms", State: breaker.StateOpen, ConsecutiveFailures: 5, Opens: 1}).Once()
+		mockRecovery.On("Stats").Return(scheduler.RecoveryStats{Recovered: 4, Failed: 1}).Once()
+		mockProviders.On("Health").Return([]messages.ProviderHealth{
+			{Name: "primary", Weight: 1, Healthy: false, ConsecutiveFailures: 3, LastError: "503"},
+			{Name: "secondary", Priority: 1, Weight: 1, Healthy: true},
+		}).Once()
 
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/scheduler", nil)
 		rr := httptest.NewRecorder()
@@ -49,13 +91,31 @@ func TestSchedulerHandler_getSchedulerStatus(t *testing.T) {
 		err := json.Unmarshal(rr.Body.Bytes(), &body)
 		assert.NoError(t, err)
 		assert.Equal(t, "running", body.Status)
+		assert.Equal(t, int64(4), body.RecoveredMessages)
+		assert.Equal(t, int64(1), body.RecoveryFailedMessages)
+		require.Len(t, body.Providers, 2)
+		assert.Equal(t, "primary", body.Providers[0].Name)
+		assert.False(t, body.Providers[0].Healthy)
+		assert.Equal(t, 3, body.Providers[0].ConsecutiveFailures)
+		assert.True(t, body.Providers[1].Healthy)
+		assert.Equal(t, breaker.StateOpen, body.CircuitBreaker.State)
+		assert.Equal(t, 5, body.CircuitBreaker.ConsecutiveFailures)
+		mockBreaker.AssertExpectations(t)
 		mockScheduler.AssertExpectations(t)
+		mockRecovery.AssertExpectations(t)
+		mockProviders.AssertExpectations(t)
 	})
 
 	t.Run("Status Stopped", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		mockRecovery := new(MockRecoveryStats)
+		mockProviders := new(MockProviderHealth)
+		mockBreaker := new(MockBreakerStats)
+		handler := NewSchedulerHandler(mockScheduler, mockRecovery, mockProviders, mockBreaker, zap.NewNop())
 		mockScheduler.On("IsRunning").Return(false).Once()
+		mockBreaker.On("Stats").Return(breaker.Stats{Name: "sms", State: breaker.StateClosed}).Once()
+		mockRecovery.On("Stats").Return(scheduler.RecoveryStats{}).Once()
+		mockProviders.On("Health").Return([]messages.ProviderHealth{}).Once()
 
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/scheduler", nil)
 		rr := httptest.NewRecorder()
@@ -74,7 +134,7 @@ func TestSchedulerHandler_getSchedulerStatus(t *testing.T) {
 func TestSchedulerHandler_schedulerControl(t *testing.T) {
 	t.Run("Start Success", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		handler := NewSchedulerHandler(mockScheduler, new(MockRecoveryStats), new(MockProviderHealth), new(MockBreakerStats), zap.NewNop())
 		mockScheduler.On("Start").Return(nil).Once()
 
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler?action=start", nil)
@@ -91,7 +151,7 @@ func TestSchedulerHandler_schedulerControl(t *testing.T) {
 
 	t.Run("Start Conflict - Already Running", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		handler := NewSchedulerHandler(mockScheduler, new(MockRecoveryStats), new(MockProviderHealth), new(MockBreakerStats), zap.NewNop())
 		mockScheduler.On("Start").Return(scheduler.ErrAlreadyRunning).Once()
 
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler?action=start", nil)
@@ -105,7 +165,7 @@ func TestSchedulerHandler_schedulerControl(t *testing.T) {
 
 	t.Run("Stop Success", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		handler := NewSchedulerHandler(mockScheduler, new(MockRecoveryStats), new(MockProviderHealth), new(MockBreakerStats), zap.NewNop())
 		mockScheduler.On("Stop").Return(nil).Once()
 
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler?action=stop", nil)
@@ -122,7 +182,7 @@ func TestSchedulerHandler_schedulerControl(t *testing.T) {
 
 	t.Run("Stop Conflict - Not Running", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		handler := NewSchedulerHandler(mockScheduler, new(MockRecoveryStats), new(MockProviderHealth), new(MockBreakerStats), zap.NewNop())
 		mockScheduler.On("Stop").Return(scheduler.ErrNotRunning).Once()
 
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler?action=stop", nil)
@@ -136,7 +196,7 @@ func TestSchedulerHandler_schedulerControl(t *testing.T) {
 
 	t.Run("Internal Server Error on Start", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		handler := NewSchedulerHandler(mockScheduler, new(MockRecoveryStats), new(MockProviderHealth), new(MockBreakerStats), zap.NewNop())
 		internalErr := errors.New("something broke")
 		mockScheduler.On("Start").Return(internalErr).Once()
 
@@ -151,7 +211,7 @@ func TestSchedulerHandler_schedulerControl(t *testing.T) {
 
 	t.Run("Invalid Action", func(t *testing.T) {
 		mockScheduler := new(MockScheduler)
-		handler := NewSchedulerHandler(mockScheduler, zap.NewNop())
+		handler := NewSchedulerHandler(mockScheduler, new(MockRecoveryStats), new(MockProviderHealth), new(MockBreakerStats), zap.NewNop())
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler?action=invalid", nil)
 		rr := httptest.NewRecorder()
 
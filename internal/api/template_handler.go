@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/akshaysangma/go-notify/internal/templates"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TemplateServicer defines the interface for the template service accepted by template handler.
+type TemplateServicer interface {
+	CreateTemplate(ctx context.Context, tmpl templates.Template) (*templates.Template, error)
+	GetTemplate(ctx context.Context, templateID string) (*templates.Template, error)
+	ListTemplates(ctx context.Context, limit, offset int32) ([]templates.Template, error)
+	UpdateTemplate(ctx context.Context, tmpl templates.Template) (*templates.Template, error)
+	DeleteTemplate(ctx context.Context, templateID string) error
+}
+
+// TemplateRequest defines the request body for creating or replacing a template.
+// Subject and body use Go text/template syntax, e.g. {{.name}}, filled from the variables of each recipient.
+type TemplateRequest struct {
+	Name string `json:"name" example:"appointment-reminder"`
+	// Optional subject line, used by channels that carry one such as email.
+	Subject string `json:"subject,omitempty" example:"Your appointment on {{.date}}"`
+	Body    string `json:"body" example:"Hi {{.name}}, see you on {{.date}}."`
+}
+
+// TemplateHandler holds the dependencies for the template-related API handlers.
+type TemplateHandler struct {
+	service TemplateServicer
+	logger  *zap.Logger
+}
+
+// NewTemplateHandler creates a new TemplateHandler.
+func NewTemplateHandler(service TemplateServicer, logger *zap.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// createTemplate godoc
+// @Summary      Create a message template
+// @Description  Stores a template that messages can be created from by passing its ID as template_id.
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        template body      TemplateRequest true "Template name, subject and body"
+// @Success      201     {object}   templates.Template "The created template"
+// @Failure      400     {object}   HTTPError "Invalid request body or template"
+// @Failure      409     {object}   HTTPError "Template name already in use"
+// @Failure      500     {object}   HTTPError "Failed to create template"
+// @Security     ApiKeyAuth
+// @Router       /api/v1/templates [post]
+func (h *TemplateHandler) createTemplate(w http.ResponseWriter, r *http.Request) {
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	tmpl, err := h.service.CreateTemplate(r.Context(), templates.Template{Name: req.Name, Subject: req.Subject, Body: req.Body})
+	if err != nil {
+		h.writeError(w, err, "Failed to create template")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusCreated, tmpl)
+}
+
+// listTemplates godoc
+// @Summary      Retrieve a list of message templates
+// @Description  Gets a paginated list of templates ordered by name.
+// @Tags         templates
+// @Produce      json
+// @Param        limit   query      int    false  "Number of templates to return" default(20)
+// @Param        offset  query      int    false  "Offset for pagination" default(0)
+// @Success      200     {array}    templates.Template "A list of templates"
+// @Failure      500     {object}   HTTPError "Failed to retrieve templates"
+// @Security     ApiKeyAuth
+// @Router       /api/v1/templates [get]
+func (h *TemplateHandler) listTemplates(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = defaultOffset
+	}
+
+	tmpls, err := h.service.ListTemplates(r.Context(), int32(limit), int32(offset))
+	if err != nil {
+		h.logger.Error("Failed to list templates", zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve templates", err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, tmpls)
+}
+
+// getTemplate godoc
+// @Summary      Retrieve a message template by ID
+// @Tags         templates
+// @Produce      json
+// @Param        id      path       string true "Template ID"
+// @Success      200     {object}   templates.Template "The requested template"
+// @Failure      400     {object}   HTTPError "Invalid template ID"
+// @Failure      404     {object}   HTTPError "Template not found"
+// @Failure      500     {object}   HTTPError "Failed to retrieve template"
+// @Security     ApiKeyAuth
+// @Router       /api/v1/templates/{id} [get]
+func (h *TemplateHandler) getTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID := r.PathValue("id")
+	if _, err := uuid.Parse(templateID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid template ID", err)
+		return
+	}
+
+	tmpl, err := h.service.GetTemplate(r.Context(), templateID)
+	if err != nil {
+		h.writeError(w, err, "Failed to retrieve template")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, tmpl)
+}
+
+// updateTemplate godoc
+// @Summary      Replace a message template
+// @Description  Replaces the name, subject and body of a template. Messages already created from it are not changed.
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        id      path       string true "Template ID"
+// @Param        template body      TemplateRequest true "Template name, subject and body"
+// @Success      200     {object}   templates.Template "The updated template"
+// @Failure      400     {object}   HTTPError "Invalid template ID, request body or template"
+// @Failure      404     {object}   HTTPError "Template not found"
+// @Failure      409     {object}   HTTPError "Template name already in use"
+// @Failure      500     {object}   HTTPError "Failed to update template"
+// @Security     ApiKeyAuth
+// @Router       /api/v1/templates/{id} [put]
+func (h *TemplateHandler) updateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID := r.PathValue("id")
+	if _, err := uuid.Parse(templateID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid template ID", err)
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	tmpl, err := h.service.UpdateTemplate(r.Context(), templates.Template{ID: templateID, Name: req.Name, Subject: req.Subject, Body: req.Body})
+	if err != nil {
+		h.writeError(w, err, "Failed to update template")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, tmpl)
+}
+
+// deleteTemplate godoc
+// @Summary      Delete a message template
+// @Description  Deletes a template. Messages already created from it are not affected.
+// @Tags         templates
+// @Produce      json
+// @Param        id      path       string true "Template ID"
+// @Success      200     {object}   SuccessResponse "Template deleted"
+// @Failure      400     {object}   HTTPError "Invalid template ID"
+// @Failure      404     {object}   HTTPError "Template not found"
+// @Failure      500     {object}   HTTPError "Failed to delete template"
+// @Security     ApiKeyAuth
+// @Router       /api/v1/templates/{id} [delete]
+func (h *TemplateHandler) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID := r.PathValue("id")
+	if _, err := uuid.Parse(templateID); err != nil {
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid template ID", err)
+		return
+	}
+
+	if err := h.service.DeleteTemplate(r.Context(), templateID); err != nil {
+		h.writeError(w, err, "Failed to delete template")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, SuccessResponse{Message: "Template deleted."})
+}
+
+// writeError maps template errors to their status code, anything else is an internal error.
+func (h *TemplateHandler) writeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, templates.ErrInvalidTemplate):
+		WriteJSONErrorResponse(w, http.StatusBadRequest, "Invalid template", err)
+	case errors.Is(err, templates.ErrTemplateNotFound):
+		WriteJSONErrorResponse(w, http.StatusNotFound, "Template not found", err)
+	case errors.Is(err, templates.ErrNameTaken):
+		WriteJSONErrorResponse(w, http.StatusConflict, "Template name already in use", err)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		WriteJSONErrorResponse(w, http.StatusInternalServerError, message, err)
+	}
+}
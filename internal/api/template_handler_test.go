@@ -0,0 +1,242 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akshaysangma/go-notify/internal/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockTemplateService is a mock of the TemplateServicer Interface.
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) CreateTemplate(ctx context.Context, tmpl templates.Template) (*templates.Template, error) {
+	args := m.Called(ctx, tmpl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*templates.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) GetTemplate(ctx context.Context, templateID string) (*templates.Template, error) {
+	args := m.Called(ctx, templateID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*templates.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) ListTemplates(ctx context.Context, limit, offset int32) ([]templates.Template, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]templates.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) UpdateTemplate(ctx context.Context, tmpl templates.Template) (*templates.Template, error) {
+	args := m.Called(ctx, tmpl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*templates.Template), args.Error(1)
+}
+
+func (m *MockTemplateService) DeleteTemplate(ctx context.Context, templateID string) error {
+	args := m.Called(ctx, templateID)
+	return args.Error(0)
+}
+
+const testTemplateID = "c3d4e5f6-a7b8-9012-3456-7890abcdef12"
+
+func TestTemplateHandler_createTemplate(t *testing.T) {
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, zap.NewNop())
+	input := templates.Template{Name: "reminder", Body: "Hi {{.name}}"}
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "Success", body: `{"name":"reminder","body":"Hi {{.name}}"}`, wantStatus: http.StatusCreated},
+		{name: "Bad Request - Invalid JSON", body: `{not_json}`, wantStatus: http.StatusBadRequest},
+		{name: "Bad Request - Invalid Template", body: `{"name":"reminder","body":"Hi {{.name}}"}`, serviceErr: templates.ErrInvalidTemplate, wantStatus: http.StatusBadRequest},
+		{name: "Conflict - Name Taken", body: `{"name":"reminder","body":"Hi {{.name}}"}`, serviceErr: templates.ErrNameTaken, wantStatus: http.StatusConflict},
+		{name: "Internal Server Error", body: `{"name":"reminder","body":"Hi {{.name}}"}`, serviceErr: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.body != `{not_json}` {
+				var created *templates.Template
+				if tt.serviceErr == nil {
+					created = &templates.Template{ID: testTemplateID, Name: input.Name, Body: input.Body}
+				}
+				mockService.On("CreateTemplate", mock.Anything, input).Return(created, tt.serviceErr).Once()
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/templates", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+
+			handler.createTemplate(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusCreated {
+				var body templates.Template
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+				assert.Equal(t, testTemplateID, body.ID)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_listTemplates(t *testing.T) {
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, zap.NewNop())
+
+	mockService.On("ListTemplates", mock.Anything, int32(defaultLimit), int32(5)).
+		Return([]templates.Template{{ID: testTemplateID, Name: "reminder"}}, nil).Once()
+	rr := httptest.NewRecorder()
+	handler.listTemplates(rr, httptest.NewRequest(http.MethodGet, "/api/v1/templates?limit=1000&offset=5", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var body []templates.Template
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Len(t, body, 1)
+
+	mockService.On("ListTemplates", mock.Anything, int32(defaultLimit), int32(defaultOffset)).Return(nil, errors.New("db error")).Once()
+	rr = httptest.NewRecorder()
+	handler.listTemplates(rr, httptest.NewRequest(http.MethodGet, "/api/v1/templates", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTemplateHandler_getTemplate(t *testing.T) {
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, zap.NewNop())
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/templates/"+id, nil)
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "Success", id: testTemplateID, wantStatus: http.StatusOK},
+		{name: "Bad Request - Invalid ID", id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+		{name: "Not Found", id: testTemplateID, serviceErr: templates.ErrTemplateNotFound, wantStatus: http.StatusNotFound},
+		{name: "Internal Server Error", id: testTemplateID, serviceErr: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantStatus != http.StatusBadRequest {
+				var tmpl *templates.Template
+				if tt.serviceErr == nil {
+					tmpl = &templates.Template{ID: testTemplateID, Name: "reminder"}
+				}
+				mockService.On("GetTemplate", mock.Anything, tt.id).Return(tmpl, tt.serviceErr).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			handler.getTemplate(rr, newRequest(tt.id))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_updateTemplate(t *testing.T) {
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, zap.NewNop())
+	input := templates.Template{ID: testTemplateID, Name: "reminder", Subject: "Hello", Body: "Hi {{.name}}"}
+
+	newRequest := func(id, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/templates/"+id, bytes.NewBufferString(body))
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "Success", id: testTemplateID, wantStatus: http.StatusOK},
+		{name: "Bad Request - Invalid ID", id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+		{name: "Not Found", id: testTemplateID, serviceErr: templates.ErrTemplateNotFound, wantStatus: http.StatusNotFound},
+		{name: "Conflict - Name Taken", id: testTemplateID, serviceErr: templates.ErrNameTaken, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantStatus != http.StatusBadRequest {
+				var updated *templates.Template
+				if tt.serviceErr == nil {
+					updated = &input
+				}
+				mockService.On("UpdateTemplate", mock.Anything, input).Return(updated, tt.serviceErr).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			handler.updateTemplate(rr, newRequest(tt.id, `{"name":"reminder","subject":"Hello","body":"Hi {{.name}}"}`))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTemplateHandler_deleteTemplate(t *testing.T) {
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, zap.NewNop())
+
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/templates/"+id, nil)
+		req.SetPathValue("id", id)
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "Success", id: testTemplateID, wantStatus: http.StatusOK},
+		{name: "Bad Request - Invalid ID", id: "not-a-uuid", wantStatus: http.StatusBadRequest},
+		{name: "Not Found", id: testTemplateID, serviceErr: templates.ErrTemplateNotFound, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantStatus != http.StatusBadRequest {
+				mockService.On("DeleteTemplate", mock.Anything, tt.id).Return(tt.serviceErr).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			handler.deleteTemplate(rr, newRequest(tt.id))
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
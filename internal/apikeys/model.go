@@ -0,0 +1,99 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Domain-specific errors.
+var (
+	ErrKeyNotFound  = fmt.Errorf("API key not found")
+	ErrInvalidKey   = fmt.Errorf("invalid API key")
+	ErrInvalidScope = fmt.Errorf("invalid scope")
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	// ScopeMessagesWrite allows creating and cancelling messages, and managing templates.
+	ScopeMessagesWrite Scope = "messages:write"
+	// ScopeMessagesRead allows reading messages, dead letters and templates.
+	ScopeMessagesRead Scope = "messages:read"
+	// ScopeSchedulerAdmin allows reading the status of the scheduler, starting or stopping it and requeueing dead letters.
+	ScopeSchedulerAdmin Scope = "scheduler:admin"
+)
+
+// Scopes lists every scope a key can be granted.
+var Scopes = []Scope{ScopeMessagesWrite, ScopeMessagesRead, ScopeSchedulerAdmin}
+
+const (
+	// secretPrefix marks the secrets issued by this service, so leaked ones are easy to recognise.
+	secretPrefix = "gn_"
+	// secretBytes is the number of random bytes in a secret.
+	secretBytes = 32
+	// displayPrefixLength is the number of leading characters of a secret kept to recognise the key.
+	displayPrefixLength = len(secretPrefix) + 8
+)
+
+// Key is an API key a client authenticates with. Its secret is only known when it is issued.
+type Key struct {
+	// The unique identifier for the key.
+	ID string `json:"id" example:"d4e5f6a7-b8c9-0123-4567-890abcdef123"`
+	// A name describing the client holding the key.
+	Name string `json:"name" example:"billing-service"`
+	// The first characters of the secret, to recognise the key.
+	Prefix string `json:"prefix" example:"gn_x7Kp2QaZ"`
+	// The permissions of the key.
+	Scopes []Scope `json:"scopes" example:"messages:write,messages:read"`
+	// Whether the key was revoked and can no longer be used.
+	Revoked bool `json:"revoked" example:"false"`
+	// The timestamp when the key was issued.
+	CreatedAt time.Time `json:"created_at" example:"2025-07-09T10:00:00Z"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *Key) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// ParseScopes validates scope names, dropping duplicates. At least one scope is required.
+func ParseScopes(names []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(names))
+	for _, name := range names {
+		scope := Scope(strings.TrimSpace(name))
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, name)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	return scopes, nil
+}
+
+// NewSecret generates the secret of a new key and returns it along with its display prefix.
+func NewSecret() (secret, prefix string, err error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret = secretPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return secret, secret[:displayPrefixLength], nil
+}
+
+// HashSecret returns the hex encoded SHA-256 of a secret, which is what gets stored.
+// Secrets are long and random, so a fast hash is enough to keep them safe if the table leaks.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,40 @@
+package apikeys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"messages:read", " scheduler:admin", "messages:read"})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeMessagesRead, ScopeSchedulerAdmin}, scopes)
+
+	_, err = ParseScopes([]string{"messages:delete"})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	_, err = ParseScopes(nil)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, prefix, err := NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "gn_"))
+	assert.True(t, strings.HasPrefix(secret, prefix))
+	assert.Len(t, prefix, 11)
+
+	other, _, err := NewSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+	assert.NotEqual(t, HashSecret(secret), HashSecret(other))
+	assert.Len(t, HashSecret(secret), 64)
+}
+
+func TestKey_HasScope(t *testing.T) {
+	key := Key{Scopes: []Scope{ScopeMessagesRead}}
+	assert.True(t, key.HasScope(ScopeMessagesRead))
+	assert.False(t, key.HasScope(ScopeMessagesWrite))
+}
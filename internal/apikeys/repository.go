@@ -0,0 +1,19 @@
+package apikeys
+
+import "context"
+
+// Repository defines the contract on Key entities.
+type Repository interface {
+	// Create stores a new key under the hash of its secret and returns it with its timestamp.
+	Create(ctx context.Context, key Key, secretHash string) (*Key, error)
+
+	// GetByHash retrieves the key whose secret has the given hash.
+	// Returns ErrKeyNotFound if there is none or it was revoked.
+	GetByHash(ctx context.Context, secretHash string) (*Key, error)
+
+	// List retrieves every key, revoked ones included, oldest first.
+	List(ctx context.Context) ([]Key, error)
+
+	// Revoke revokes a key. Returns ErrKeyNotFound if it does not exist or was already revoked.
+	Revoke(ctx context.Context, keyID string) error
+}
@@ -0,0 +1,86 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Service issues, revokes and authenticates API keys.
+type Service struct {
+	repo   Repository
+	logger *zap.Logger
+}
+
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// IssueKey creates a key with the given scopes and returns it along with its secret.
+// The secret is not stored and cannot be retrieved again.
+func (s *Service) IssueKey(ctx context.Context, name string, scopeNames []string) (*Key, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", fmt.Errorf("%w: name cannot be empty", ErrInvalidKey)
+	}
+	scopes, err := ParseScopes(scopeNames)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, prefix, err := NewSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := s.repo.Create(ctx, Key{
+		ID:     uuid.New().String(),
+		Name:   name,
+		Prefix: prefix,
+		Scopes: scopes,
+	}, HashSecret(secret))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to issue API key %q: %w", name, err)
+	}
+	s.logger.Info("Issued API key", zap.String("key_id", key.ID), zap.String("name", key.Name), zap.Any("scopes", key.Scopes))
+	return key, secret, nil
+}
+
+// Authenticate returns the key a secret belongs to.
+// Returns ErrInvalidKey if the secret is unknown or its key was revoked.
+func (s *Service) Authenticate(ctx context.Context, secret string) (*Key, error) {
+	key, err := s.repo.GetByHash(ctx, HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListKeys returns every key, revoked ones included, oldest first.
+func (s *Service) ListKeys(ctx context.Context) ([]Key, error) {
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	if keys == nil {
+		return []Key{}, nil
+	}
+	return keys, nil
+}
+
+// RevokeKey revokes a key. Requests made with it are rejected from then on.
+func (s *Service) RevokeKey(ctx context.Context, keyID string) error {
+	if err := s.repo.Revoke(ctx, keyID); err != nil {
+		return fmt.Errorf("failed to revoke API key %s: %w", keyID, err)
+	}
+	s.logger.Info("Revoked API key", zap.String("key_id", keyID))
+	return nil
+}
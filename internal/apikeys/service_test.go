@@ -0,0 +1,92 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockRepository is a mock of the Repository interface.
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, key Key, secretHash string) (*Key, error) {
+	args := m.Called(ctx, key, secretHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Key), args.Error(1)
+}
+
+func (m *MockRepository) GetByHash(ctx context.Context, secretHash string) (*Key, error) {
+	args := m.Called(ctx, secretHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Key), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context) ([]Key, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]Key), args.Error(1)
+}
+
+func (m *MockRepository) Revoke(ctx context.Context, keyID string) error {
+	return m.Called(ctx, keyID).Error(0)
+}
+
+func TestService_IssueAndAuthenticate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, zap.NewNop())
+	ctx := context.Background()
+
+	var storedHash string
+	var stored Key
+	mockRepo.On("Create", ctx, mock.AnythingOfType("apikeys.Key"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(1).(Key)
+			storedHash = args.String(2)
+		}).
+		Return(&stored, nil).Once()
+
+	key, secret, err := service.IssueKey(ctx, "billing", []string{"messages:write"})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{ScopeMessagesWrite}, key.Scopes)
+	// Only the hash of the secret is stored.
+	assert.Equal(t, HashSecret(secret), storedHash)
+	assert.NotContains(t, storedHash, secret)
+	assert.Equal(t, secret[:len(stored.Prefix)], stored.Prefix)
+
+	mockRepo.On("GetByHash", ctx, storedHash).Return(&stored, nil).Once()
+	authenticated, err := service.Authenticate(ctx, secret)
+	require.NoError(t, err)
+	assert.Equal(t, stored.ID, authenticated.ID)
+
+	mockRepo.On("GetByHash", ctx, HashSecret("gn_unknown")).Return(nil, ErrKeyNotFound).Once()
+	_, err = service.Authenticate(ctx, "gn_unknown")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	dbErr := errors.New("connection refused")
+	mockRepo.On("GetByHash", ctx, HashSecret("gn_later")).Return(nil, dbErr).Once()
+	_, err = service.Authenticate(ctx, "gn_later")
+	assert.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, ErrInvalidKey)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_IssueKey_Invalid(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, zap.NewNop())
+
+	_, _, err := service.IssueKey(context.Background(), " ", []string{"messages:read"})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, _, err = service.IssueKey(context.Background(), "billing", []string{"admin"})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	mockRepo.AssertNotCalled(t, "Create")
+}
@@ -0,0 +1,186 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State string
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = "closed"
+	// StateOpen rejects every call until the cool-down has passed.
+	StateOpen State = "open"
+	// StateHalfOpen lets a few trial calls through to find out whether the dependency recovered.
+	StateHalfOpen State = "half-open"
+)
+
+// ErrOpen is matched by the errors returned while the breaker rejects calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// OpenError is returned by Allow while the breaker rejects calls.
+// It implements messages.ClassifiedError, asking to retry once the breaker may let calls through again.
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s circuit breaker is open", e.Name)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// Retryable reports that the call may succeed once the breaker closes.
+func (e *OpenError) Retryable() bool {
+	return true
+}
+
+// RetryDelay returns how long until the breaker lets trial calls through.
+func (e *OpenError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// Stats is a snapshot of a circuit breaker.
+type Stats struct {
+	// The name of the breaker.
+	Name string `json:"name" example:"sms"`
+	// The current state: closed, open or half-open.
+	State State `json:"state" example:"closed"`
+	// The number of failures since the last success.
+	ConsecutiveFailures int `json:"consecutive_failures" example:"0"`
+	// The number of times the breaker opened since startup.
+	Opens int64 `json:"opens" example:"2"`
+	// The timestamp the breaker last opened, if it ever did.
+	OpenedAt *time.Time `json:"opened_at,omitempty" example:"2025-07-09T10:01:00Z"`
+}
+
+// Breaker is a circuit breaker. It opens after failureThreshold consecutive failures and
+// rejects calls for the cool-down, then lets halfOpenRequests trial calls through: the breaker
+// closes once they all succeed and opens again on the first failure.
+//
+// Callers ask Allow before each call and report its outcome with exactly one of
+// Success, Failure or Abort.
+type Breaker struct {
+	mu                  sync.Mutex
+	name                string
+	failureThreshold    int
+	cooldown            time.Duration
+	halfOpenRequests    int
+	state               State
+	consecutiveFailures int
+	halfOpenInFlight    int
+	halfOpenSuccesses   int
+	opens               int64
+	openedAt            time.Time
+	now                 func() time.Time
+}
+
+func New(name string, failureThreshold int, cooldown time.Duration, halfOpenRequests int) *Breaker {
+	return &Breaker{
+		name:             name,
+		failureThreshold: max(failureThreshold, 1),
+		cooldown:         cooldown,
+		halfOpenRequests: max(halfOpenRequests, 1),
+		state:            StateClosed,
+		now:              time.Now,
+	}
+}
+
+// Allow reports whether a call may go through, returning an *OpenError if not.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen {
+		elapsed := b.now().Sub(b.openedAt)
+		if elapsed < b.cooldown {
+			return &OpenError{Name: b.name, RetryAfter: b.cooldown - elapsed}
+		}
+		b.state = StateHalfOpen
+		b.halfOpenInFlight = 0
+		b.halfOpenSuccesses = 0
+	}
+	if b.state == StateHalfOpen {
+		if b.halfOpenInFlight+b.halfOpenSuccesses >= b.halfOpenRequests {
+			// The trial calls are still running; retry as soon as they are done.
+			return &OpenError{Name: b.name}
+		}
+		b.halfOpenInFlight++
+	}
+	return nil
+}
+
+// Success reports that an allowed call succeeded.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures = 0
+	if b.state != StateHalfOpen {
+		return
+	}
+	b.halfOpenInFlight = max(b.halfOpenInFlight-1, 0)
+	b.halfOpenSuccesses++
+	if b.halfOpenSuccesses >= b.halfOpenRequests {
+		b.state = StateClosed
+	}
+}
+
+// Failure reports that an allowed call failed.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures++
+	switch b.state {
+	case StateHalfOpen:
+		b.open()
+	case StateClosed:
+		if b.consecutiveFailures >= b.failureThreshold {
+			b.open()
+		}
+	}
+}
+
+// Abort reports that an allowed call ended without telling whether the dependency is healthy,
+// for instance because the caller gave up on it.
+func (b *Breaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.halfOpenInFlight = max(b.halfOpenInFlight-1, 0)
+	}
+}
+
+// Stats returns a snapshot of the breaker.
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := Stats{
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		Opens:               b.opens,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt.UTC()
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+// open must be called with b.mu held.
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.opens++
+}
@@ -0,0 +1,105 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBreaker returns a breaker whose clock is moved by advancing the returned time.
+func newTestBreaker(failureThreshold int, cooldown time.Duration, halfOpenRequests int) (*Breaker, *time.Time) {
+	now := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	b := New("sms", failureThreshold, cooldown, halfOpenRequests)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute, 1)
+
+	for range 2 {
+		require.NoError(t, b.Allow())
+		b.Failure()
+	}
+	// A success resets the count.
+	require.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, StateClosed, b.Stats().State)
+
+	for range 3 {
+		require.NoError(t, b.Allow())
+		b.Failure()
+	}
+	stats := b.Stats()
+	assert.Equal(t, StateOpen, stats.State)
+	assert.Equal(t, 3, stats.ConsecutiveFailures)
+	assert.Equal(t, int64(1), stats.Opens)
+	assert.NotNil(t, stats.OpenedAt)
+
+	err := b.Allow()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOpen))
+	var openErr *OpenError
+	require.True(t, errors.As(err, &openErr))
+	assert.Equal(t, time.Minute, openErr.RetryDelay())
+	assert.True(t, openErr.Retryable())
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	t.Run("Closes After Successful Trials", func(t *testing.T) {
+		b, now := newTestBreaker(1, time.Minute, 2)
+		require.NoError(t, b.Allow())
+		b.Failure()
+
+		*now = now.Add(40 * time.Second)
+		var openErr *OpenError
+		require.True(t, errors.As(b.Allow(), &openErr))
+		assert.Equal(t, 20*time.Second, openErr.RetryAfter)
+
+		*now = now.Add(20 * time.Second)
+		require.NoError(t, b.Allow())
+		require.NoError(t, b.Allow())
+		assert.Equal(t, StateHalfOpen, b.Stats().State)
+		// Only halfOpenRequests trial calls go through.
+		require.True(t, errors.As(b.Allow(), &openErr))
+		assert.Zero(t, openErr.RetryAfter)
+
+		b.Success()
+		assert.Equal(t, StateHalfOpen, b.Stats().State)
+		b.Success()
+		assert.Equal(t, StateClosed, b.Stats().State)
+		assert.NoError(t, b.Allow())
+	})
+
+	t.Run("Reopens On Failed Trial", func(t *testing.T) {
+		b, now := newTestBreaker(1, time.Minute, 1)
+		require.NoError(t, b.Allow())
+		b.Failure()
+
+		*now = now.Add(time.Minute)
+		require.NoError(t, b.Allow())
+		b.Failure()
+
+		stats := b.Stats()
+		assert.Equal(t, StateOpen, stats.State)
+		assert.Equal(t, int64(2), stats.Opens)
+		assert.Equal(t, *now, *stats.OpenedAt)
+		assert.Error(t, b.Allow())
+	})
+
+	t.Run("Aborted Trial Frees Its Slot", func(t *testing.T) {
+		b, now := newTestBreaker(1, time.Minute, 1)
+		require.NoError(t, b.Allow())
+		b.Failure()
+
+		*now = now.Add(time.Minute)
+		require.NoError(t, b.Allow())
+		assert.Error(t, b.Allow())
+		b.Abort()
+		assert.NoError(t, b.Allow())
+		assert.Equal(t, StateHalfOpen, b.Stats().State)
+	})
+}
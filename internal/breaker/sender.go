@@ -0,0 +1,96 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+)
+
+// Sender wraps a messages.Sender with a circuit breaker. While the breaker is open, sends fail
+// straight away with an error wrapping messages.ErrSenderUnavailable, so messages are left
+// 'pending' instead of burning their attempts against a provider that is down.
+//
+// Only retryable errors count as failures: a provider that rejects a message is still up.
+// Sender also implements messages.SubjectSender and messages.ProviderSender, falling back to
+// Send when the wrapped sender does not.
+type Sender struct {
+	sender  messages.Sender
+	breaker *Breaker
+}
+
+func NewSender(sender messages.Sender, breaker *Breaker) *Sender {
+	return &Sender{
+		sender:  sender,
+		breaker: breaker,
+	}
+}
+
+// Send sends the content through the wrapped sender unless the breaker is open.
+func (s *Sender) Send(ctx context.Context, to, content string) (string, error) {
+	if err := s.allow(); err != nil {
+		return "", err
+	}
+	externalID, err := s.sender.Send(ctx, to, content)
+	s.record(ctx, err)
+	return externalID, err
+}
+
+// SendWithSubject sends the subject and content through the wrapped sender unless the breaker is open.
+// Wrapped senders without subjects get the content through SendWithProvider, so the provider is still reported.
+func (s *Sender) SendWithSubject(ctx context.Context, to, subject, content string) (string, string, error) {
+	subjectSender, ok := s.sender.(messages.SubjectSender)
+	if !ok {
+		return s.SendWithProvider(ctx, to, content)
+	}
+	if err := s.allow(); err != nil {
+		return "", "", err
+	}
+	externalID, provider, err := subjectSender.SendWithSubject(ctx, to, subject, content)
+	s.record(ctx, err)
+	return externalID, provider, err
+}
+
+// SendWithProvider sends the content through the wrapped sender unless the breaker is open.
+func (s *Sender) SendWithProvider(ctx context.Context, to, content string) (string, string, error) {
+	providerSender, ok := s.sender.(messages.ProviderSender)
+	if !ok {
+		externalID, err := s.Send(ctx, to, content)
+		return externalID, "", err
+	}
+	if err := s.allow(); err != nil {
+		return "", "", err
+	}
+	externalID, provider, err := providerSender.SendWithProvider(ctx, to, content)
+	s.record(ctx, err)
+	return externalID, provider, err
+}
+
+// Stats returns a snapshot of the breaker.
+func (s *Sender) Stats() Stats {
+	return s.breaker.Stats()
+}
+
+func (s *Sender) allow() error {
+	if err := s.breaker.Allow(); err != nil {
+		return fmt.Errorf("%w: %w", messages.ErrSenderUnavailable, err)
+	}
+	return nil
+}
+
+// record reports the outcome of a send to the breaker. Sends cancelled by the caller or refused
+// by the wrapped sender say nothing about the provider, unlike sends that ran out of time.
+func (s *Sender) record(ctx context.Context, err error) {
+	var classified messages.ClassifiedError
+	switch {
+	case err == nil:
+		s.breaker.Success()
+	case errors.Is(ctx.Err(), context.Canceled), errors.Is(err, messages.ErrSenderUnavailable):
+		s.breaker.Abort()
+	case errors.As(err, &classified) && classified.Retryable():
+		s.breaker.Failure()
+	default:
+		s.breaker.Success()
+	}
+}
@@ -0,0 +1,150 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSender is a mock of the messages.Sender interface.
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, to, content string) (string, error) {
+	args := m.Called(ctx, to, content)
+	return args.String(0), args.Error(1)
+}
+
+// MockProviderSender is a mock of a messages.Sender that also implements messages.ProviderSender.
+type MockProviderSender struct {
+	MockSender
+}
+
+func (m *MockProviderSender) SendWithProvider(ctx context.Context, to, content string) (string, string, error) {
+	args := m.Called(ctx, to, content)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+// classifiedError is a test implementation of messages.ClassifiedError.
+type classifiedError struct {
+	retryable bool
+}
+
+func (e classifiedError) Error() string             { return "classified error" }
+func (e classifiedError) Retryable() bool           { return e.retryable }
+func (e classifiedError) RetryDelay() time.Duration { return 0 }
+
+func TestSender_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Open After Retryable Failures", func(t *testing.T) {
+		mockSender := new(MockSender)
+		sender := NewSender(mockSender, New("sms", 2, time.Minute, 1))
+		mockSender.On("Send", mock.Anything, "+15551234567", "hello").Return("", classifiedError{retryable: true}).Twice()
+
+		for range 2 {
+			_, err := sender.Send(ctx, "+15551234567", "hello")
+			assert.False(t, errors.Is(err, messages.ErrSenderUnavailable))
+		}
+
+		_, err := sender.Send(ctx, "+15551234567", "hello")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, messages.ErrSenderUnavailable))
+		assert.True(t, errors.Is(err, ErrOpen))
+		var classified messages.ClassifiedError
+		require.True(t, errors.As(err, &classified))
+		assert.InDelta(t, time.Minute, classified.RetryDelay(), float64(time.Second))
+		assert.Equal(t, StateOpen, sender.Stats().State)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Permanent Failures Keep It Closed", func(t *testing.T) {
+		mockSender := new(MockSender)
+		sender := NewSender(mockSender, New("sms", 1, time.Minute, 1))
+		mockSender.On("Send", mock.Anything, "+15551234567", "hello").Return("", classifiedError{retryable: false}).Once()
+		mockSender.On("Send", mock.Anything, "", "hello").Return("", messages.ErrRecipientEmpty).Once()
+
+		_, err := sender.Send(ctx, "+15551234567", "hello")
+		assert.Error(t, err)
+		_, err = sender.Send(ctx, "", "hello")
+		assert.ErrorIs(t, err, messages.ErrRecipientEmpty)
+		assert.Equal(t, StateClosed, sender.Stats().State)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Cancelled Sends Are Not Failures", func(t *testing.T) {
+		mockSender := new(MockSender)
+		sender := NewSender(mockSender, New("sms", 1, time.Minute, 1))
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		mockSender.On("Send", mock.Anything, "+15551234567", "hello").Return("", classifiedError{retryable: true}).Once()
+
+		_, err := sender.Send(cancelledCtx, "+15551234567", "hello")
+		assert.Error(t, err)
+		assert.Equal(t, StateClosed, sender.Stats().State)
+	})
+}
+
+func TestSender_SendWithProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Delegates To ProviderSender", func(t *testing.T) {
+		mockSender := new(MockProviderSender)
+		sender := NewSender(mockSender, New("sms", 1, time.Minute, 1))
+		mockSender.On("SendWithProvider", mock.Anything, "+15551234567", "hello").Return("ext-1", "backup", nil).Once()
+
+		externalID, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "ext-1", externalID)
+		assert.Equal(t, "backup", provider)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Falls Back To Send", func(t *testing.T) {
+		mockSender := new(MockSender)
+		sender := NewSender(mockSender, New("sms", 1, time.Minute, 1))
+		mockSender.On("Send", mock.Anything, "+15551234567", "hello").Return("ext-1", nil).Once()
+
+		externalID, provider, err := sender.SendWithProvider(ctx, "+15551234567", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "ext-1", externalID)
+		assert.Empty(t, provider)
+		mockSender.AssertExpectations(t)
+	})
+}
+
+func TestSender_SendWithSubject(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Provider Reported Without Subject Support", func(t *testing.T) {
+		// An sms template with a subject must still record the provider that accepted the message.
+		mockSender := new(MockProviderSender)
+		sender := NewSender(mockSender, New("sms", 1, time.Minute, 1))
+		mockSender.On("SendWithProvider", mock.Anything, "+15551234567", "hello").Return("ext-1", "backup", nil).Once()
+
+		externalID, provider, err := sender.SendWithSubject(ctx, "+15551234567", "Reminder", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "ext-1", externalID)
+		assert.Equal(t, "backup", provider)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Open Breaker", func(t *testing.T) {
+		mockSender := new(MockProviderSender)
+		sender := NewSender(mockSender, New("sms", 1, time.Minute, 1))
+		mockSender.On("SendWithProvider", mock.Anything, "+15551234567", "hello").Return("", "primary", classifiedError{retryable: true}).Once()
+
+		_, _, err := sender.SendWithSubject(ctx, "+15551234567", "Reminder", "hello")
+		require.Error(t, err)
+		_, _, err = sender.SendWithSubject(ctx, "+15551234567", "Reminder", "hello")
+		assert.ErrorIs(t, err, messages.ErrSenderUnavailable)
+		mockSender.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,47 @@
+package callbacks
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrLeaseLost is returned when the outcome of a post is recorded after another instance claimed the delivery.
+var ErrLeaseLost = fmt.Errorf("delivery is no longer claimed by this instance")
+
+// Event is the JSON body posted to the callback URL of a message once it reaches a terminal status.
+type Event struct {
+	// The unique identifier of the event, the same across redeliveries so receivers can drop duplicates.
+	ID string `json:"id"`
+	// The ID of the message the event is about.
+	MessageID string `json:"message_id"`
+	// The status the message reached: sent or failed, then delivered or undelivered once the provider reports it,
+	// or cancelled if it was cancelled before being sent.
+	Status string `json:"status"`
+	// The ID returned by the provider of the channel, if it returned one.
+	ExternalMessageID *string `json:"external_message_id,omitempty"`
+	// Why the message failed, for failed and undelivered messages.
+	FailureReason *string `json:"failure_reason,omitempty"`
+	// The time the message reached its status.
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Delivery is an Event queued for posting to a callback URL.
+type Delivery struct {
+	// The unique identifier of the delivery, also used as the event ID.
+	ID string
+	// The ID of the message the event is about.
+	MessageID string
+	// The callback URL the event is posted to.
+	URL string
+	// The JSON encoded Event.
+	Payload []byte
+	// The number of posts made so far, including the one in progress.
+	AttemptCount int
+	// The number of posts allowed before the delivery is given up.
+	MaxAttempts int
+}
+
+// CanRetry reports whether the delivery has attempts left.
+func (d *Delivery) CanRetry() bool {
+	return d.AttemptCount < d.MaxAttempts
+}
@@ -0,0 +1,32 @@
+package callbacks
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the contract on callback Deliveries.
+type Repository interface {
+	// Enqueue stores a new delivery, due right away.
+	Enqueue(ctx context.Context, delivery Delivery) error
+
+	// ClaimDue atomically moves up to limit due deliveries to 'delivering' for the lease duration and counts
+	// an attempt on each. Deliveries whose lease expired, e.g. because their instance crashed, are claimed again.
+	ClaimDue(ctx context.Context, instanceID string, lease time.Duration, limit int32) ([]Delivery, error)
+
+	// MarkDelivered records that the callback URL accepted the delivery.
+	// Outcomes are only recorded while instanceID holds the claim, otherwise ErrLeaseLost is returned.
+	MarkDelivered(ctx context.Context, deliveryID, instanceID string) error
+
+	// ScheduleRetry returns a delivery to 'pending' until nextAttemptAt, recording why the post failed.
+	// Returns ErrLeaseLost if instanceID no longer holds the claim.
+	ScheduleRetry(ctx context.Context, deliveryID, instanceID string, nextAttemptAt time.Time, lastError string) error
+
+	// MarkFailed gives up on a delivery, recording why the last post failed.
+	// Returns ErrLeaseLost if instanceID no longer holds the claim.
+	MarkFailed(ctx context.Context, deliveryID, instanceID string, lastError string) error
+
+	// DeleteFinished deletes delivered and failed deliveries last updated before finishedBefore
+	// and returns how many were deleted.
+	DeleteFinished(ctx context.Context, finishedBefore time.Time) (int64, error)
+}
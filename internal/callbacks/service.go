@@ -0,0 +1,178 @@
+package callbacks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Poster posts a JSON payload to a callback URL.
+type Poster interface {
+	// Post returns an error if the callback URL did not accept the payload. Errors implementing
+	// messages.ClassifiedError tell whether posting again may succeed; others are assumed to be transient.
+	Post(ctx context.Context, url string, payload []byte) error
+}
+
+// Service queues status events for the callback URLs of messages and delivers them with retries.
+// It implements messages.StatusNotifier.
+type Service struct {
+	repo        Repository
+	poster      Poster
+	logger      *zap.Logger
+	instanceID  string
+	lease       time.Duration
+	retryPolicy messages.RetryPolicy
+}
+
+func NewService(repo Repository,
+	poster Poster,
+	logger *zap.Logger,
+	instanceID string,
+	lease time.Duration,
+	retryPolicy messages.RetryPolicy) *Service {
+
+	return &Service{
+		repo:        repo,
+		poster:      poster,
+		logger:      logger,
+		instanceID:  instanceID,
+		lease:       lease,
+		retryPolicy: retryPolicy,
+	}
+}
+
+// NotifyStatus queues an event with the current status of msg for delivery to its callback URL.
+func (s *Service) NotifyStatus(ctx context.Context, msg messages.Message) error {
+	delivery, err := NewDelivery(msg, s.retryPolicy.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Enqueue(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to queue callback for message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// NewDelivery returns a delivery of an event with the current status of msg to its callback URL,
+// allowed up to maxAttempts posts. Repositories use it to queue events in the transaction that changes the status.
+func NewDelivery(msg messages.Message, maxAttempts int) (Delivery, error) {
+	event := Event{
+		ID:                uuid.New().String(),
+		MessageID:         msg.ID,
+		Status:            msg.Status,
+		ExternalMessageID: msg.ExternalMessageID,
+		OccurredAt:        time.Now().UTC(),
+	}
+	if msg.Status == "failed" || msg.Status == messages.ReceiptUndelivered {
+		event.FailureReason = msg.LastFailureReason
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("failed to marshal callback event: %w", err)
+	}
+
+	return Delivery{
+		ID:          event.ID,
+		MessageID:   msg.ID,
+		URL:         msg.CallbackURL,
+		Payload:     payload,
+		MaxAttempts: max(maxAttempts, 1),
+	}, nil
+}
+
+// DeliverDue claims up to limit due deliveries and posts them concurrently.
+// Failed posts are retried with backoff until the delivery runs out of attempts. Returns the number of claimed deliveries.
+func (s *Service) DeliverDue(ctx context.Context, limit int) (int, error) {
+	deliveries, err := s.repo.ClaimDue(ctx, s.instanceID, s.lease, int32(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim callback deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// deliver posts a claimed delivery and records the outcome.
+func (s *Service) deliver(ctx context.Context, delivery Delivery) {
+	logFields := []zap.Field{
+		zap.String("delivery_id", delivery.ID),
+		zap.String("message_id", delivery.MessageID),
+		zap.Int("attempt", delivery.AttemptCount),
+	}
+
+	postErr := s.poster.Post(ctx, delivery.URL, delivery.Payload)
+	if postErr == nil {
+		if err := s.repo.MarkDelivered(ctx, delivery.ID, s.instanceID); err != nil {
+			if errors.Is(err, ErrLeaseLost) {
+				s.logLeaseLost(logFields)
+				return
+			}
+			// The delivery is posted again once its lease expires.
+			s.logger.Error("Failed to mark callback as delivered", append(logFields, zap.Error(err))...)
+			return
+		}
+		s.logger.Info("Delivered status callback", logFields...)
+		return
+	}
+
+	retryable, retryDelay := true, time.Duration(0)
+	var classified messages.ClassifiedError
+	if errors.As(postErr, &classified) {
+		retryable, retryDelay = classified.Retryable(), classified.RetryDelay()
+	}
+	logFields = append(logFields, zap.Bool("retryable", retryable), zap.Error(postErr))
+
+	if retryable && delivery.CanRetry() {
+		nextAttemptAt := time.Now().UTC().Add(max(s.retryPolicy.Backoff(delivery.AttemptCount), retryDelay))
+		if err := s.repo.ScheduleRetry(ctx, delivery.ID, s.instanceID, nextAttemptAt, postErr.Error()); err != nil {
+			if errors.Is(err, ErrLeaseLost) {
+				s.logLeaseLost(logFields)
+				return
+			}
+			s.logger.Error("Failed to schedule callback retry", append(logFields, zap.NamedError("update_error", err))...)
+			return
+		}
+		s.logger.Warn("Status callback failed, scheduled retry", append(logFields, zap.Time("next_attempt_at", nextAttemptAt))...)
+		return
+	}
+
+	if err := s.repo.MarkFailed(ctx, delivery.ID, s.instanceID, postErr.Error()); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			s.logLeaseLost(logFields)
+			return
+		}
+		s.logger.Error("Failed to mark callback as failed", append(logFields, zap.NamedError("update_error", err))...)
+		return
+	}
+	s.logger.Warn("Status callback failed for good", logFields...)
+}
+
+// logLeaseLost logs a post whose outcome was not stored because another instance claimed the delivery meanwhile.
+func (s *Service) logLeaseLost(logFields []zap.Field) {
+	s.logger.Warn("Callback lease expired during post, outcome not stored", append(logFields, zap.String("instance_id", s.instanceID))...)
+}
+
+// DeleteFinished deletes delivered and failed deliveries last updated more than retention ago.
+// Returns the number of deleted deliveries.
+func (s *Service) DeleteFinished(ctx context.Context, retention time.Duration) (int64, error) {
+	deleted, err := s.repo.DeleteFinished(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished callback deliveries: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,192 @@
+package callbacks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockRepository is a mock of the Repository interface.
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Enqueue(ctx context.Context, delivery Delivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimDue(ctx context.Context, instanceID string, lease time.Duration, limit int32) ([]Delivery, error) {
+	args := m.Called(ctx, instanceID, lease, limit)
+	return args.Get(0).([]Delivery), args.Error(1)
+}
+
+func (m *MockRepository) MarkDelivered(ctx context.Context, deliveryID, instanceID string) error {
+	args := m.Called(ctx, deliveryID, instanceID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ScheduleRetry(ctx context.Context, deliveryID, instanceID string, nextAttemptAt time.Time, lastError string) error {
+	args := m.Called(ctx, deliveryID, instanceID, nextAttemptAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkFailed(ctx context.Context, deliveryID, instanceID string, lastError string) error {
+	args := m.Called(ctx, deliveryID, instanceID, lastError)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteFinished(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, finishedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockPoster is a mock of the Poster interface.
+type MockPoster struct {
+	mock.Mock
+}
+
+func (m *MockPoster) Post(ctx context.Context, url string, payload []byte) error {
+	args := m.Called(ctx, url, string(payload))
+	return args.Error(0)
+}
+
+// permanentError is a post error that must not be retried.
+type permanentError struct{}
+
+func (permanentError) Error() string             { return "status 410" }
+func (permanentError) Retryable() bool           { return false }
+func (permanentError) RetryDelay() time.Duration { return 0 }
+
+var testRetryPolicy = messages.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+func TestService_NotifyStatus(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, new(MockPoster), zap.NewNop(), "instance-a", time.Minute, testRetryPolicy)
+	externalID, reason := "ext-1", "status 400"
+
+	var queued []Delivery
+	mockRepo.On("Enqueue", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		queued = append(queued, args.Get(1).(Delivery))
+	}).Return(nil)
+
+	require.NoError(t, service.NotifyStatus(context.Background(), messages.Message{
+		ID: "msg-1", Status: "sent", ExternalMessageID: &externalID, LastFailureReason: &reason, CallbackURL: "https://example.com/hooks",
+	}))
+	require.NoError(t, service.NotifyStatus(context.Background(), messages.Message{
+		ID: "msg-2", Status: "failed", LastFailureReason: &reason, CallbackURL: "https://example.com/hooks",
+	}))
+	require.Len(t, queued, 2)
+
+	var sent, failed Event
+	require.NoError(t, json.Unmarshal(queued[0].Payload, &sent))
+	require.NoError(t, json.Unmarshal(queued[1].Payload, &failed))
+	assert.Equal(t, queued[0].ID, sent.ID)
+	assert.Equal(t, "https://example.com/hooks", queued[0].URL)
+	assert.Equal(t, 3, queued[0].MaxAttempts)
+	assert.Equal(t, "sent", sent.Status)
+	assert.Equal(t, &externalID, sent.ExternalMessageID)
+	// A reason left over from an earlier failed attempt is not reported for a sent message.
+	assert.Nil(t, sent.FailureReason)
+	assert.Equal(t, "msg-2", failed.MessageID)
+	assert.Equal(t, "failed", failed.Status)
+	assert.Nil(t, failed.ExternalMessageID)
+	assert.Equal(t, &reason, failed.FailureReason)
+
+	mockRepo.ExpectedCalls = nil
+	mockRepo.On("Enqueue", mock.Anything, mock.Anything).Return(errors.New("db down"))
+	assert.Error(t, service.NotifyStatus(context.Background(), messages.Message{ID: "msg-3", Status: "sent", CallbackURL: "https://example.com/hooks"}))
+}
+
+func TestService_DeliverDue(t *testing.T) {
+	url := "https://example.com/hooks"
+	tests := []struct {
+		name    string
+		attempt int
+		postErr error
+		expect  func(repo *MockRepository)
+	}{
+		{
+			name:    "Delivered",
+			attempt: 1,
+			expect: func(repo *MockRepository) {
+				repo.On("MarkDelivered", mock.Anything, "d-1", "instance-a").Return(nil).Once()
+			},
+		},
+		{
+			name:    "Transient Failure Is Retried",
+			attempt: 1,
+			postErr: errors.New("connection refused"),
+			expect: func(repo *MockRepository) {
+				repo.On("ScheduleRetry", mock.Anything, "d-1", "instance-a", mock.MatchedBy(func(at time.Time) bool {
+					return time.Until(at) > 50*time.Second && time.Until(at) <= time.Minute
+				}), "connection refused").Return(nil).Once()
+			},
+		},
+		{
+			name:    "Last Attempt",
+			attempt: 3,
+			postErr: errors.New("connection refused"),
+			expect: func(repo *MockRepository) {
+				repo.On("MarkFailed", mock.Anything, "d-1", "instance-a", "connection refused").Return(nil).Once()
+			},
+		},
+		{
+			name:    "Permanent Failure",
+			attempt: 1,
+			postErr: permanentError{},
+			expect: func(repo *MockRepository) {
+				repo.On("MarkFailed", mock.Anything, "d-1", "instance-a", "status 410").Return(nil).Once()
+			},
+		},
+		{
+			name:    "Lease Lost - Outcome Not Stored",
+			attempt: 1,
+			postErr: errors.New("connection refused"),
+			expect: func(repo *MockRepository) {
+				// Another instance claimed the delivery while the post was running, so only the attempt to record is made.
+				repo.On("ScheduleRetry", mock.Anything, "d-1", "instance-a", mock.Anything, "connection refused").Return(ErrLeaseLost).Once()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, mockPoster := new(MockRepository), new(MockPoster)
+			service := NewService(mockRepo, mockPoster, zap.NewNop(), "instance-a", time.Minute, testRetryPolicy)
+			delivery := Delivery{ID: "d-1", MessageID: "msg-1", URL: url, Payload: []byte(`{}`), AttemptCount: tt.attempt, MaxAttempts: 3}
+			mockRepo.On("ClaimDue", mock.Anything, "instance-a", time.Minute, int32(10)).Return([]Delivery{delivery}, nil).Once()
+			mockPoster.On("Post", mock.Anything, url, "{}").Return(tt.postErr).Once()
+			tt.expect(mockRepo)
+
+			claimed, err := service.DeliverDue(context.Background(), 10)
+			require.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+			mockRepo.AssertExpectations(t)
+			mockPoster.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_DeleteFinished(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, new(MockPoster), zap.NewNop(), "instance-a", time.Minute, testRetryPolicy)
+	mockRepo.On("DeleteFinished", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= 24*time.Hour && time.Since(before) < 24*time.Hour+time.Minute
+	})).Return(int64(4), nil).Once()
+
+	deleted, err := service.DeleteFinished(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+
+	mockRepo.On("DeleteFinished", mock.Anything, mock.Anything).Return(int64(0), errors.New("db down")).Once()
+	_, err = service.DeleteFinished(context.Background(), 24*time.Hour)
+	assert.Error(t, err)
+}
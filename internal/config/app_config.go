@@ -4,21 +4,29 @@ import "time"
 
 // AppConfig holds the entire application configuration.
 type AppConfig struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Redis     RedisConfig     `mapstructure:"redis"`
-	Webhook   WebhookConfig   `mapstructure:"webhook"`
-	Scheduler SchedulerConfig `mapstructure:"scheduler"`
-	App       AppEnvConfig    `mapstructure:"app"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Webhook    WebhookConfig    `mapstructure:"webhook"`
+	Chat       ChatConfig       `mapstructure:"chat"`
+	SMTP       SMTPConfig       `mapstructure:"smtp"`
+	Scheduler  SchedulerConfig  `mapstructure:"scheduler"`
+	Recipients RecipientsConfig `mapstructure:"recipients"`
+	Callbacks  CallbacksConfig  `mapstructure:"callbacks"`
+	App        AppEnvConfig     `mapstructure:"app"`
 }
 
 // ServerConfig holds HTTP server configuration.
+// Client APIs require an API key unless authentication is disabled.
 type ServerConfig struct {
-	Port         int           `mapstructure:"port"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
-	GracePeriod  time.Duration `mapstructure:"grace_period"`
+	Port                   int           `mapstructure:"port"`
+	ReadTimeout            time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout           time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout            time.Duration `mapstructure:"idle_timeout"`
+	GracePeriod            time.Duration `mapstructure:"grace_period"`
+	IdempotencyWindow      time.Duration `mapstructure:"idempotency_window"`
+	IdempotencyLockTimeout time.Duration `mapstructure:"idempotency_lock_timeout"`
+	DisableAuth            bool          `mapstructure:"disable_auth"`
 }
 
 // DatabaseConfig holds PostgreSQL database configuration.
@@ -33,17 +41,114 @@ type RedisConfig struct {
 }
 
 // WebhookConfig holds webhook.site configuration.
+// A single URL, with its receipt secrets, is used as the only provider when no providers are listed.
+// Requests to every provider are signed when a signing secret is set; the previous secret is also
+// used while rotating it.
 type WebhookConfig struct {
-	URL            string `mapstructure:"url"`
-	CharacterLimit int    `mapstructure:"character_limit"`
+	URL                   string                  `mapstructure:"url"`
+	ReceiptSecret         string                  `mapstructure:"receipt_secret"`
+	PreviousReceiptSecret string                  `mapstructure:"previous_receipt_secret"`
+	SigningSecret         string                  `mapstructure:"signing_secret"`
+	PreviousSigningSecret string                  `mapstructure:"previous_signing_secret"`
+	BodyTemplate          string                  `mapstructure:"body_template"`
+	ContentType           string                  `mapstructure:"content_type"`
+	SuccessStatusCodes    []int                   `mapstructure:"success_status_codes"`
+	ExternalIDPath        string                  `mapstructure:"external_id_path"`
+	CharacterLimit        int                     `mapstructure:"character_limit"`
+	Providers             []WebhookProviderConfig `mapstructure:"providers"`
+	UnhealthyAfter        int                     `mapstructure:"unhealthy_after"`
+	HealthCooldown        time.Duration           `mapstructure:"health_cooldown"`
+	CircuitBreaker        CircuitBreakerConfig    `mapstructure:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig holds the circuit breaker configuration of the sms channel.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	Cooldown         time.Duration `mapstructure:"cooldown"`
+	HalfOpenRequests int           `mapstructure:"half_open_requests"`
+}
+
+// WebhookProviderConfig holds one of the webhook providers of the sms channel.
+// Providers of the lowest priority share the traffic by weight; the others are only used on failover.
+// A provider posts delivery receipts signed with its receipt secret, or the previous one while rotating it,
+// and cannot post any without one.
+// The body template, content type, success status codes and external ID path describe the API of the
+// provider; left empty, it is sent {"to","content"} as JSON and must answer 202 with a messageId.
+type WebhookProviderConfig struct {
+	Name                  string  `mapstructure:"name"`
+	URL                   string  `mapstructure:"url"`
+	Weight                int     `mapstructure:"weight"`
+	Priority              int     `mapstructure:"priority"`
+	SendRate              float64 `mapstructure:"send_rate"`
+	SendBurst             int     `mapstructure:"send_burst"`
+	ReceiptSecret         string  `mapstructure:"receipt_secret"`
+	PreviousReceiptSecret string  `mapstructure:"previous_receipt_secret"`
+	BodyTemplate          string  `mapstructure:"body_template"`
+	ContentType           string  `mapstructure:"content_type"`
+	SuccessStatusCodes    []int   `mapstructure:"success_status_codes"`
+	ExternalIDPath        string  `mapstructure:"external_id_path"`
+}
+
+// ChatConfig holds the Slack-style incoming webhook configuration of the chat channel.
+type ChatConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"`
+}
+
+// SMTPConfig holds the SMTP server configuration of the email channel.
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	StartTLS bool   `mapstructure:"starttls"`
 }
 
 // SchedulerConfig holds the message dispatch scheduler configuration.
 type SchedulerConfig struct {
-	MessageRate int           `mapstructure:"message_rate"`
-	RunsEvery   time.Duration `mapstructure:"runs_every"`
-	GracePeriod time.Duration `mapstructure:"grace_period"`
-	JobTimeout  time.Duration `mapstructure:"job_timeout"`
+	MessageRate      int           `mapstructure:"message_rate"`
+	RunsEvery        time.Duration `mapstructure:"runs_every"`
+	GracePeriod      time.Duration `mapstructure:"grace_period"`
+	JobTimeout       time.Duration `mapstructure:"job_timeout"`
+	InstanceID       string        `mapstructure:"instance_id"`
+	LeaseDuration    time.Duration `mapstructure:"lease_duration"`
+	RecoveryInterval time.Duration `mapstructure:"recovery_interval"`
+	MaxRecoveries    int           `mapstructure:"max_recoveries"`
+	MaxAttempts      int           `mapstructure:"max_attempts"`
+	RetryBaseDelay   time.Duration `mapstructure:"retry_base_delay"`
+	RetryMaxDelay    time.Duration `mapstructure:"retry_max_delay"`
+	RetryJitter      float64       `mapstructure:"retry_jitter"`
+	ScheduleHorizon  time.Duration `mapstructure:"schedule_horizon"`
+	PriorityReserve  float64       `mapstructure:"priority_reserve"`
+	SendRate         float64       `mapstructure:"send_rate"`
+	SendBurst        int           `mapstructure:"send_burst"`
+}
+
+// RecipientsConfig holds the per-recipient send limit, quiet hours and duplicate suppression.
+// Quiet hours are "15:04" times of day in the recipient's time zone; leave both empty to disable them.
+type RecipientsConfig struct {
+	MaxPerWindow    int           `mapstructure:"max_per_window"`
+	Window          time.Duration `mapstructure:"window"`
+	QuietHoursStart string        `mapstructure:"quiet_hours_start"`
+	QuietHoursEnd   string        `mapstructure:"quiet_hours_end"`
+	DefaultTimezone string        `mapstructure:"default_timezone"`
+	DedupWindow     time.Duration `mapstructure:"dedup_window"`
+}
+
+// CallbacksConfig holds the delivery of status callbacks to the callback_url of messages.
+// Callbacks are disabled when no signing secret is set. The previous secret also signs callbacks while rotating it.
+type CallbacksConfig struct {
+	SigningSecret         string        `mapstructure:"signing_secret"`
+	PreviousSigningSecret string        `mapstructure:"previous_signing_secret"`
+	RunsEvery             time.Duration `mapstructure:"runs_every"`
+	BatchSize             int           `mapstructure:"batch_size"`
+	Timeout               time.Duration `mapstructure:"timeout"`
+	LeaseDuration         time.Duration `mapstructure:"lease_duration"`
+	MaxAttempts           int           `mapstructure:"max_attempts"`
+	RetryBaseDelay        time.Duration `mapstructure:"retry_base_delay"`
+	RetryMaxDelay         time.Duration `mapstructure:"retry_max_delay"`
+	RetryJitter           float64       `mapstructure:"retry_jitter"`
+	Retention             time.Duration `mapstructure:"retention"`
 }
 
 // AppEnvConfig holds application environment settings.
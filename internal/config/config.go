@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -37,8 +38,56 @@ func LoadConfig() (*AppConfig, error) {
 	if cfg.Database.ConnectionString == "" {
 		return nil, fmt.Errorf("database connection string is not configured")
 	}
-	if cfg.Webhook.URL == "" {
-		return nil, fmt.Errorf("webhook URL is not configured")
+	if len(cfg.Webhook.Providers) == 0 {
+		if cfg.Webhook.URL == "" {
+			return nil, fmt.Errorf("webhook URL is not configured")
+		}
+		cfg.Webhook.Providers = []WebhookProviderConfig{{
+			Name:                  "default",
+			URL:                   cfg.Webhook.URL,
+			Weight:                1,
+			ReceiptSecret:         cfg.Webhook.ReceiptSecret,
+			PreviousReceiptSecret: cfg.Webhook.PreviousReceiptSecret,
+			BodyTemplate:          cfg.Webhook.BodyTemplate,
+			ContentType:           cfg.Webhook.ContentType,
+			SuccessStatusCodes:    cfg.Webhook.SuccessStatusCodes,
+			ExternalIDPath:        cfg.Webhook.ExternalIDPath,
+		}}
+	}
+	providerNames := make(map[string]bool, len(cfg.Webhook.Providers))
+	for i := range cfg.Webhook.Providers {
+		provider := &cfg.Webhook.Providers[i]
+		if provider.Name == "" {
+			provider.Name = fmt.Sprintf("provider-%d", i+1)
+		}
+		if providerNames[provider.Name] {
+			return nil, fmt.Errorf("webhook provider %q is configured more than once", provider.Name)
+		}
+		providerNames[provider.Name] = true
+		if provider.URL == "" {
+			return nil, fmt.Errorf("webhook provider %q has no URL", provider.Name)
+		}
+		if provider.Weight <= 0 {
+			provider.Weight = 1
+		}
+		if provider.SendRate > 0 && provider.SendBurst <= 0 {
+			provider.SendBurst = 1
+		}
+	}
+	if cfg.Webhook.UnhealthyAfter <= 0 {
+		cfg.Webhook.UnhealthyAfter = 3
+	}
+	if cfg.Webhook.HealthCooldown <= 0 {
+		cfg.Webhook.HealthCooldown = 30 * time.Second
+	}
+	if cfg.Webhook.CircuitBreaker.FailureThreshold <= 0 {
+		cfg.Webhook.CircuitBreaker.FailureThreshold = 5
+	}
+	if cfg.Webhook.CircuitBreaker.Cooldown <= 0 {
+		cfg.Webhook.CircuitBreaker.Cooldown = time.Minute
+	}
+	if cfg.Webhook.CircuitBreaker.HalfOpenRequests <= 0 {
+		cfg.Webhook.CircuitBreaker.HalfOpenRequests = 1
 	}
 
 	if cfg.Scheduler.MessageRate <= 0 {
@@ -53,11 +102,124 @@ func LoadConfig() (*AppConfig, error) {
 		fmt.Println("WARNING: Scheduler grace period set to 0 or greater than scheduler Interval, defaulting to 30 secs")
 		cfg.Scheduler.GracePeriod = 30 * time.Second
 	}
+	if cfg.Scheduler.LeaseDuration <= cfg.Scheduler.RunsEvery {
+		// A batch may keep a message queued for a whole run before sending it.
+		fmt.Println("WARNING: Scheduler lease duration not set or shorter than scheduler interval, defaulting to runs_every + job_timeout")
+		cfg.Scheduler.LeaseDuration = cfg.Scheduler.RunsEvery + cfg.Scheduler.JobTimeout
+	}
+	if cfg.Scheduler.RecoveryInterval <= 0 {
+		cfg.Scheduler.RecoveryInterval = time.Minute
+	}
+	if cfg.Scheduler.MaxRecoveries <= 0 {
+		cfg.Scheduler.MaxRecoveries = 3
+	}
+
+	if cfg.Scheduler.MaxAttempts <= 0 {
+		cfg.Scheduler.MaxAttempts = 5
+	}
+	if cfg.Scheduler.RetryBaseDelay <= 0 {
+		cfg.Scheduler.RetryBaseDelay = 30 * time.Second
+	}
+	if cfg.Scheduler.RetryMaxDelay < cfg.Scheduler.RetryBaseDelay {
+		fmt.Println("WARNING: Scheduler retry max delay set lower than retry base delay, defaulting to 30 minutes")
+		cfg.Scheduler.RetryMaxDelay = max(30*time.Minute, cfg.Scheduler.RetryBaseDelay)
+	}
+	if cfg.Scheduler.RetryJitter < 0 || cfg.Scheduler.RetryJitter > 1 {
+		fmt.Println("WARNING: Scheduler retry jitter must be between 0 and 1, defaulting to 0.2")
+		cfg.Scheduler.RetryJitter = 0.2
+	}
+
+	if cfg.Scheduler.ScheduleHorizon <= 0 {
+		cfg.Scheduler.ScheduleHorizon = 30 * 24 * time.Hour
+	}
+	if cfg.Scheduler.PriorityReserve < 0 || cfg.Scheduler.PriorityReserve >= 1 {
+		fmt.Println("WARNING: Scheduler priority reserve must be at least 0 and below 1, defaulting to 0.2")
+		cfg.Scheduler.PriorityReserve = 0.2
+	}
+
+	if cfg.Scheduler.SendRate < 0 {
+		fmt.Println("WARNING: Scheduler send rate set below 0, disabling rate limiting")
+		cfg.Scheduler.SendRate = 0
+	}
+	if cfg.Scheduler.SendRate > 0 && cfg.Scheduler.SendBurst <= 0 {
+		cfg.Scheduler.SendBurst = 1
+	}
+
+	if cfg.Scheduler.InstanceID == "" {
+		// Identifies this replica as the owner of the messages it claims.
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		cfg.Scheduler.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	if cfg.Recipients.MaxPerWindow < 0 {
+		fmt.Println("WARNING: Recipient max per window set below 0, disabling the recipient limit")
+		cfg.Recipients.MaxPerWindow = 0
+	}
+	if cfg.Recipients.Window <= 0 {
+		cfg.Recipients.Window = time.Hour
+	}
+	if cfg.Recipients.DedupWindow < 0 {
+		fmt.Println("WARNING: Recipient dedup window set below 0, disabling deduplication")
+		cfg.Recipients.DedupWindow = 0
+	}
+	if cfg.Recipients.DefaultTimezone == "" {
+		cfg.Recipients.DefaultTimezone = "UTC"
+	}
+	if _, err := time.LoadLocation(cfg.Recipients.DefaultTimezone); err != nil {
+		return nil, fmt.Errorf("invalid recipients default time zone %q: %w", cfg.Recipients.DefaultTimezone, err)
+	}
+
+	if cfg.Callbacks.RunsEvery <= 0 {
+		cfg.Callbacks.RunsEvery = 10 * time.Second
+	}
+	if cfg.Callbacks.BatchSize <= 0 {
+		cfg.Callbacks.BatchSize = 50
+	}
+	if cfg.Callbacks.Timeout <= 0 {
+		cfg.Callbacks.Timeout = 10 * time.Second
+	}
+	if cfg.Callbacks.LeaseDuration <= cfg.Callbacks.Timeout {
+		// A delivery must not be claimed again while it is still being posted.
+		fmt.Println("WARNING: Callbacks lease duration not set or shorter than callbacks timeout, defaulting to timeout + 1 minute")
+		cfg.Callbacks.LeaseDuration = cfg.Callbacks.Timeout + time.Minute
+	}
+	if cfg.Callbacks.MaxAttempts <= 0 {
+		cfg.Callbacks.MaxAttempts = 8
+	}
+	if cfg.Callbacks.RetryBaseDelay <= 0 {
+		cfg.Callbacks.RetryBaseDelay = 30 * time.Second
+	}
+	if cfg.Callbacks.RetryMaxDelay < cfg.Callbacks.RetryBaseDelay {
+		fmt.Println("WARNING: Callbacks retry max delay set lower than retry base delay, defaulting to 1 hour")
+		cfg.Callbacks.RetryMaxDelay = max(time.Hour, cfg.Callbacks.RetryBaseDelay)
+	}
+	if cfg.Callbacks.RetryJitter < 0 || cfg.Callbacks.RetryJitter > 1 {
+		fmt.Println("WARNING: Callbacks retry jitter must be between 0 and 1, defaulting to 0.2")
+		cfg.Callbacks.RetryJitter = 0.2
+	}
+	if cfg.Callbacks.Retention <= 0 {
+		cfg.Callbacks.Retention = 7 * 24 * time.Hour
+	}
+
+	if cfg.SMTP.Host != "" && cfg.SMTP.Port <= 0 {
+		cfg.SMTP.Port = 587
+	}
 
 	if cfg.Server.GracePeriod <= 0 {
 		// If no specific grace period is set.
 		cfg.Server.GracePeriod = cfg.Server.WriteTimeout + cfg.Server.IdleTimeout
 	}
 
+	if cfg.Server.IdempotencyWindow <= 0 {
+		cfg.Server.IdempotencyWindow = 24 * time.Hour
+	}
+	if cfg.Server.IdempotencyLockTimeout <= cfg.Server.WriteTimeout {
+		// A key must not be taken over while its request may still be running.
+		cfg.Server.IdempotencyLockTimeout = cfg.Server.WriteTimeout + time.Minute
+	}
+
 	return &cfg, nil
 }
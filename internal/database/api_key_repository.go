@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+	"github.com/akshaysangma/go-notify/internal/database/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAPIKeyRepository stores API keys using sqlc generated queries.
+type PostgresAPIKeyRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewPostgresAPIKeyRepository returns PostgresAPIKeyRepository
+func NewPostgresAPIKeyRepository(pool PgxPoolInterface) (*PostgresAPIKeyRepository, error) {
+	if dBTX, ok := pool.(sqlc.DBTX); ok {
+		return &PostgresAPIKeyRepository{
+			queries: sqlc.New(dBTX),
+		}, nil
+	}
+	return nil, fmt.Errorf("unable to convert pool to dBTX")
+}
+
+func scopesToDB(scopes []apikeys.Scope) []string {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	return names
+}
+
+func scopesFromDB(names []string) []apikeys.Scope {
+	scopes := make([]apikeys.Scope, 0, len(names))
+	for _, name := range names {
+		scopes = append(scopes, apikeys.Scope(name))
+	}
+	return scopes
+}
+
+// Create call sqlc generated CreateAPIKey for storing a new key.
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key apikeys.Key, secretHash string) (*apikeys.Key, error) {
+	id, err := uuid.Parse(key.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key ID %q: %w", key.ID, err)
+	}
+
+	createdAt, err := r.queries.CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+		ID:        id,
+		Name:      key.Name,
+		KeyPrefix: key.Prefix,
+		KeyHash:   secretHash,
+		Scopes:    scopesToDB(key.Scopes),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert API key: %w", err)
+	}
+	key.CreatedAt = createdAt.Time
+	return &key, nil
+}
+
+// GetByHash call sqlc generated GetAPIKeyByHash for fetching the active key of a secret.
+func (r *PostgresAPIKeyRepository) GetByHash(ctx context.Context, secretHash string) (*apikeys.Key, error) {
+	dbKey, err := r.queries.GetAPIKeyByHash(ctx, secretHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apikeys.ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch API key: %w", err)
+	}
+	return &apikeys.Key{
+		ID:        dbKey.ID.String(),
+		Name:      dbKey.Name,
+		Prefix:    dbKey.KeyPrefix,
+		Scopes:    scopesFromDB(dbKey.Scopes),
+		CreatedAt: dbKey.CreatedAt.Time,
+	}, nil
+}
+
+// List call sqlc generated ListAPIKeys for fetching every key.
+func (r *PostgresAPIKeyRepository) List(ctx context.Context) ([]apikeys.Key, error) {
+	dbKeys, err := r.queries.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	keys := make([]apikeys.Key, 0, len(dbKeys))
+	for _, dbKey := range dbKeys {
+		keys = append(keys, apikeys.Key{
+			ID:        dbKey.ID.String(),
+			Name:      dbKey.Name,
+			Prefix:    dbKey.KeyPrefix,
+			Scopes:    scopesFromDB(dbKey.Scopes),
+			Revoked:   dbKey.Revoked,
+			CreatedAt: dbKey.CreatedAt.Time,
+		})
+	}
+	return keys, nil
+}
+
+// Revoke call sqlc generated RevokeAPIKey for revoking a key.
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, keyID string) error {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return apikeys.ErrKeyNotFound
+	}
+
+	revoked, err := r.queries.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if revoked == 0 {
+		return apikeys.ErrKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akshaysangma/go-notify/internal/apikeys"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresAPIKeyRepository_Lifecycle(t *testing.T) {
+	pool := newTestPool(t)
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.api_keys")
+	require.NoError(t, err)
+	repo, err := NewPostgresAPIKeyRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	key := apikeys.Key{
+		ID:     uuid.NewString(),
+		Name:   "billing",
+		Prefix: "gn_abcdefgh",
+		Scopes: []apikeys.Scope{apikeys.ScopeMessagesWrite, apikeys.ScopeMessagesRead},
+	}
+	created, err := repo.Create(ctx, key, apikeys.HashSecret("gn_secret"))
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	fetched, err := repo.GetByHash(ctx, apikeys.HashSecret("gn_secret"))
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, fetched.ID)
+	assert.Equal(t, key.Scopes, fetched.Scopes)
+	_, err = repo.GetByHash(ctx, apikeys.HashSecret("gn_other"))
+	assert.ErrorIs(t, err, apikeys.ErrKeyNotFound)
+
+	require.NoError(t, repo.Revoke(ctx, key.ID))
+	assert.ErrorIs(t, repo.Revoke(ctx, key.ID), apikeys.ErrKeyNotFound)
+	_, err = repo.GetByHash(ctx, apikeys.HashSecret("gn_secret"))
+	assert.ErrorIs(t, err, apikeys.ErrKeyNotFound)
+
+	listed, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.True(t, listed[0].Revoked)
+	assert.Equal(t, "gn_abcdefgh", listed[0].Prefix)
+}
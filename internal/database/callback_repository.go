@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/callbacks"
+	"github.com/akshaysangma/go-notify/internal/database/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresCallbackRepository stores the callback delivery queue using sqlc generated queries.
+type PostgresCallbackRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewPostgresCallbackRepository returns PostgresCallbackRepository
+func NewPostgresCallbackRepository(pool PgxPoolInterface) (*PostgresCallbackRepository, error) {
+	if dBTX, ok := pool.(sqlc.DBTX); ok {
+		return &PostgresCallbackRepository{
+			queries: sqlc.New(dBTX),
+		}, nil
+	}
+	return nil, fmt.Errorf("unable to convert pool to dBTX")
+}
+
+// Enqueue call sqlc generated CreateCallbackDelivery for queueing a new delivery.
+func (r *PostgresCallbackRepository) Enqueue(ctx context.Context, delivery callbacks.Delivery) error {
+	return createCallbackDelivery(ctx, r.queries, delivery)
+}
+
+// createCallbackDelivery stores delivery through q, which may be bound to a transaction of another repository.
+func createCallbackDelivery(ctx context.Context, q *sqlc.Queries, delivery callbacks.Delivery) error {
+	id, err := uuid.Parse(delivery.ID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery id %q: %w", delivery.ID, err)
+	}
+	messageID, err := uuid.Parse(delivery.MessageID)
+	if err != nil {
+		return fmt.Errorf("invalid message id %q: %w", delivery.MessageID, err)
+	}
+
+	err = q.CreateCallbackDelivery(ctx, sqlc.CreateCallbackDeliveryParams{
+		ID:          id,
+		MessageID:   messageID,
+		Url:         delivery.URL,
+		Payload:     delivery.Payload,
+		MaxAttempts: int32(delivery.MaxAttempts),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to create callback delivery in db: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue call sqlc generated ClaimCallbackDeliveries for claiming due deliveries under a lease.
+func (r *PostgresCallbackRepository) ClaimDue(ctx context.Context, instanceID string, lease time.Duration, limit int32) ([]callbacks.Delivery, error) {
+	rows, err := r.queries.ClaimCallbackDeliveries(ctx, sqlc.ClaimCallbackDeliveriesParams{
+		BatchSize:     limit,
+		ClaimedBy:     instanceID,
+		LeaseDuration: pgtype.Interval{Microseconds: lease.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to claim callback deliveries from db: %w", err)
+	}
+
+	deliveries := make([]callbacks.Delivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = callbacks.Delivery{
+			ID:           row.ID.String(),
+			MessageID:    row.MessageID.String(),
+			URL:          row.Url,
+			Payload:      row.Payload,
+			AttemptCount: int(row.AttemptCount),
+			MaxAttempts:  int(row.MaxAttempts),
+		}
+	}
+	return deliveries, nil
+}
+
+// MarkDelivered call sqlc generated MarkCallbackDelivered.
+// Returns callbacks.ErrLeaseLost if instanceID no longer holds the claim.
+func (r *PostgresCallbackRepository) MarkDelivered(ctx context.Context, deliveryID, instanceID string) error {
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery id %q: %w", deliveryID, err)
+	}
+	updated, err := r.queries.MarkCallbackDelivered(ctx, sqlc.MarkCallbackDeliveredParams{
+		ID:        id,
+		ClaimedBy: mapDomainToText(instanceID),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to mark callback delivery %s as delivered: %w", deliveryID, err)
+	}
+	if updated == 0 {
+		return callbacks.ErrLeaseLost
+	}
+	return nil
+}
+
+// ScheduleRetry call sqlc generated ScheduleCallbackRetry.
+// Returns callbacks.ErrLeaseLost if instanceID no longer holds the claim.
+func (r *PostgresCallbackRepository) ScheduleRetry(ctx context.Context, deliveryID, instanceID string, nextAttemptAt time.Time, lastError string) error {
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery id %q: %w", deliveryID, err)
+	}
+	updated, err := r.queries.ScheduleCallbackRetry(ctx, sqlc.ScheduleCallbackRetryParams{
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+		LastError:     mapDomainToText(lastError),
+		ID:            id,
+		ClaimedBy:     mapDomainToText(instanceID),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to schedule retry of callback delivery %s: %w", deliveryID, err)
+	}
+	if updated == 0 {
+		return callbacks.ErrLeaseLost
+	}
+	return nil
+}
+
+// MarkFailed call sqlc generated MarkCallbackFailed.
+// Returns callbacks.ErrLeaseLost if instanceID no longer holds the claim.
+func (r *PostgresCallbackRepository) MarkFailed(ctx context.Context, deliveryID, instanceID string, lastError string) error {
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery id %q: %w", deliveryID, err)
+	}
+	updated, err := r.queries.MarkCallbackFailed(ctx, sqlc.MarkCallbackFailedParams{
+		LastError: mapDomainToText(lastError),
+		ID:        id,
+		ClaimedBy: mapDomainToText(instanceID),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to mark callback delivery %s as failed: %w", deliveryID, err)
+	}
+	if updated == 0 {
+		return callbacks.ErrLeaseLost
+	}
+	return nil
+}
+
+// DeleteFinished call sqlc generated DeleteFinishedCallbackDeliveries.
+func (r *PostgresCallbackRepository) DeleteFinished(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	deleted, err := r.queries.DeleteFinishedCallbackDeliveries(ctx, pgtype.Timestamptz{Time: finishedBefore, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("fail to delete finished callback deliveries from db: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/callbacks"
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresCallbackRepository_Lifecycle(t *testing.T) {
+	pool := newTestPool(t)
+	msgRepo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	repo, err := NewPostgresCallbackRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+	msg := seedPendingMessages(t, msgRepo, 1)[0]
+
+	delivery := callbacks.Delivery{
+		ID:          uuid.NewString(),
+		MessageID:   msg.ID,
+		URL:         "https://example.com/hooks",
+		Payload:     []byte(`{"status":"sent"}`),
+		MaxAttempts: 2,
+	}
+	require.NoError(t, repo.Enqueue(ctx, delivery))
+
+	claimed, err := repo.ClaimDue(ctx, "instance-a", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, delivery.ID, claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].AttemptCount)
+	assert.JSONEq(t, `{"status":"sent"}`, string(claimed[0].Payload))
+
+	// A claimed delivery is not handed out again while its lease holds.
+	claimed, err = repo.ClaimDue(ctx, "instance-b", time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// A retry in the past is due right away.
+	require.NoError(t, repo.ScheduleRetry(ctx, delivery.ID, "instance-a", time.Now().Add(-time.Second), "status 503"))
+	claimed, err = repo.ClaimDue(ctx, "instance-b", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].AttemptCount)
+
+	// Only the instance holding the claim records the outcome.
+	assert.ErrorIs(t, repo.MarkDelivered(ctx, delivery.ID, "instance-a"), callbacks.ErrLeaseLost)
+	require.NoError(t, repo.MarkDelivered(ctx, delivery.ID, "instance-b"))
+	assert.ErrorIs(t, repo.MarkFailed(ctx, delivery.ID, "instance-b", "status 410"), callbacks.ErrLeaseLost)
+	claimed, err = repo.ClaimDue(ctx, "instance-b", -time.Second, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestPostgresCallbackRepository_ClaimDue_ExpiredLease(t *testing.T) {
+	pool := newTestPool(t)
+	msgRepo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	repo, err := NewPostgresCallbackRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+	msg := seedPendingMessages(t, msgRepo, 1)[0]
+
+	deliveryID := uuid.NewString()
+	require.NoError(t, repo.Enqueue(ctx, callbacks.Delivery{
+		ID: deliveryID, MessageID: msg.ID, URL: "https://example.com/hooks", Payload: []byte(`{}`), MaxAttempts: 3,
+	}))
+
+	// The first claim gets a lease that is already over, as if its instance crashed mid-post.
+	claimed, err := repo.ClaimDue(ctx, "instance-a", -time.Second, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	claimed, err = repo.ClaimDue(ctx, "instance-b", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, deliveryID, claimed[0].ID)
+
+	// The post of the first instance outlived its lease, so its outcome must not overwrite the new claim.
+	assert.ErrorIs(t, repo.MarkDelivered(ctx, deliveryID, "instance-a"), callbacks.ErrLeaseLost)
+
+	// A retry in the future is not due yet.
+	require.NoError(t, repo.ScheduleRetry(ctx, deliveryID, "instance-b", time.Now().Add(time.Hour), "status 503"))
+	claimed, err = repo.ClaimDue(ctx, "instance-c", time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestPostgresCallbackRepository_DeleteFinished(t *testing.T) {
+	pool := newTestPool(t)
+	msgRepo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	repo, err := NewPostgresCallbackRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+	msg := seedPendingMessages(t, msgRepo, 1)[0]
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id := uuid.NewString()
+		require.NoError(t, repo.Enqueue(ctx, callbacks.Delivery{
+			ID: id, MessageID: msg.ID, URL: "https://example.com/hooks", Payload: []byte(`{}`), MaxAttempts: 3,
+		}))
+		ids = append(ids, id)
+	}
+	claimed, err := repo.ClaimDue(ctx, "instance-a", time.Minute, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.NoError(t, repo.MarkDelivered(ctx, claimed[0].ID, "instance-a"))
+	require.NoError(t, repo.MarkFailed(ctx, claimed[1].ID, "instance-a", "status 410"))
+
+	// Finished deliveries are kept until they are older than the cutoff, pending ones are never deleted.
+	deleted, err := repo.DeleteFinished(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+	deleted, err = repo.DeleteFinished(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	claimed, err = repo.ClaimDue(ctx, "instance-a", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Contains(t, ids, claimed[0].ID)
+}
+
+func TestPostgresMessageRepository_QueuesStatusCallbacks(t *testing.T) {
+	pool := newTestPool(t)
+	msgRepo, err := NewPostgresMessageRepository(pool, 3)
+	require.NoError(t, err)
+	repo, err := NewPostgresCallbackRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	batchID := "f0e1d2c3-b4a5-6789-0123-456789abcdef"
+	var msgs []*messages.Message
+	for i := 0; i < 4; i++ {
+		msg, err := messages.NewMessage(fmt.Sprintf("message %d", i), fmt.Sprintf("+1555000%04d", i), 250)
+		require.NoError(t, err)
+		msg.CallbackURL = "https://example.com/hooks"
+		msgs = append(msgs, msg)
+	}
+	msgs[1].BatchID = batchID
+	msgs[3].CallbackURL = ""
+	require.NoError(t, msgRepo.CreateMessages(ctx, msgs))
+
+	cancelled := *msgs[0]
+	require.NoError(t, cancelled.MarkAsCancelled())
+	require.NoError(t, msgRepo.CancelMessage(ctx, cancelled))
+	n, err := msgRepo.CancelBatch(ctx, batchID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// The remaining messages fail on their first expired lease; only the one with a callback URL is notified.
+	_, err = msgRepo.ClaimPendingMessages(ctx, "crashed-instance", time.Millisecond, 2, 0)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	result, err := msgRepo.RecoverExpiredMessages(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, messages.RecoveryResult{Failed: 2}, result)
+
+	claimed, err := repo.ClaimDue(ctx, "instance-a", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	events := make(map[string]callbacks.Event, len(claimed))
+	for _, delivery := range claimed {
+		assert.Equal(t, "https://example.com/hooks", delivery.URL)
+		assert.Equal(t, 3, delivery.MaxAttempts)
+		var event callbacks.Event
+		require.NoError(t, json.Unmarshal(delivery.Payload, &event))
+		events[delivery.MessageID] = event
+	}
+	assert.Equal(t, "cancelled", events[msgs[0].ID].Status)
+	assert.Equal(t, "cancelled", events[msgs[1].ID].Status)
+	require.NotNil(t, events[msgs[2].ID].FailureReason)
+	assert.Equal(t, "failed", events[msgs[2].ID].Status)
+	assert.Equal(t, "lease expired while sending", *events[msgs[2].ID].FailureReason)
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/database/sqlc"
+	"github.com/akshaysangma/go-notify/internal/idempotency"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresIdempotencyRepository stores Idempotency-Key records using sqlc generated queries.
+type PostgresIdempotencyRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewPostgresIdempotencyRepository returns PostgresIdempotencyRepository
+func NewPostgresIdempotencyRepository(pool PgxPoolInterface) (*PostgresIdempotencyRepository, error) {
+	if dBTX, ok := pool.(sqlc.DBTX); ok {
+		return &PostgresIdempotencyRepository{
+			queries: sqlc.New(dBTX),
+		}, nil
+	}
+	return nil, fmt.Errorf("unable to convert pool to dBTX")
+}
+
+// Reserve call sqlc generated ReserveIdempotencyKey for claiming a key.
+// The insert and the takeover of an expired key happen in a single statement, so only one
+// concurrent request can reserve a key. Others receive the existing record.
+func (r *PostgresIdempotencyRepository) Reserve(ctx context.Context, apiKeyID, key, requestHash string, lockTimeout time.Duration) (*idempotency.Record, bool, error) {
+	_, err := r.queries.ReserveIdempotencyKey(ctx, sqlc.ReserveIdempotencyKeyParams{
+		ApiKeyID:    apiKeyID,
+		Key:         key,
+		RequestHash: requestHash,
+		Ttl:         pgtype.Interval{Microseconds: lockTimeout.Microseconds(), Valid: true},
+	})
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	dbKey, err := r.queries.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{ApiKeyID: apiKeyID, Key: key})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Released by its owner between the two statements.
+			return nil, false, idempotency.ErrKeyNotFound
+		}
+		return nil, false, fmt.Errorf("failed to fetch idempotency key: %w", err)
+	}
+
+	record := &idempotency.Record{
+		APIKeyID:     dbKey.ApiKeyID,
+		Key:          dbKey.Key,
+		RequestHash:  dbKey.RequestHash,
+		ResponseBody: dbKey.ResponseBody,
+		ExpiresAt:    dbKey.ExpiresAt.Time,
+	}
+	if dbKey.StatusCode.Valid {
+		record.StatusCode = int(dbKey.StatusCode.Int32)
+	}
+	return record, false, nil
+}
+
+// Complete call sqlc generated CompleteIdempotencyKey for storing the response of a reserved key.
+func (r *PostgresIdempotencyRepository) Complete(ctx context.Context, apiKeyID, key string, statusCode int, responseBody []byte, window time.Duration) error {
+	err := r.queries.CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		ApiKeyID:     apiKeyID,
+		Key:          key,
+		StatusCode:   pgtype.Int4{Int32: int32(statusCode), Valid: true},
+		ResponseBody: responseBody,
+		Ttl:          pgtype.Interval{Microseconds: window.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release call sqlc generated DeleteIdempotencyKey for freeing a reserved key.
+func (r *PostgresIdempotencyRepository) Release(ctx context.Context, apiKeyID, key string) error {
+	if err := r.queries.DeleteIdempotencyKey(ctx, sqlc.DeleteIdempotencyKeyParams{ApiKeyID: apiKeyID, Key: key}); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired call sqlc generated DeleteExpiredIdempotencyKeys for removing expired keys.
+func (r *PostgresIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	deleted, err := r.queries.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return deleted, nil
+}
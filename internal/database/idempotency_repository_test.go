@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresIdempotencyRepository_Lifecycle(t *testing.T) {
+	pool := newTestPool(t)
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.idempotency_keys")
+	require.NoError(t, err)
+	repo, err := NewPostgresIdempotencyRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, reserved, err := repo.Reserve(ctx, "api-key-1", "key-1", "hash-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// A second request sees the in-progress record.
+	record, reserved, err := repo.Reserve(ctx, "api-key-1", "key-1", "hash-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.False(t, record.Completed())
+
+	require.NoError(t, repo.Complete(ctx, "api-key-1", "key-1", 202, []byte(`{"batch_id":"b1"}`), time.Hour))
+	record, reserved, err = repo.Reserve(ctx, "api-key-1", "key-1", "hash-2", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "hash-1", record.RequestHash)
+	assert.Equal(t, 202, record.StatusCode)
+	assert.JSONEq(t, `{"batch_id":"b1"}`, string(record.ResponseBody))
+
+	// Released and expired keys can be reserved again.
+	require.NoError(t, repo.Release(ctx, "api-key-1", "key-1"))
+	_, reserved, err = repo.Reserve(ctx, "api-key-1", "key-1", "hash-2", time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	time.Sleep(10 * time.Millisecond)
+	_, reserved, err = repo.Reserve(ctx, "api-key-1", "key-1", "hash-3", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
+
+func TestPostgresIdempotencyRepository_ScopedToAPIKey(t *testing.T) {
+	pool := newTestPool(t)
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.idempotency_keys")
+	require.NoError(t, err)
+	repo, err := NewPostgresIdempotencyRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, reserved, err := repo.Reserve(ctx, "api-key-1", "key-1", "hash-1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	require.NoError(t, repo.Complete(ctx, "api-key-1", "key-1", 202, []byte(`{"batch_id":"b1"}`), time.Hour))
+
+	// Another API key using the same Idempotency-Key gets its own record.
+	_, reserved, err = repo.Reserve(ctx, "api-key-2", "key-1", "hash-2", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	require.NoError(t, repo.Complete(ctx, "api-key-2", "key-1", 202, []byte(`{"batch_id":"b2"}`), time.Hour))
+
+	record, reserved, err := repo.Reserve(ctx, "api-key-1", "key-1", "hash-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "api-key-1", record.APIKeyID)
+	assert.JSONEq(t, `{"batch_id":"b1"}`, string(record.ResponseBody))
+
+	// Releasing the key of one API key leaves the other's record alone.
+	require.NoError(t, repo.Release(ctx, "api-key-2", "key-1"))
+	_, reserved, err = repo.Reserve(ctx, "api-key-1", "key-1", "hash-1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+}
+
+func TestPostgresIdempotencyRepository_LockTimeout(t *testing.T) {
+	pool := newTestPool(t)
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.idempotency_keys")
+	require.NoError(t, err)
+	repo, err := NewPostgresIdempotencyRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// A key whose request never completed frees up after the lock timeout.
+	_, reserved, err := repo.Reserve(ctx, "api-key-1", "abandoned", "hash-1", time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	time.Sleep(10 * time.Millisecond)
+	_, reserved, err = repo.Reserve(ctx, "api-key-1", "abandoned", "hash-1", time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// A completed key is kept for the window, whatever its lock timeout was.
+	_, reserved, err = repo.Reserve(ctx, "api-key-1", "completed", "hash-1", time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	require.NoError(t, repo.Complete(ctx, "api-key-1", "completed", 202, []byte(`{"batch_id":"b1"}`), time.Hour))
+	time.Sleep(10 * time.Millisecond)
+	record, reserved, err := repo.Reserve(ctx, "api-key-1", "completed", "hash-1", time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, 202, record.StatusCode)
+}
+
+func TestPostgresIdempotencyRepository_DeleteExpired(t *testing.T) {
+	pool := newTestPool(t)
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.idempotency_keys")
+	require.NoError(t, err)
+	repo, err := NewPostgresIdempotencyRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, _, err = repo.Reserve(ctx, "api-key-1", "expired", "hash-1", time.Millisecond)
+	require.NoError(t, err)
+	_, _, err = repo.Reserve(ctx, "api-key-1", "live", "hash-1", time.Hour)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	deleted, err := repo.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// The live key is kept, the expired one is gone rather than only ignored.
+	record, reserved, err := repo.Reserve(ctx, "api-key-1", "live", "hash-2", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "hash-1", record.RequestHash)
+	deleted, err = repo.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
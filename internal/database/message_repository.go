@@ -2,41 +2,58 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/akshaysangma/go-notify/internal/callbacks"
 	"github.com/akshaysangma/go-notify/internal/database/sqlc"
 	"github.com/akshaysangma/go-notify/internal/messages"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // PostgresMessageRepository contains sqlc queries to execute type safe generated queries.
 // Object pool is used by services where multiple queries need to be ran in transactional manner.
 type PostgresMessageRepository struct {
-	queries *sqlc.Queries
-	pool    PgxPoolInterface //for Transactions
+	queries          *sqlc.Queries
+	pool             PgxPoolInterface //for Transactions
+	callbackAttempts int
 }
 
-// NewPostgresMessageRepository returns PostgresMessageRepository
-func NewPostgresMessageRepository(pool PgxPoolInterface) (*PostgresMessageRepository, error) {
+// NewPostgresMessageRepository returns PostgresMessageRepository.
+// callbackAttempts is the number of posts allowed for the status callbacks the repository queues itself,
+// when it fails or cancels messages outside the send path. Zero disables them.
+func NewPostgresMessageRepository(pool PgxPoolInterface, callbackAttempts int) (*PostgresMessageRepository, error) {
 	if dBTX, ok := pool.(sqlc.DBTX); ok {
 		return &PostgresMessageRepository{
-			queries: sqlc.New(dBTX),
-			pool:    pool,
+			queries:          sqlc.New(dBTX),
+			pool:             pool,
+			callbackAttempts: callbackAttempts,
 		}, nil
 	}
 	return nil, fmt.Errorf("unable to convert pool to dBTX")
 }
 
-// mapDBMessageToDomain converts a sqlc.Message to a messages.Message domain model.
-func mapDBPendingMessageToDomain(dbMsg *sqlc.GetPendingMessagesRow) (*messages.Message, error) {
+// mapDBClaimedMessageToDomain converts a sqlc.ClaimPendingMessagesRow to a messages.Message domain model.
+func mapDBClaimedMessageToDomain(dbMsg *sqlc.ClaimPendingMessagesRow) (*messages.Message, error) {
 	msg := &messages.Message{
-		ID:        dbMsg.ID.String(),
-		Content:   dbMsg.Content,
-		Recipient: dbMsg.RecipientPhoneNumber,
-		Status:    string(dbMsg.Status),
-		CreatedAt: dbMsg.CreatedAt,
-		UpdatedAt: dbMsg.UpdatedAt,
+		ID:           dbMsg.ID.String(),
+		Content:      dbMsg.Content,
+		Channel:      dbMsg.Channel,
+		Subject:      dbMsg.Subject.String,
+		Recipient:    dbMsg.Recipient,
+		Status:       string(dbMsg.Status),
+		Priority:     messages.PriorityFromRank(dbMsg.Priority),
+		Timezone:     dbMsg.Timezone.String,
+		CallbackURL:  dbMsg.CallbackUrl.String,
+		AttemptCount: int(dbMsg.AttemptCount),
+		MaxAttempts:  int(dbMsg.MaxAttempts),
+		CreatedAt:    dbMsg.CreatedAt,
+		UpdatedAt:    dbMsg.UpdatedAt,
 	}
 
 	if dbMsg.ExternalMessageID.Valid {
@@ -51,7 +68,7 @@ func mapDBSentMessageToDomain(dbMsg *sqlc.GetAllSentMessagesRow) (*messages.Mess
 	msg := &messages.Message{
 		ID:        dbMsg.ID.String(),
 		Content:   dbMsg.Content,
-		Recipient: dbMsg.RecipientPhoneNumber,
+		Recipient: dbMsg.Recipient,
 		Status:    string(dbMsg.Status),
 		CreatedAt: dbMsg.CreatedAt,
 		UpdatedAt: dbMsg.UpdatedAt,
@@ -64,30 +81,101 @@ func mapDBSentMessageToDomain(dbMsg *sqlc.GetAllSentMessagesRow) (*messages.Mess
 	return msg, nil
 }
 
-// GetPendingMessages call sqlc generated GetPendingMessages for fetching pending messages.
-// Takes limit as param to control max fetch count.
-func (r *PostgresMessageRepository) GetPendingMessages(ctx context.Context, limit int32) ([]messages.Message, error) {
-	pendingMsgs, err := r.queries.GetPendingMessages(ctx, limit)
+// ClaimPendingMessages call sqlc generated ClaimPendingMessages for claiming pending messages.
+// Rows are locked with FOR UPDATE SKIP LOCKED and moved to 'sending' in a single statement,
+// so concurrent instances never receive the same message. Takes limit as param to control max claim count,
+// of which reserved slots go to the oldest messages whatever their priority.
+func (r *PostgresMessageRepository) ClaimPendingMessages(ctx context.Context, instanceID string, lease time.Duration, limit, reserved int32) ([]messages.Message, error) {
+	claimedMsgs, err := r.queries.ClaimPendingMessages(ctx, sqlc.ClaimPendingMessagesParams{
+		BatchSize:     limit,
+		ReservedSize:  reserved,
+		ClaimedBy:     instanceID,
+		LeaseDuration: pgtype.Interval{Microseconds: lease.Microseconds(), Valid: true},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("fail to fetch pending messages from db: %w", err)
+		return nil, fmt.Errorf("fail to claim pending messages from db: %w", err)
 	}
 	var msgs []messages.Message
-	for _, dbMsg := range pendingMsgs {
-		msg, err := mapDBPendingMessageToDomain(&dbMsg)
+	for _, dbMsg := range claimedMsgs {
+		msg, err := mapDBClaimedMessageToDomain(&dbMsg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to map db message to domain for ID %s: %w", dbMsg.ID.String(), err)
 		}
+		msg.ClaimedBy = instanceID
 		msgs = append(msgs, *msg)
 	}
 	return msgs, nil
 }
 
-// GetPendingMessages call sqlc generated UpdateMessageStatus for updating message status.
-// Additionally it also updates external ID and LastFailureReason if avialable.
+// RecoverExpiredMessages call sqlc generated RecoverExpiredMessages for releasing messages whose lease expired.
+// Messages that ran out of recoveries are moved to the dead-letter queue, and their failure callbacks queued,
+// in the same transaction.
+func (r *PostgresMessageRepository) RecoverExpiredMessages(ctx context.Context, maxRecoveries int32) (messages.RecoveryResult, error) {
+	var result messages.RecoveryResult
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	rows, err := qtx.RecoverExpiredMessages(ctx, maxRecoveries)
+	if err != nil {
+		return result, fmt.Errorf("fail to recover expired messages: %w", err)
+	}
+	for _, row := range rows {
+		if row.Status != sqlc.NotificationsMessageStatusFailed {
+			result.Recovered++
+			continue
+		}
+		if err := qtx.CreateDeadLetterMessage(ctx, row.ID); err != nil {
+			return messages.RecoveryResult{}, fmt.Errorf("failed to dead-letter message %s: %w", row.ID.String(), err)
+		}
+		msg := messages.Message{
+			ID:          row.ID.String(),
+			Status:      string(row.Status),
+			CallbackURL: row.CallbackUrl.String,
+		}
+		if row.ExternalMessageID.Valid {
+			msg.ExternalMessageID = &row.ExternalMessageID.String
+		}
+		if row.LastFailureReason.Valid {
+			msg.LastFailureReason = &row.LastFailureReason.String
+		}
+		if err := r.queueStatusCallback(ctx, qtx, msg); err != nil {
+			return messages.RecoveryResult{}, err
+		}
+		result.Failed++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return messages.RecoveryResult{}, fmt.Errorf("failed to commit recovery: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateMessageStatus call sqlc generated UpdateMessageStatus for recording the outcome of a send,
+// along with the external ID and LastFailureReason if available. The update only applies
+// while the message is still 'sending' under the claim of msg.ClaimedBy, and clears its lease.
+// Returns messages.ErrLeaseLost, leaving the message untouched, if msg.ClaimedBy no longer holds the claim.
 func (r *PostgresMessageRepository) UpdateMessageStatus(ctx context.Context, msg messages.Message) error {
+	updated, err := r.queries.UpdateMessageStatus(ctx, mapDomainToUpdateStatusParams(msg))
+	if err != nil {
+		return fmt.Errorf("failed to update Message Status: %w", err)
+	}
+	if updated == 0 {
+		return messages.ErrLeaseLost
+	}
+	return nil
+}
+
+// mapDomainToUpdateStatusParams converts a messages.Message to sqlc.UpdateMessageStatusParams.
+func mapDomainToUpdateStatusParams(msg messages.Message) sqlc.UpdateMessageStatusParams {
 	updateParams := sqlc.UpdateMessageStatusParams{
-		Status: sqlc.NotificationsMessageStatus(msg.Status),
-		ID:     uuid.MustParse(msg.ID),
+		Status:    sqlc.NotificationsMessageStatus(msg.Status),
+		ID:        uuid.MustParse(msg.ID),
+		Provider:  mapDomainToText(msg.Provider),
+		ClaimedBy: pgtype.Text{String: msg.ClaimedBy, Valid: msg.ClaimedBy != ""},
 	}
 
 	if msg.ExternalMessageID != nil {
@@ -102,13 +190,428 @@ func (r *PostgresMessageRepository) UpdateMessageStatus(ctx context.Context, msg
 		updateParams.LastFailureReason = pgtype.Text{Valid: false}
 	}
 
-	err := r.queries.UpdateMessageStatus(ctx, updateParams)
+	return updateParams
+}
+
+// MoveToDeadLetter updates the message status and inserts its dead-letter record in a single transaction.
+// Returns messages.ErrLeaseLost, without dead-lettering the message, if msg.ClaimedBy no longer holds the claim.
+func (r *PostgresMessageRepository) MoveToDeadLetter(ctx context.Context, msg messages.Message) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	updated, err := qtx.UpdateMessageStatus(ctx, mapDomainToUpdateStatusParams(msg))
 	if err != nil {
 		return fmt.Errorf("failed to update Message Status: %w", err)
 	}
+	if updated == 0 {
+		return messages.ErrLeaseLost
+	}
+	if err := qtx.CreateDeadLetterMessage(ctx, uuid.MustParse(msg.ID)); err != nil {
+		return fmt.Errorf("failed to create dead-letter message: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordAttempt call sqlc generated CreateMessageAttempt for storing attempt history.
+func (r *PostgresMessageRepository) RecordAttempt(ctx context.Context, attempt messages.Attempt) error {
+	params := sqlc.CreateMessageAttemptParams{
+		MessageID:     uuid.MustParse(attempt.MessageID),
+		AttemptNumber: int32(attempt.AttemptNumber),
+		InstanceID:    attempt.InstanceID,
+		Succeeded:     attempt.Succeeded,
+		Provider:      mapDomainToText(attempt.Provider),
+	}
+	if attempt.Error != nil {
+		params.Error = pgtype.Text{String: *attempt.Error, Valid: true}
+	}
+	if attempt.ExternalMessageID != nil {
+		params.ExternalMessageID = pgtype.Text{String: *attempt.ExternalMessageID, Valid: true}
+	}
+
+	if err := r.queries.CreateMessageAttempt(ctx, params); err != nil {
+		return fmt.Errorf("failed to record message attempt: %w", err)
+	}
+	return nil
+}
+
+// GetDeadLetters call sqlc generated ListDeadLetterMessages for fetching dead-lettered messages.
+func (r *PostgresMessageRepository) GetDeadLetters(ctx context.Context, limit, offset int32) ([]messages.DeadLetter, error) {
+	rows, err := r.queries.ListDeadLetterMessages(ctx, sqlc.ListDeadLetterMessagesParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("fail to fetch dead-letter messages: %w", err)
+	}
+	var deadLetters []messages.DeadLetter
+	for _, row := range rows {
+		deadLetter := messages.DeadLetter{
+			MessageID:      row.MessageID.String(),
+			Content:        row.Content,
+			Recipient:      row.Recipient,
+			FailureReason:  row.FailureReason,
+			AttemptCount:   int(row.AttemptCount),
+			Attempts:       []messages.Attempt{},
+			DeadLetteredAt: row.DeadLetteredAt.Time,
+		}
+		if err := json.Unmarshal(row.Attempts, &deadLetter.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to decode attempt history for ID %s: %w", row.MessageID.String(), err)
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, nil
+}
+
+// RequeueDeadLetter deletes the dead-letter record and resets the message to 'pending' in a single transaction.
+func (r *PostgresMessageRepository) RequeueDeadLetter(ctx context.Context, messageID string) error {
+	id, err := uuid.Parse(messageID)
+	if err != nil {
+		return messages.ErrDeadLetterNotFound
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	deleted, err := qtx.DeleteDeadLetterMessage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete dead-letter message: %w", err)
+	}
+	if deleted == 0 {
+		return messages.ErrDeadLetterNotFound
+	}
+	if err := qtx.RequeueMessage(ctx, id); err != nil {
+		return fmt.Errorf("failed to requeue message: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ScheduleRetry call sqlc generated ScheduleMessageRetry for returning a failed message to the queue.
+// Returns messages.ErrLeaseLost if msg.ClaimedBy no longer holds the claim.
+func (r *PostgresMessageRepository) ScheduleRetry(ctx context.Context, msg messages.Message) error {
+	if msg.NextAttemptAt == nil {
+		return fmt.Errorf("message %s has no next attempt time", msg.ID)
+	}
+
+	params := sqlc.ScheduleMessageRetryParams{
+		ID:            uuid.MustParse(msg.ID),
+		NextAttemptAt: pgtype.Timestamptz{Time: *msg.NextAttemptAt, Valid: true},
+		ClaimedBy:     pgtype.Text{String: msg.ClaimedBy, Valid: msg.ClaimedBy != ""},
+	}
+	if msg.LastFailureReason != nil {
+		params.LastFailureReason = pgtype.Text{String: *msg.LastFailureReason, Valid: true}
+	}
+
+	updated, err := r.queries.ScheduleMessageRetry(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to schedule message retry: %w", err)
+	}
+	if updated == 0 {
+		return messages.ErrLeaseLost
+	}
+	return nil
+}
+
+// ReleaseMessage call sqlc generated ReleaseMessage for returning a claimed message that was not sent to the queue.
+func (r *PostgresMessageRepository) ReleaseMessage(ctx context.Context, msg messages.Message) error {
+	if msg.NextAttemptAt == nil {
+		return fmt.Errorf("message %s has no next attempt time", msg.ID)
+	}
+
+	params := sqlc.ReleaseMessageParams{
+		ID:            uuid.MustParse(msg.ID),
+		NextAttemptAt: pgtype.Timestamptz{Time: *msg.NextAttemptAt, Valid: true},
+		ClaimedBy:     pgtype.Text{String: msg.ClaimedBy, Valid: msg.ClaimedBy != ""},
+	}
+	if msg.DeferredReason != nil {
+		params.DeferredReason = pgtype.Text{String: *msg.DeferredReason, Valid: true}
+	}
+
+	updated, err := r.queries.ReleaseMessage(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to release message: %w", err)
+	}
+	if updated == 0 {
+		return messages.ErrLeaseLost
+	}
 	return nil
 }
 
+// GetMessageByID call sqlc generated GetMessageByID and ListMessageAttempts for fetching a message with its attempt history.
+func (r *PostgresMessageRepository) GetMessageByID(ctx context.Context, messageID string) (*messages.Message, error) {
+	id, err := uuid.Parse(messageID)
+	if err != nil {
+		return nil, messages.ErrMessageNotFound
+	}
+
+	dbMsg, err := r.queries.GetMessageByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, messages.ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("fail to fetch message: %w", err)
+	}
+
+	msg := &messages.Message{
+		ID:           dbMsg.ID.String(),
+		Content:      dbMsg.Content,
+		Channel:      dbMsg.Channel,
+		Subject:      dbMsg.Subject.String,
+		Recipient:    dbMsg.Recipient,
+		Status:       string(dbMsg.Status),
+		Priority:     messages.PriorityFromRank(dbMsg.Priority),
+		Timezone:     dbMsg.Timezone.String,
+		CallbackURL:  dbMsg.CallbackUrl.String,
+		AttemptCount: int(dbMsg.AttemptCount),
+		MaxAttempts:  int(dbMsg.MaxAttempts),
+		CreatedAt:    dbMsg.CreatedAt,
+		UpdatedAt:    dbMsg.UpdatedAt,
+		Provider:     dbMsg.Provider.String,
+		Attempts:     []messages.Attempt{},
+	}
+	if dbMsg.ExternalMessageID.Valid {
+		msg.ExternalMessageID = &dbMsg.ExternalMessageID.String
+	}
+	if dbMsg.LastFailureReason.Valid {
+		msg.LastFailureReason = &dbMsg.LastFailureReason.String
+	}
+	if dbMsg.DeferredReason.Valid {
+		msg.DeferredReason = &dbMsg.DeferredReason.String
+	}
+	if dbMsg.BatchID.Valid {
+		msg.BatchID = uuid.UUID(dbMsg.BatchID.Bytes).String()
+	}
+	if dbMsg.SendAt.Valid {
+		msg.SendAt = &dbMsg.SendAt.Time
+	}
+	if dbMsg.Status == sqlc.NotificationsMessageStatusPending && dbMsg.NextAttemptAt.Valid {
+		msg.NextAttemptAt = &dbMsg.NextAttemptAt.Time
+	}
+
+	attempts, err := r.queries.ListMessageAttempts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fail to fetch message attempts: %w", err)
+	}
+	for _, dbAttempt := range attempts {
+		attempt := messages.Attempt{
+			AttemptNumber: int(dbAttempt.AttemptNumber),
+			InstanceID:    dbAttempt.InstanceID,
+			Succeeded:     dbAttempt.Succeeded,
+			Provider:      dbAttempt.Provider.String,
+			AttemptedAt:   dbAttempt.AttemptedAt.Time,
+		}
+		if dbAttempt.Error.Valid {
+			attempt.Error = &dbAttempt.Error.String
+		}
+		if dbAttempt.ExternalMessageID.Valid {
+			attempt.ExternalMessageID = &dbAttempt.ExternalMessageID.String
+		}
+		msg.Attempts = append(msg.Attempts, attempt)
+	}
+
+	return msg, nil
+}
+
+// CancelMessage call sqlc generated CancelMessage for cancelling a pending message.
+// The update is conditional on the message still being 'pending', which makes it safe against concurrent claims.
+// The cancellation callback of the message is queued in the same transaction.
+func (r *PostgresMessageRepository) CancelMessage(ctx context.Context, msg messages.Message) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	cancelled, err := qtx.CancelMessage(ctx, uuid.MustParse(msg.ID))
+	if err != nil {
+		return fmt.Errorf("failed to cancel message: %w", err)
+	}
+	if cancelled == 0 {
+		return fmt.Errorf("%w, message was claimed or changed concurrently", messages.ErrNotCancellable)
+	}
+	if err := r.queueStatusCallback(ctx, qtx, msg); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit cancellation: %w", err)
+	}
+	return nil
+}
+
+// queueStatusCallback queues an event with the status of msg for its callback URL through qtx.
+// It does nothing if the message has no callback URL or the repository queues no callbacks.
+func (r *PostgresMessageRepository) queueStatusCallback(ctx context.Context, qtx *sqlc.Queries, msg messages.Message) error {
+	if r.callbackAttempts <= 0 || msg.CallbackURL == "" {
+		return nil
+	}
+	delivery, err := callbacks.NewDelivery(msg, r.callbackAttempts)
+	if err != nil {
+		return err
+	}
+	if err := createCallbackDelivery(ctx, qtx, delivery); err != nil {
+		return fmt.Errorf("failed to queue callback for message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// GetMessageIDByExternalID call sqlc generated GetMessageIDByExternalID for finding the message a provider accepted.
+func (r *PostgresMessageRepository) GetMessageIDByExternalID(ctx context.Context, provider, externalMessageID string) (string, error) {
+	id, err := r.queries.GetMessageIDByExternalID(ctx, sqlc.GetMessageIDByExternalIDParams{
+		Provider:          mapDomainToText(provider),
+		ExternalMessageID: mapDomainToText(externalMessageID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", messages.ErrMessageNotFound
+		}
+		return "", fmt.Errorf("fail to get message by external id from db: %w", err)
+	}
+	return id.String(), nil
+}
+
+// ApplyDeliveryReceipt call sqlc generated ApplyDeliveryReceipt for storing the delivery state of a sent message.
+func (r *PostgresMessageRepository) ApplyDeliveryReceipt(ctx context.Context, msg messages.Message) error {
+	params := sqlc.ApplyDeliveryReceiptParams{
+		ID:     uuid.MustParse(msg.ID),
+		Status: sqlc.NotificationsMessageStatus(msg.Status),
+	}
+	if msg.LastFailureReason != nil {
+		params.LastFailureReason = pgtype.Text{String: *msg.LastFailureReason, Valid: true}
+	}
+	applied, err := r.queries.ApplyDeliveryReceipt(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to apply delivery receipt: %w", err)
+	}
+	if applied == 0 {
+		return fmt.Errorf("%w, message changed concurrently", messages.ErrNotAwaitingReceipt)
+	}
+	return nil
+}
+
+// CancelBatch call sqlc generated CancelBatchMessages for cancelling the pending messages of a batch.
+// The cancellation callbacks of the messages are queued in the same transaction.
+func (r *PostgresMessageRepository) CancelBatch(ctx context.Context, batchID string) (int, error) {
+	id, err := uuid.Parse(batchID)
+	if err != nil {
+		return 0, messages.ErrBatchNotFound
+	}
+	dbBatchID := pgtype.UUID{Bytes: id, Valid: true}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	cancelled, err := qtx.CancelBatchMessages(ctx, dbBatchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel batch: %w", err)
+	}
+	if len(cancelled) == 0 {
+		exists, err := qtx.BatchExists(ctx, dbBatchID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check batch: %w", err)
+		}
+		if !exists {
+			return 0, messages.ErrBatchNotFound
+		}
+	}
+	for _, row := range cancelled {
+		msg := messages.Message{
+			ID:          row.ID.String(),
+			Status:      "cancelled",
+			CallbackURL: row.CallbackUrl.String,
+		}
+		if err := r.queueStatusCallback(ctx, qtx, msg); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit batch cancellation: %w", err)
+	}
+	return len(cancelled), nil
+}
+
+// ListMessages call sqlc generated ListMessages for fetching messages matching the filter.
+func (r *PostgresMessageRepository) ListMessages(ctx context.Context, filter messages.MessageFilter) ([]messages.Message, error) {
+	params := sqlc.ListMessagesParams{
+		Statuses:      filter.Statuses,
+		CreatedAfter:  mapDomainToTimestamptz(filter.CreatedAfter),
+		CreatedBefore: mapDomainToTimestamptz(filter.CreatedBefore),
+		UpdatedAfter:  mapDomainToTimestamptz(filter.UpdatedAfter),
+		UpdatedBefore: mapDomainToTimestamptz(filter.UpdatedBefore),
+		PageSize:      filter.Limit,
+	}
+	if params.Statuses == nil {
+		params.Statuses = []string{}
+	}
+	if filter.Recipient != "" {
+		params.Recipient = pgtype.Text{String: filter.Recipient, Valid: true}
+	}
+	if filter.ExternalMessageID != "" {
+		params.ExternalMessageID = pgtype.Text{String: filter.ExternalMessageID, Valid: true}
+	}
+	if filter.Cursor != nil {
+		cursorID, err := uuid.Parse(filter.Cursor.ID)
+		if err != nil {
+			return nil, messages.ErrInvalidCursor
+		}
+		params.CursorUpdatedAt = pgtype.Timestamptz{Time: filter.Cursor.UpdatedAt, Valid: true}
+		params.CursorID = pgtype.UUID{Bytes: cursorID, Valid: true}
+	}
+
+	rows, err := r.queries.ListMessages(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("fail to list messages: %w", err)
+	}
+	var msgs []messages.Message
+	for _, row := range rows {
+		msg := messages.Message{
+			ID:           row.ID.String(),
+			Content:      row.Content,
+			Channel:      row.Channel,
+			Subject:      row.Subject.String,
+			Recipient:    row.Recipient,
+			Status:       string(row.Status),
+			Priority:     messages.PriorityFromRank(row.Priority),
+			Timezone:     row.Timezone.String,
+			CallbackURL:  row.CallbackUrl.String,
+			Provider:     row.Provider.String,
+			AttemptCount: int(row.AttemptCount),
+			MaxAttempts:  int(row.MaxAttempts),
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+		}
+		if row.ExternalMessageID.Valid {
+			msg.ExternalMessageID = &row.ExternalMessageID.String
+		}
+		if row.LastFailureReason.Valid {
+			msg.LastFailureReason = &row.LastFailureReason.String
+		}
+		if row.DeferredReason.Valid {
+			msg.DeferredReason = &row.DeferredReason.String
+		}
+		if row.BatchID.Valid {
+			msg.BatchID = uuid.UUID(row.BatchID.Bytes).String()
+		}
+		if row.SendAt.Valid {
+			msg.SendAt = &row.SendAt.Time
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
 func (r *PostgresMessageRepository) GetSentMessages(ctx context.Context, limit, offset int32) ([]messages.Message, error) {
 	sentMsgs, err := r.queries.GetAllSentMessages(ctx, sqlc.GetAllSentMessagesParams{Limit: limit, Offset: offset})
 	if err != nil {
@@ -135,10 +638,23 @@ func (r *PostgresMessageRepository) CreateMessages(ctx context.Context, msgs []*
 	qtx := r.queries.WithTx(tx)
 
 	for _, msg := range msgs {
-		_, err := qtx.CreateMessage(ctx, sqlc.CreateMessageParams{
-			ID:                   uuid.MustParse(msg.ID),
-			Content:              msg.Content,
-			RecipientPhoneNumber: msg.Recipient,
+		priority, err := messages.PriorityRank(msg.Priority)
+		if err != nil {
+			return fmt.Errorf("failed to create message for recipient %s: %w", msg.Recipient, err)
+		}
+		_, err = qtx.CreateMessage(ctx, sqlc.CreateMessageParams{
+			ID:          uuid.MustParse(msg.ID),
+			Content:     msg.Content,
+			Recipient:   msg.Recipient,
+			Channel:     msg.Channel,
+			Subject:     mapDomainToText(msg.Subject),
+			Timezone:    mapDomainToText(msg.Timezone),
+			CallbackUrl: mapDomainToText(msg.CallbackURL),
+			ContentHash: contentHash(msg.Content),
+			MaxAttempts: int32(msg.MaxAttempts),
+			BatchID:     mapDomainToBatchID(msg.BatchID),
+			Priority:    priority,
+			SendAt:      mapDomainToTimestamptz(msg.SendAt),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create message for recipient %s: %w", msg.Recipient, err)
@@ -147,3 +663,51 @@ func (r *PostgresMessageRepository) CreateMessages(ctx context.Context, msgs []*
 
 	return tx.Commit(ctx)
 }
+
+// FindRecentDuplicates call sqlc generated ListRecentDuplicates for looking up messages by content hash.
+func (r *PostgresMessageRepository) FindRecentDuplicates(ctx context.Context, channel, content string, recipients []string, since time.Time) (map[string]string, error) {
+	rows, err := r.queries.ListRecentDuplicates(ctx, sqlc.ListRecentDuplicatesParams{
+		Recipients:  recipients,
+		ContentHash: contentHash(content),
+		Channel:     channel,
+		Since:       pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to find duplicate messages: %w", err)
+	}
+	duplicates := make(map[string]string, len(rows))
+	for _, row := range rows {
+		duplicates[row.Recipient] = row.ID.String()
+	}
+	return duplicates, nil
+}
+
+// contentHash returns the SHA-256 digest stored with a message, matching sha256() in Postgres.
+func contentHash(content string) []byte {
+	sum := sha256.Sum256([]byte(content))
+	return sum[:]
+}
+
+// mapDomainToBatchID converts an optional batch ID to a nullable pgtype.UUID.
+func mapDomainToBatchID(batchID string) pgtype.UUID {
+	if batchID == "" {
+		return pgtype.UUID{Valid: false}
+	}
+	return pgtype.UUID{Bytes: uuid.MustParse(batchID), Valid: true}
+}
+
+// mapDomainToText converts an optional string to a nullable pgtype.Text.
+func mapDomainToText(s string) pgtype.Text {
+	if s == "" {
+		return pgtype.Text{Valid: false}
+	}
+	return pgtype.Text{String: s, Valid: true}
+}
+
+// mapDomainToTimestamptz converts an optional time to a nullable pgtype.Timestamptz.
+func mapDomainToTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{Valid: false}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
@@ -0,0 +1,630 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/config"
+	"github.com/akshaysangma/go-notify/internal/database/postgres"
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestPool connects to the database pointed to by TEST_DATABASE_URL.
+// The database must have all migrations applied and must be disposable, as tests wipe the messages table.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	pool, err := postgres.NewPostgresDB(ctx, config.DatabaseConfig{ConnectionString: connString, MaxConns: 20})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	resetMessages(t, pool)
+	return pool
+}
+
+func resetMessages(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.messages")
+	require.NoError(t, err)
+}
+
+func seedPendingMessages(t *testing.T, repo *PostgresMessageRepository, count int) []*messages.Message {
+	t.Helper()
+	var msgs []*messages.Message
+	for i := 0; i < count; i++ {
+		msg, err := messages.NewMessage(fmt.Sprintf("message %d", i), fmt.Sprintf("+1555000%04d", i), 250)
+		require.NoError(t, err)
+		msgs = append(msgs, msg)
+	}
+	require.NoError(t, repo.CreateMessages(context.Background(), msgs))
+	return msgs
+}
+
+// countingSender records how many times each message content was sent.
+type countingSender struct {
+	mu    sync.Mutex
+	sends map[string]int
+}
+
+func (c *countingSender) Send(ctx context.Context, to, content string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sends[content]++
+	return "ext-" + to, nil
+}
+
+type noopCache struct{}
+
+func (noopCache) CacheSentMessage(ctx context.Context, messageID, externalMessageID string, sentAt time.Time) error {
+	return nil
+}
+
+func TestPostgresMessageRepository_ClaimPendingMessages_Concurrent(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+
+	const total = 100
+	seedPendingMessages(t, repo, total)
+
+	var (
+		mu      sync.Mutex
+		claimed = make(map[string]int)
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(instanceID string) {
+			defer wg.Done()
+			for {
+				batch, err := repo.ClaimPendingMessages(context.Background(), instanceID, time.Minute, 7, 0)
+				if !assert.NoError(t, err) || len(batch) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, msg := range batch {
+					assert.Equal(t, "sending", msg.Status)
+					claimed[msg.ID]++
+				}
+				mu.Unlock()
+			}
+		}(fmt.Sprintf("instance-%d", i))
+	}
+	wg.Wait()
+
+	assert.Len(t, claimed, total)
+	for id, count := range claimed {
+		assert.Equal(t, 1, count, "message %s claimed more than once", id)
+	}
+}
+
+func TestMessageService_FetchAndSendPending_MultipleInstances(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+
+	const total = 60
+	seeded := seedPendingMessages(t, repo, total)
+
+	sender := &countingSender{sends: make(map[string]int)}
+	senders := messages.NewSenderRegistry()
+	senders.Register(messages.ChannelSMS, sender)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		service := messages.NewMessageService(repo, senders, zap.NewNop(), noopCache{}, 4, 5*time.Second, fmt.Sprintf("instance-%d", i), time.Minute, messages.RetryPolicy{}, 0, 0, nil, nil, messages.RecipientPolicy{}, nil, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < total; j++ {
+				_, err := service.FetchAndSendPending(context.Background(), 5)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, sender.sends, total)
+	for _, msg := range seeded {
+		assert.Equal(t, 1, sender.sends[msg.Content], "message %s sent %d times", msg.ID, sender.sends[msg.Content])
+	}
+
+	var sentCount int
+	err = pool.QueryRow(context.Background(), "SELECT count(*) FROM notifications.messages WHERE status = 'sent'").Scan(&sentCount)
+	require.NoError(t, err)
+	assert.Equal(t, total, sentCount)
+}
+
+func TestPostgresMessageRepository_RecoverExpiredMessages(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seedPendingMessages(t, repo, 3)
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "crashed-instance", time.Millisecond, 3, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	time.Sleep(10 * time.Millisecond)
+
+	// First sweep returns every expired message to pending.
+	result, err := repo.RecoverExpiredMessages(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, messages.RecoveryResult{Recovered: 3}, result)
+
+	// Messages with a live lease are left alone.
+	_, err = repo.ClaimPendingMessages(ctx, "live-instance", time.Hour, 1, 0)
+	require.NoError(t, err)
+	result, err = repo.RecoverExpiredMessages(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, messages.RecoveryResult{}, result)
+
+	// Once the recovery budget is spent the message is failed instead.
+	_, err = repo.ClaimPendingMessages(ctx, "crashed-instance", time.Millisecond, 2, 0)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	result, err = repo.RecoverExpiredMessages(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, messages.RecoveryResult{Failed: 2}, result)
+}
+
+func TestPostgresMessageRepository_LeaseLost(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+	seeded := seedPendingMessages(t, repo, 1)
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "instance-1", claimed[0].ClaimedBy)
+
+	// Another instance cannot store the outcome of a message it does not hold the claim on.
+	stale := claimed[0]
+	stale.ClaimedBy = "instance-2"
+	sent := stale
+	sent.MarkAsSent("ext-1")
+	assert.ErrorIs(t, repo.UpdateMessageStatus(ctx, sent), messages.ErrLeaseLost)
+	retried := stale
+	retried.MarkForRetry("status 503", time.Now().Add(time.Minute))
+	assert.ErrorIs(t, repo.ScheduleRetry(ctx, retried), messages.ErrLeaseLost)
+	released := stale
+	released.Release("circuit breaker is open", time.Now().Add(time.Minute))
+	assert.ErrorIs(t, repo.ReleaseMessage(ctx, released), messages.ErrLeaseLost)
+	failed := stale
+	failed.MarkAsFailed("status 400")
+	assert.ErrorIs(t, repo.MoveToDeadLetter(ctx, failed), messages.ErrLeaseLost)
+
+	fetched, err := repo.GetMessageByID(ctx, seeded[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "sending", fetched.Status)
+	deadLetters, err := repo.GetDeadLetters(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
+
+func TestPostgresMessageRepository_DeadLetterLifecycle(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seeded := seedPendingMessages(t, repo, 1)
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	msg := claimed[0]
+
+	sendErr := "webhook responded with non-202 status code: 503"
+	require.NoError(t, repo.RecordAttempt(ctx, messages.Attempt{
+		MessageID:     msg.ID,
+		AttemptNumber: msg.AttemptCount,
+		InstanceID:    "instance-1",
+		Error:         &sendErr,
+	}))
+	msg.MarkAsFailed(sendErr)
+	require.NoError(t, repo.MoveToDeadLetter(ctx, msg))
+
+	deadLetters, err := repo.GetDeadLetters(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, seeded[0].ID, deadLetters[0].MessageID)
+	assert.Equal(t, sendErr, deadLetters[0].FailureReason)
+	require.Len(t, deadLetters[0].Attempts, 1)
+	assert.Equal(t, "instance-1", deadLetters[0].Attempts[0].InstanceID)
+	assert.False(t, deadLetters[0].Attempts[0].Succeeded)
+
+	require.NoError(t, repo.RequeueDeadLetter(ctx, msg.ID))
+	assert.ErrorIs(t, repo.RequeueDeadLetter(ctx, msg.ID), messages.ErrDeadLetterNotFound)
+
+	requeued, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, requeued, 1)
+	assert.Equal(t, 1, requeued[0].AttemptCount)
+}
+
+func TestPostgresMessageRepository_GetMessageByID(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seeded := seedPendingMessages(t, repo, 1)
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	msg := claimed[0]
+
+	externalID := "ext-1"
+	require.NoError(t, repo.RecordAttempt(ctx, messages.Attempt{
+		MessageID:         msg.ID,
+		AttemptNumber:     msg.AttemptCount,
+		InstanceID:        "instance-1",
+		Succeeded:         true,
+		ExternalMessageID: &externalID,
+		Provider:          "backup",
+	}))
+	msg.MarkAsSent(externalID)
+	msg.Provider = "backup"
+	require.NoError(t, repo.UpdateMessageStatus(ctx, msg))
+
+	fetched, err := repo.GetMessageByID(ctx, seeded[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "sent", fetched.Status)
+	assert.Equal(t, seeded[0].Content, fetched.Content)
+	require.NotNil(t, fetched.ExternalMessageID)
+	assert.Equal(t, externalID, *fetched.ExternalMessageID)
+	assert.Equal(t, "backup", fetched.Provider)
+	require.Len(t, fetched.Attempts, 1)
+	assert.True(t, fetched.Attempts[0].Succeeded)
+	assert.Equal(t, "backup", fetched.Attempts[0].Provider)
+
+	_, err = repo.GetMessageByID(ctx, "a1b2c3d4-e5f6-7890-1234-567890abcdef")
+	assert.ErrorIs(t, err, messages.ErrMessageNotFound)
+}
+
+func TestPostgresMessageRepository_ListMessages(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seeded := seedPendingMessages(t, repo, 5)
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+
+	// Page through all messages two at a time.
+	seen := make(map[string]bool)
+	filter := messages.MessageFilter{Limit: 2}
+	for {
+		page, err := repo.ListMessages(ctx, filter)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, msg := range page {
+			assert.False(t, seen[msg.ID], "message %s returned twice", msg.ID)
+			seen[msg.ID] = true
+		}
+		last := page[len(page)-1]
+		filter.Cursor = &messages.Cursor{UpdatedAt: last.UpdatedAt, ID: last.ID}
+	}
+	assert.Len(t, seen, len(seeded))
+
+	sending, err := repo.ListMessages(ctx, messages.MessageFilter{Statuses: []string{"sending"}, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, sending, 2)
+
+	byRecipient, err := repo.ListMessages(ctx, messages.MessageFilter{Recipient: seeded[0].Recipient, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, byRecipient, 1)
+	assert.Equal(t, seeded[0].ID, byRecipient[0].ID)
+
+	future := time.Now().Add(time.Hour)
+	none, err := repo.ListMessages(ctx, messages.MessageFilter{CreatedAfter: &future, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestPostgresMessageRepository_ClaimPendingMessages_SendAt(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	due, err := messages.NewMessage("due", "+15550000001", 250)
+	require.NoError(t, err)
+	scheduled, err := messages.NewMessage("scheduled", "+15550000002", 250)
+	require.NoError(t, err)
+	require.NoError(t, scheduled.ScheduleAt(time.Now().Add(time.Hour), time.Now(), 0))
+	require.NoError(t, repo.CreateMessages(ctx, []*messages.Message{due, scheduled}))
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+
+	fetched, err := repo.GetMessageByID(ctx, scheduled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", fetched.Status)
+	require.NotNil(t, fetched.SendAt)
+	assert.WithinDuration(t, *scheduled.SendAt, *fetched.SendAt, time.Millisecond)
+}
+
+func TestPostgresMessageRepository_ReleaseMessage(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+	seeded := seedPendingMessages(t, repo, 1)
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, 1, claimed[0].AttemptCount)
+
+	msg := claimed[0]
+	msg.Release("sms send deferred: circuit breaker is open", time.Now().Add(time.Hour))
+	require.NoError(t, repo.ReleaseMessage(ctx, msg))
+
+	fetched, err := repo.GetMessageByID(ctx, seeded[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", fetched.Status)
+	assert.Equal(t, 0, fetched.AttemptCount)
+	require.NotNil(t, fetched.DeferredReason)
+	assert.Equal(t, "sms send deferred: circuit breaker is open", *fetched.DeferredReason)
+	assert.Nil(t, fetched.LastFailureReason)
+	require.NotNil(t, fetched.NextAttemptAt)
+
+	// The message is not claimed again before its next attempt time.
+	none, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestPostgresMessageRepository_ClaimPendingMessages_Priority(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Each CreateMessages call is its own transaction, so the bulk messages are strictly older.
+	newMessages := func(priority string, n int) {
+		var msgs []*messages.Message
+		for i := 0; i < n; i++ {
+			msg, err := messages.NewMessage(priority, fmt.Sprintf("+1555%07d", i), 250)
+			require.NoError(t, err)
+			msg.Priority = priority
+			msgs = append(msgs, msg)
+		}
+		require.NoError(t, repo.CreateMessages(ctx, msgs))
+	}
+	newMessages(messages.PriorityBulk, 3)
+	newMessages(messages.PriorityCritical, 3)
+
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 3, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+
+	// Two slots go to critical messages and the reserved one to the oldest message, a bulk one.
+	counts := map[string]int{}
+	for _, msg := range claimed {
+		counts[msg.Priority]++
+	}
+	assert.Equal(t, 2, counts[messages.PriorityCritical])
+	assert.Equal(t, 1, counts[messages.PriorityBulk])
+}
+
+func TestPostgresMessageRepository_CreateMessages_Channel(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	msg, err := messages.NewMessage("deploy finished", "#deployments-and-releases", 250)
+	require.NoError(t, err)
+	msg.Channel = messages.ChannelChat
+	// Only sms content is limited to 250 characters.
+	report := "<p>" + strings.Repeat("Your weekly report is ready. ", 20) + "</p>"
+	email, err := messages.NewMessage(report, "jane.doe@example.org", 0)
+	require.NoError(t, err)
+	email.Channel = messages.ChannelEmail
+	email.Subject = "Weekly report"
+	email.Timezone = "Europe/Berlin"
+	require.NoError(t, repo.CreateMessages(ctx, []*messages.Message{msg, email}))
+
+	fetched, err := repo.GetMessageByID(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, messages.ChannelChat, fetched.Channel)
+	assert.Equal(t, msg.Recipient, fetched.Recipient)
+	assert.Empty(t, fetched.Subject)
+
+	fetched, err = repo.GetMessageByID(ctx, email.ID)
+	require.NoError(t, err)
+	assert.Equal(t, messages.ChannelEmail, fetched.Channel)
+	assert.Equal(t, "Weekly report", fetched.Subject)
+	assert.Equal(t, "Europe/Berlin", fetched.Timezone)
+	assert.Equal(t, report, fetched.Content)
+
+	sms, err := messages.NewMessage(report, "+15550000001", 0)
+	require.NoError(t, err)
+	assert.Error(t, repo.CreateMessages(ctx, []*messages.Message{sms}))
+}
+
+func TestPostgresMessageRepository_CancelMessage_ConcurrentClaim(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	const total = 50
+	seeded := seedPendingMessages(t, repo, total)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		claimed   = make(map[string]bool)
+		cancelled = make(map[string]bool)
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for {
+			batch, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 3, 0)
+			if !assert.NoError(t, err) || len(batch) == 0 {
+				return
+			}
+			mu.Lock()
+			for _, msg := range batch {
+				claimed[msg.ID] = true
+			}
+			mu.Unlock()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, msg := range seeded {
+			msg.Status = "cancelled"
+			err := repo.CancelMessage(ctx, *msg)
+			if err == nil {
+				mu.Lock()
+				cancelled[msg.ID] = true
+				mu.Unlock()
+				continue
+			}
+			assert.ErrorIs(t, err, messages.ErrNotCancellable)
+		}
+	}()
+	wg.Wait()
+
+	// Every message was either claimed or cancelled, never both.
+	assert.Equal(t, total, len(claimed)+len(cancelled))
+	for id := range cancelled {
+		assert.False(t, claimed[id], "message %s was claimed and cancelled", id)
+		fetched, err := repo.GetMessageByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", fetched.Status)
+	}
+}
+
+func TestPostgresMessageRepository_CancelBatch(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	batchID := "f0e1d2c3-b4a5-6789-0123-456789abcdef"
+	var msgs []*messages.Message
+	for i := 0; i < 3; i++ {
+		msg, err := messages.NewMessage("campaign", fmt.Sprintf("+1555000%04d", i), 250)
+		require.NoError(t, err)
+		msg.BatchID = batchID
+		msgs = append(msgs, msg)
+	}
+	require.NoError(t, repo.CreateMessages(ctx, msgs))
+	_, err = repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+
+	cancelled, err := repo.CancelBatch(ctx, batchID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cancelled)
+
+	cancelled, err = repo.CancelBatch(ctx, batchID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cancelled)
+
+	_, err = repo.CancelBatch(ctx, "a1b2c3d4-e5f6-7890-1234-567890abcdef")
+	assert.ErrorIs(t, err, messages.ErrBatchNotFound)
+}
+
+func TestPostgresMessageRepository_FindRecentDuplicates(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	recent, err := messages.NewMessage("your code is 1234", "+15550000001", 250)
+	require.NoError(t, err)
+	cancelled, err := messages.NewMessage("your code is 1234", "+15550000002", 250)
+	require.NoError(t, err)
+	other, err := messages.NewMessage("your code is 9999", "+15550000003", 250)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateMessages(ctx, []*messages.Message{recent, cancelled, other}))
+	require.NoError(t, repo.CancelMessage(ctx, *cancelled))
+
+	recipients := []string{"+15550000001", "+15550000002", "+15550000003"}
+	duplicates, err := repo.FindRecentDuplicates(ctx, messages.ChannelSMS, "your code is 1234", recipients, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"+15550000001": recent.ID}, duplicates)
+
+	// Messages created before the window are not duplicates.
+	duplicates, err = repo.FindRecentDuplicates(ctx, messages.ChannelSMS, "your code is 1234", recipients, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+
+	// Nor are messages on another channel.
+	duplicates, err = repo.FindRecentDuplicates(ctx, messages.ChannelChat, "your code is 1234", recipients, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+}
+
+func TestPostgresMessageRepository_ApplyDeliveryReceipt(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewPostgresMessageRepository(pool, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seeded := seedPendingMessages(t, repo, 1)
+	claimed, err := repo.ClaimPendingMessages(ctx, "instance-1", time.Minute, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	msg := claimed[0]
+	msg.MarkAsSent("ext-1")
+	msg.Provider = "primary"
+	require.NoError(t, repo.UpdateMessageStatus(ctx, msg))
+
+	// External IDs are only unique per provider.
+	_, err = repo.GetMessageIDByExternalID(ctx, "backup", "ext-1")
+	assert.ErrorIs(t, err, messages.ErrMessageNotFound)
+	messageID, err := repo.GetMessageIDByExternalID(ctx, "primary", "ext-1")
+	require.NoError(t, err)
+	assert.Equal(t, seeded[0].ID, messageID)
+
+	require.NoError(t, msg.ApplyReceipt(messages.DeliveryReceipt{Status: messages.ReceiptUndelivered, Reason: "handset off"}))
+	require.NoError(t, repo.ApplyDeliveryReceipt(ctx, msg))
+	fetched, err := repo.GetMessageByID(ctx, messageID)
+	require.NoError(t, err)
+	assert.Equal(t, messages.ReceiptUndelivered, fetched.Status)
+	require.NotNil(t, fetched.LastFailureReason)
+	assert.Equal(t, "handset off", *fetched.LastFailureReason)
+
+	// A second receipt does not overwrite the first.
+	msg.Status = "sent"
+	require.NoError(t, msg.ApplyReceipt(messages.DeliveryReceipt{Status: messages.ReceiptDelivered}))
+	assert.ErrorIs(t, repo.ApplyDeliveryReceipt(ctx, msg), messages.ErrNotAwaitingReceipt)
+
+	// Messages with a receipt are still listed as sent.
+	sent, err := repo.GetSentMessages(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, messages.ReceiptUndelivered, sent[0].Status)
+}
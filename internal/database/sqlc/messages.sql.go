@@ -13,26 +13,269 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const applyDeliveryReceipt = `-- name: ApplyDeliveryReceipt :execrows
+UPDATE notifications.messages
+SET
+    status = $2,
+    last_failure_reason = $3,
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'sent'
+`
+
+type ApplyDeliveryReceiptParams struct {
+	ID                uuid.UUID                  `json:"id"`
+	Status            NotificationsMessageStatus `json:"status"`
+	LastFailureReason pgtype.Text                `json:"last_failure_reason"`
+}
+
+// Only sent messages take a receipt, so a late or repeated receipt cannot overwrite the first one.
+func (q *Queries) ApplyDeliveryReceipt(ctx context.Context, arg ApplyDeliveryReceiptParams) (int64, error) {
+	result, err := q.db.Exec(ctx, applyDeliveryReceipt, arg.ID, arg.Status, arg.LastFailureReason)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const batchExists = `-- name: BatchExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM notifications.messages
+    WHERE batch_id = $1
+)
+`
+
+func (q *Queries) BatchExists(ctx context.Context, batchID pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, batchExists, batchID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const cancelBatchMessages = `-- name: CancelBatchMessages :many
+UPDATE notifications.messages
+SET
+    status = 'cancelled',
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'pending'
+RETURNING
+    id,
+    callback_url
+`
+
+type CancelBatchMessagesRow struct {
+	ID          uuid.UUID   `json:"id"`
+	CallbackUrl pgtype.Text `json:"callback_url"`
+}
+
+func (q *Queries) CancelBatchMessages(ctx context.Context, batchID pgtype.UUID) ([]CancelBatchMessagesRow, error) {
+	rows, err := q.db.Query(ctx, cancelBatchMessages, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CancelBatchMessagesRow{}
+	for rows.Next() {
+		var i CancelBatchMessagesRow
+		if err := rows.Scan(&i.ID, &i.CallbackUrl); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const cancelMessage = `-- name: CancelMessage :execrows
+UPDATE notifications.messages
+SET
+    status = 'cancelled',
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'pending'
+`
+
+// Only pending messages are cancelled. A row locked by a concurrent claim is re-checked once the
+// claim commits, so a message that moved to 'sending' is left alone.
+func (q *Queries) CancelMessage(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelMessage, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimPendingMessages = `-- name: ClaimPendingMessages :many
+WITH prioritized AS (
+    SELECT id
+    FROM notifications.messages
+    WHERE status = 'pending'
+      AND send_at <= NOW()
+      AND next_attempt_at <= NOW()
+    ORDER BY priority ASC, created_at ASC
+    LIMIT $1::int - $2::int
+    FOR UPDATE SKIP LOCKED
+), reserved AS (
+    SELECT id
+    FROM notifications.messages
+    WHERE status = 'pending'
+      AND send_at <= NOW()
+      AND next_attempt_at <= NOW()
+      AND id NOT IN (SELECT id FROM prioritized)
+    ORDER BY created_at ASC
+    LIMIT $2::int
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE notifications.messages
+SET
+    status = 'sending',
+    claimed_by = $3::text,
+    lease_expires_at = NOW() + $4::interval,
+    attempt_count = attempt_count + 1,
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM prioritized
+    UNION ALL
+    SELECT id FROM reserved
+)
+RETURNING
+    id,
+    content,
+    recipient,
+    channel,
+    subject,
+    timezone,
+    callback_url,
+    status,
+    external_message_id,
+    attempt_count,
+    max_attempts,
+    priority,
+    created_at,
+    updated_at
+`
+
+type ClaimPendingMessagesParams struct {
+	BatchSize     int32           `json:"batch_size"`
+	ReservedSize  int32           `json:"reserved_size"`
+	ClaimedBy     string          `json:"claimed_by"`
+	LeaseDuration pgtype.Interval `json:"lease_duration"`
+}
+
+type ClaimPendingMessagesRow struct {
+	ID                uuid.UUID                  `json:"id"`
+	Content           string                     `json:"content"`
+	Recipient         string                     `json:"recipient"`
+	Channel           string                     `json:"channel"`
+	Subject           pgtype.Text                `json:"subject"`
+	Timezone          pgtype.Text                `json:"timezone"`
+	CallbackUrl       pgtype.Text                `json:"callback_url"`
+	Status            NotificationsMessageStatus `json:"status"`
+	ExternalMessageID pgtype.Text                `json:"external_message_id"`
+	AttemptCount      int32                      `json:"attempt_count"`
+	MaxAttempts       int32                      `json:"max_attempts"`
+	Priority          int16                      `json:"priority"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+}
+
+// Most of the batch goes to the most urgent messages, oldest first. The last reserved_size slots
+// go to the oldest of the remaining messages whatever their priority, so bulk traffic is never starved.
+func (q *Queries) ClaimPendingMessages(ctx context.Context, arg ClaimPendingMessagesParams) ([]ClaimPendingMessagesRow, error) {
+	rows, err := q.db.Query(ctx, claimPendingMessages,
+		arg.BatchSize,
+		arg.ReservedSize,
+		arg.ClaimedBy,
+		arg.LeaseDuration,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimPendingMessagesRow{}
+	for rows.Next() {
+		var i ClaimPendingMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.Recipient,
+			&i.Channel,
+			&i.Subject,
+			&i.Timezone,
+			&i.CallbackUrl,
+			&i.Status,
+			&i.ExternalMessageID,
+			&i.AttemptCount,
+			&i.MaxAttempts,
+			&i.Priority,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createMessage = `-- name: CreateMessage :one
 INSERT INTO notifications.messages (
     id,
     content,
-    recipient_phone_number,
-    status
+    recipient,
+    channel,
+    status,
+    max_attempts,
+    batch_id,
+    priority,
+    subject,
+    timezone,
+    callback_url,
+    content_hash,
+    send_at
 ) VALUES (
-    $1, $2, $3, 'pending'
+    $1, $2, $3, $4, 'pending', $5, $6, $7, $8, $9, $10, $11, COALESCE($12::timestamptz, NOW())
 )
 RETURNING id
 `
 
 type CreateMessageParams struct {
-	ID                   uuid.UUID `json:"id"`
-	Content              string    `json:"content"`
-	RecipientPhoneNumber string    `json:"recipient_phone_number"`
+	ID          uuid.UUID          `json:"id"`
+	Content     string             `json:"content"`
+	Recipient   string             `json:"recipient"`
+	Channel     string             `json:"channel"`
+	MaxAttempts int32              `json:"max_attempts"`
+	BatchID     pgtype.UUID        `json:"batch_id"`
+	Priority    int16              `json:"priority"`
+	Subject     pgtype.Text        `json:"subject"`
+	Timezone    pgtype.Text        `json:"timezone"`
+	CallbackUrl pgtype.Text        `json:"callback_url"`
+	ContentHash []byte             `json:"content_hash"`
+	SendAt      pgtype.Timestamptz `json:"send_at"`
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, createMessage, arg.ID, arg.Content, arg.RecipientPhoneNumber)
+	row := q.db.QueryRow(ctx, createMessage,
+		arg.ID,
+		arg.Content,
+		arg.Recipient,
+		arg.Channel,
+		arg.MaxAttempts,
+		arg.BatchID,
+		arg.Priority,
+		arg.Subject,
+		arg.Timezone,
+		arg.CallbackUrl,
+		arg.ContentHash,
+		arg.SendAt,
+	)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
@@ -42,13 +285,13 @@ const getAllSentMessages = `-- name: GetAllSentMessages :many
 SELECT
     id,
     content,
-    recipient_phone_number,
+    recipient,
     status,
     external_message_id,
     created_at,
     updated_at
 FROM notifications.messages
-WHERE status = 'sent'
+WHERE status IN ('sent', 'delivered', 'undelivered')
 ORDER BY updated_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -59,13 +302,13 @@ type GetAllSentMessagesParams struct {
 }
 
 type GetAllSentMessagesRow struct {
-	ID                   uuid.UUID                  `json:"id"`
-	Content              string                     `json:"content"`
-	RecipientPhoneNumber string                     `json:"recipient_phone_number"`
-	Status               NotificationsMessageStatus `json:"status"`
-	ExternalMessageID    pgtype.Text                `json:"external_message_id"`
-	CreatedAt            time.Time                  `json:"created_at"`
-	UpdatedAt            time.Time                  `json:"updated_at"`
+	ID                uuid.UUID                  `json:"id"`
+	Content           string                     `json:"content"`
+	Recipient         string                     `json:"recipient"`
+	Status            NotificationsMessageStatus `json:"status"`
+	ExternalMessageID pgtype.Text                `json:"external_message_id"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
 }
 
 func (q *Queries) GetAllSentMessages(ctx context.Context, arg GetAllSentMessagesParams) ([]GetAllSentMessagesRow, error) {
@@ -80,7 +323,7 @@ func (q *Queries) GetAllSentMessages(ctx context.Context, arg GetAllSentMessages
 		if err := rows.Scan(
 			&i.ID,
 			&i.Content,
-			&i.RecipientPhoneNumber,
+			&i.Recipient,
 			&i.Status,
 			&i.ExternalMessageID,
 			&i.CreatedAt,
@@ -96,46 +339,212 @@ func (q *Queries) GetAllSentMessages(ctx context.Context, arg GetAllSentMessages
 	return items, nil
 }
 
-const getPendingMessages = `-- name: GetPendingMessages :many
+const getMessageByID = `-- name: GetMessageByID :one
 SELECT
     id,
     content,
-    recipient_phone_number,
+    recipient,
+    channel,
+    subject,
+    timezone,
+    callback_url,
     status,
     external_message_id,
+    provider,
+    last_failure_reason,
+    deferred_reason,
+    attempt_count,
+    max_attempts,
+    priority,
+    next_attempt_at,
+    batch_id,
+    send_at,
     created_at,
     updated_at
 FROM notifications.messages
-WHERE status = 'pending'
-ORDER BY created_at ASC
-LIMIT $1
+WHERE id = $1
+`
+
+type GetMessageByIDRow struct {
+	ID                uuid.UUID                  `json:"id"`
+	Content           string                     `json:"content"`
+	Recipient         string                     `json:"recipient"`
+	Channel           string                     `json:"channel"`
+	Subject           pgtype.Text                `json:"subject"`
+	Timezone          pgtype.Text                `json:"timezone"`
+	CallbackUrl       pgtype.Text                `json:"callback_url"`
+	Status            NotificationsMessageStatus `json:"status"`
+	ExternalMessageID pgtype.Text                `json:"external_message_id"`
+	Provider          pgtype.Text                `json:"provider"`
+	LastFailureReason pgtype.Text                `json:"last_failure_reason"`
+	DeferredReason    pgtype.Text                `json:"deferred_reason"`
+	AttemptCount      int32                      `json:"attempt_count"`
+	MaxAttempts       int32                      `json:"max_attempts"`
+	Priority          int16                      `json:"priority"`
+	NextAttemptAt     pgtype.Timestamptz         `json:"next_attempt_at"`
+	BatchID           pgtype.UUID                `json:"batch_id"`
+	SendAt            pgtype.Timestamptz         `json:"send_at"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+}
+
+func (q *Queries) GetMessageByID(ctx context.Context, id uuid.UUID) (GetMessageByIDRow, error) {
+	row := q.db.QueryRow(ctx, getMessageByID, id)
+	var i GetMessageByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.Recipient,
+		&i.Channel,
+		&i.Subject,
+		&i.Timezone,
+		&i.CallbackUrl,
+		&i.Status,
+		&i.ExternalMessageID,
+		&i.Provider,
+		&i.LastFailureReason,
+		&i.DeferredReason,
+		&i.AttemptCount,
+		&i.MaxAttempts,
+		&i.Priority,
+		&i.NextAttemptAt,
+		&i.BatchID,
+		&i.SendAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMessageIDByExternalID = `-- name: GetMessageIDByExternalID :one
+SELECT id
+FROM notifications.messages
+WHERE provider = $1
+  AND external_message_id = $2
+ORDER BY created_at DESC
+LIMIT 1
 `
 
-type GetPendingMessagesRow struct {
-	ID                   uuid.UUID                  `json:"id"`
-	Content              string                     `json:"content"`
-	RecipientPhoneNumber string                     `json:"recipient_phone_number"`
-	Status               NotificationsMessageStatus `json:"status"`
-	ExternalMessageID    pgtype.Text                `json:"external_message_id"`
-	CreatedAt            time.Time                  `json:"created_at"`
-	UpdatedAt            time.Time                  `json:"updated_at"`
+type GetMessageIDByExternalIDParams struct {
+	Provider          pgtype.Text `json:"provider"`
+	ExternalMessageID pgtype.Text `json:"external_message_id"`
 }
 
-func (q *Queries) GetPendingMessages(ctx context.Context, limit int32) ([]GetPendingMessagesRow, error) {
-	rows, err := q.db.Query(ctx, getPendingMessages, limit)
+func (q *Queries) GetMessageIDByExternalID(ctx context.Context, arg GetMessageIDByExternalIDParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getMessageIDByExternalID, arg.Provider, arg.ExternalMessageID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const listMessages = `-- name: ListMessages :many
+SELECT
+    id,
+    content,
+    recipient,
+    channel,
+    subject,
+    timezone,
+    callback_url,
+    status,
+    external_message_id,
+    provider,
+    last_failure_reason,
+    deferred_reason,
+    attempt_count,
+    max_attempts,
+    priority,
+    batch_id,
+    send_at,
+    created_at,
+    updated_at
+FROM notifications.messages
+WHERE (cardinality($1::text[]) = 0 OR status::text = ANY($1::text[]))
+  AND ($2::text IS NULL OR recipient = $2::text)
+  AND ($3::text IS NULL OR external_message_id = $3::text)
+  AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR created_at < $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR updated_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR updated_at < $7::timestamptz)
+  AND ($8::timestamptz IS NULL OR (updated_at, id) < ($8::timestamptz, $9::uuid))
+ORDER BY updated_at DESC, id DESC
+LIMIT $10
+`
+
+type ListMessagesParams struct {
+	Statuses          []string           `json:"statuses"`
+	Recipient         pgtype.Text        `json:"recipient"`
+	ExternalMessageID pgtype.Text        `json:"external_message_id"`
+	CreatedAfter      pgtype.Timestamptz `json:"created_after"`
+	CreatedBefore     pgtype.Timestamptz `json:"created_before"`
+	UpdatedAfter      pgtype.Timestamptz `json:"updated_after"`
+	UpdatedBefore     pgtype.Timestamptz `json:"updated_before"`
+	CursorUpdatedAt   pgtype.Timestamptz `json:"cursor_updated_at"`
+	CursorID          pgtype.UUID        `json:"cursor_id"`
+	PageSize          int32              `json:"page_size"`
+}
+
+type ListMessagesRow struct {
+	ID                uuid.UUID                  `json:"id"`
+	Content           string                     `json:"content"`
+	Recipient         string                     `json:"recipient"`
+	Channel           string                     `json:"channel"`
+	Subject           pgtype.Text                `json:"subject"`
+	Timezone          pgtype.Text                `json:"timezone"`
+	CallbackUrl       pgtype.Text                `json:"callback_url"`
+	Status            NotificationsMessageStatus `json:"status"`
+	ExternalMessageID pgtype.Text                `json:"external_message_id"`
+	Provider          pgtype.Text                `json:"provider"`
+	LastFailureReason pgtype.Text                `json:"last_failure_reason"`
+	DeferredReason    pgtype.Text                `json:"deferred_reason"`
+	AttemptCount      int32                      `json:"attempt_count"`
+	MaxAttempts       int32                      `json:"max_attempts"`
+	Priority          int16                      `json:"priority"`
+	BatchID           pgtype.UUID                `json:"batch_id"`
+	SendAt            pgtype.Timestamptz         `json:"send_at"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+}
+
+// Keyset paginated on (updated_at, id); pass the last row of the previous page as the cursor.
+func (q *Queries) ListMessages(ctx context.Context, arg ListMessagesParams) ([]ListMessagesRow, error) {
+	rows, err := q.db.Query(ctx, listMessages,
+		arg.Statuses,
+		arg.Recipient,
+		arg.ExternalMessageID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CursorUpdatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetPendingMessagesRow{}
+	items := []ListMessagesRow{}
 	for rows.Next() {
-		var i GetPendingMessagesRow
+		var i ListMessagesRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Content,
-			&i.RecipientPhoneNumber,
+			&i.Recipient,
+			&i.Channel,
+			&i.Subject,
+			&i.Timezone,
+			&i.CallbackUrl,
 			&i.Status,
 			&i.ExternalMessageID,
+			&i.Provider,
+			&i.LastFailureReason,
+			&i.DeferredReason,
+			&i.AttemptCount,
+			&i.MaxAttempts,
+			&i.Priority,
+			&i.BatchID,
+			&i.SendAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -149,14 +558,214 @@ func (q *Queries) GetPendingMessages(ctx context.Context, limit int32) ([]GetPen
 	return items, nil
 }
 
-const updateMessageStatus = `-- name: UpdateMessageStatus :exec
+const listRecentDuplicates = `-- name: ListRecentDuplicates :many
+SELECT DISTINCT ON (recipient)
+    recipient,
+    id
+FROM notifications.messages
+WHERE recipient = ANY($1::text[])
+  AND content_hash = $2
+  AND channel = $3
+  AND status NOT IN ('failed', 'cancelled', 'undelivered')
+  AND (created_at >= $4::timestamptz OR (status IN ('sent', 'delivered') AND updated_at >= $4::timestamptz))
+ORDER BY recipient, created_at DESC
+`
+
+type ListRecentDuplicatesParams struct {
+	Recipients  []string           `json:"recipients"`
+	ContentHash []byte             `json:"content_hash"`
+	Channel     string             `json:"channel"`
+	Since       pgtype.Timestamptz `json:"since"`
+}
+
+type ListRecentDuplicatesRow struct {
+	Recipient string    `json:"recipient"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Returns, for each recipient, the latest message with the same content created or sent since the given time.
+// Failed, cancelled and undelivered messages never reached the recipient, so they are not duplicated by a new one.
+func (q *Queries) ListRecentDuplicates(ctx context.Context, arg ListRecentDuplicatesParams) ([]ListRecentDuplicatesRow, error) {
+	rows, err := q.db.Query(ctx, listRecentDuplicates,
+		arg.Recipients,
+		arg.ContentHash,
+		arg.Channel,
+		arg.Since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRecentDuplicatesRow{}
+	for rows.Next() {
+		var i ListRecentDuplicatesRow
+		if err := rows.Scan(&i.Recipient, &i.ID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recoverExpiredMessages = `-- name: RecoverExpiredMessages :many
+UPDATE notifications.messages
+SET
+    status = CASE
+        WHEN recovery_count >= $1::int THEN 'failed'::notifications.message_status
+        ELSE 'pending'::notifications.message_status
+    END,
+    recovery_count = recovery_count + 1,
+    lease_expires_at = NULL,
+    last_failure_reason = 'lease expired while sending',
+    updated_at = NOW()
+WHERE status = 'sending'
+  AND lease_expires_at < NOW()
+RETURNING
+    id,
+    status,
+    external_message_id,
+    last_failure_reason,
+    callback_url
+`
+
+type RecoverExpiredMessagesRow struct {
+	ID                uuid.UUID                  `json:"id"`
+	Status            NotificationsMessageStatus `json:"status"`
+	ExternalMessageID pgtype.Text                `json:"external_message_id"`
+	LastFailureReason pgtype.Text                `json:"last_failure_reason"`
+	CallbackUrl       pgtype.Text                `json:"callback_url"`
+}
+
+func (q *Queries) RecoverExpiredMessages(ctx context.Context, maxRecoveries int32) ([]RecoverExpiredMessagesRow, error) {
+	rows, err := q.db.Query(ctx, recoverExpiredMessages, maxRecoveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecoverExpiredMessagesRow{}
+	for rows.Next() {
+		var i RecoverExpiredMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.ExternalMessageID,
+			&i.LastFailureReason,
+			&i.CallbackUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseMessage = `-- name: ReleaseMessage :execrows
+UPDATE notifications.messages
+SET
+    status = 'pending',
+    attempt_count = GREATEST(attempt_count - 1, 0),
+    next_attempt_at = $1,
+    deferred_reason = $2,
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $3
+  AND status = 'sending'
+  AND claimed_by = $4
+`
+
+type ReleaseMessageParams struct {
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	DeferredReason pgtype.Text        `json:"deferred_reason"`
+	ID             uuid.UUID          `json:"id"`
+	ClaimedBy      pgtype.Text        `json:"claimed_by"`
+}
+
+// Returns a claimed message to 'pending' without consuming the attempt counted by the claim.
+// Like UpdateMessageStatus, only the instance holding the claim may release it.
+func (q *Queries) ReleaseMessage(ctx context.Context, arg ReleaseMessageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseMessage,
+		arg.NextAttemptAt,
+		arg.DeferredReason,
+		arg.ID,
+		arg.ClaimedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const requeueMessage = `-- name: RequeueMessage :exec
+UPDATE notifications.messages
+SET
+    status = 'pending',
+    attempt_count = 0,
+    recovery_count = 0,
+    next_attempt_at = NOW(),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RequeueMessage(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, requeueMessage, id)
+	return err
+}
+
+const scheduleMessageRetry = `-- name: ScheduleMessageRetry :execrows
+UPDATE notifications.messages
+SET
+    status = 'pending',
+    next_attempt_at = $1,
+    last_failure_reason = $2,
+    deferred_reason = NULL,
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $3
+  AND status = 'sending'
+  AND claimed_by = $4
+`
+
+type ScheduleMessageRetryParams struct {
+	NextAttemptAt     pgtype.Timestamptz `json:"next_attempt_at"`
+	LastFailureReason pgtype.Text        `json:"last_failure_reason"`
+	ID                uuid.UUID          `json:"id"`
+	ClaimedBy         pgtype.Text        `json:"claimed_by"`
+}
+
+// Like UpdateMessageStatus, only the instance holding the claim may schedule the retry.
+func (q *Queries) ScheduleMessageRetry(ctx context.Context, arg ScheduleMessageRetryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, scheduleMessageRetry,
+		arg.NextAttemptAt,
+		arg.LastFailureReason,
+		arg.ID,
+		arg.ClaimedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateMessageStatus = `-- name: UpdateMessageStatus :execrows
 UPDATE notifications.messages
 SET
     status = $3,
     external_message_id = $1,
+    provider = $5,
     updated_at = NOW(),
-    last_failure_reason = $4
+    last_failure_reason = $4,
+    deferred_reason = NULL,
+    lease_expires_at = NULL
 WHERE id = $2
+  AND status = 'sending'
+  AND claimed_by = $6
 `
 
 type UpdateMessageStatusParams struct {
@@ -164,14 +773,23 @@ type UpdateMessageStatusParams struct {
 	ID                uuid.UUID                  `json:"id"`
 	Status            NotificationsMessageStatus `json:"status"`
 	LastFailureReason pgtype.Text                `json:"last_failure_reason"`
+	Provider          pgtype.Text                `json:"provider"`
+	ClaimedBy         pgtype.Text                `json:"claimed_by"`
 }
 
-func (q *Queries) UpdateMessageStatus(ctx context.Context, arg UpdateMessageStatusParams) error {
-	_, err := q.db.Exec(ctx, updateMessageStatus,
+// Only the instance holding the claim may record the outcome of a send, so a worker whose lease expired
+// cannot overwrite a message the recovery sweeper released or another instance claimed again.
+func (q *Queries) UpdateMessageStatus(ctx context.Context, arg UpdateMessageStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateMessageStatus,
 		arg.ExternalMessageID,
 		arg.ID,
 		arg.Status,
 		arg.LastFailureReason,
+		arg.Provider,
+		arg.ClaimedBy,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
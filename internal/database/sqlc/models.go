@@ -16,10 +16,13 @@ import (
 type NotificationsMessageStatus string
 
 const (
-	NotificationsMessageStatusPending NotificationsMessageStatus = "pending"
-	NotificationsMessageStatusSending NotificationsMessageStatus = "sending"
-	NotificationsMessageStatusSent    NotificationsMessageStatus = "sent"
-	NotificationsMessageStatusFailed  NotificationsMessageStatus = "failed"
+	NotificationsMessageStatusPending     NotificationsMessageStatus = "pending"
+	NotificationsMessageStatusSending     NotificationsMessageStatus = "sending"
+	NotificationsMessageStatusSent        NotificationsMessageStatus = "sent"
+	NotificationsMessageStatusFailed      NotificationsMessageStatus = "failed"
+	NotificationsMessageStatusCancelled   NotificationsMessageStatus = "cancelled"
+	NotificationsMessageStatusDelivered   NotificationsMessageStatus = "delivered"
+	NotificationsMessageStatusUndelivered NotificationsMessageStatus = "undelivered"
 )
 
 func (e *NotificationsMessageStatus) Scan(src interface{}) error {
@@ -57,13 +60,94 @@ func (ns NullNotificationsMessageStatus) Value() (driver.Value, error) {
 	return string(ns.NotificationsMessageStatus), nil
 }
 
+type NotificationsApiKey struct {
+	ID        uuid.UUID          `json:"id"`
+	Name      string             `json:"name"`
+	KeyPrefix string             `json:"key_prefix"`
+	KeyHash   string             `json:"key_hash"`
+	Scopes    []string           `json:"scopes"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	RevokedAt time.Time          `json:"revoked_at"`
+}
+
+type NotificationsCallbackDelivery struct {
+	ID             uuid.UUID          `json:"id"`
+	MessageID      uuid.UUID          `json:"message_id"`
+	Url            string             `json:"url"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	AttemptCount   int32              `json:"attempt_count"`
+	MaxAttempts    int32              `json:"max_attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	ClaimedBy      pgtype.Text        `json:"claimed_by"`
+	LeaseExpiresAt time.Time          `json:"lease_expires_at"`
+	LastError      pgtype.Text        `json:"last_error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NotificationsDeadLetterMessage struct {
+	MessageID      uuid.UUID          `json:"message_id"`
+	FailureReason  string             `json:"failure_reason"`
+	AttemptCount   int32              `json:"attempt_count"`
+	Attempts       []byte             `json:"attempts"`
+	DeadLetteredAt pgtype.Timestamptz `json:"dead_lettered_at"`
+}
+
+type NotificationsIdempotencyKey struct {
+	Key          string             `json:"key"`
+	RequestHash  string             `json:"request_hash"`
+	StatusCode   pgtype.Int4        `json:"status_code"`
+	ResponseBody []byte             `json:"response_body"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	ApiKeyID     string             `json:"api_key_id"`
+}
+
 type NotificationsMessage struct {
-	ID                   uuid.UUID                  `json:"id"`
-	Content              string                     `json:"content"`
-	RecipientPhoneNumber string                     `json:"recipient_phone_number"`
-	Status               NotificationsMessageStatus `json:"status"`
-	ExternalMessageID    pgtype.Text                `json:"external_message_id"`
-	LastFailureReason    pgtype.Text                `json:"last_failure_reason"`
-	CreatedAt            time.Time                  `json:"created_at"`
-	UpdatedAt            time.Time                  `json:"updated_at"`
+	ID                uuid.UUID                  `json:"id"`
+	Content           string                     `json:"content"`
+	Recipient         string                     `json:"recipient"`
+	Status            NotificationsMessageStatus `json:"status"`
+	ExternalMessageID pgtype.Text                `json:"external_message_id"`
+	LastFailureReason pgtype.Text                `json:"last_failure_reason"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+	ClaimedBy         pgtype.Text                `json:"claimed_by"`
+	LeaseExpiresAt    time.Time                  `json:"lease_expires_at"`
+	RecoveryCount     int32                      `json:"recovery_count"`
+	AttemptCount      int32                      `json:"attempt_count"`
+	MaxAttempts       int32                      `json:"max_attempts"`
+	NextAttemptAt     pgtype.Timestamptz         `json:"next_attempt_at"`
+	BatchID           pgtype.UUID                `json:"batch_id"`
+	SendAt            pgtype.Timestamptz         `json:"send_at"`
+	Priority          int16                      `json:"priority"`
+	Channel           string                     `json:"channel"`
+	Subject           pgtype.Text                `json:"subject"`
+	Provider          pgtype.Text                `json:"provider"`
+	Timezone          pgtype.Text                `json:"timezone"`
+	DeferredReason    pgtype.Text                `json:"deferred_reason"`
+	ContentHash       []byte                     `json:"content_hash"`
+	CallbackUrl       pgtype.Text                `json:"callback_url"`
+}
+
+type NotificationsMessageAttempt struct {
+	ID                int64              `json:"id"`
+	MessageID         uuid.UUID          `json:"message_id"`
+	AttemptNumber     int32              `json:"attempt_number"`
+	InstanceID        string             `json:"instance_id"`
+	Succeeded         bool               `json:"succeeded"`
+	Error             pgtype.Text        `json:"error"`
+	ExternalMessageID pgtype.Text        `json:"external_message_id"`
+	AttemptedAt       pgtype.Timestamptz `json:"attempted_at"`
+	Provider          pgtype.Text        `json:"provider"`
+}
+
+type NotificationsTemplate struct {
+	ID        uuid.UUID          `json:"id"`
+	Name      string             `json:"name"`
+	Subject   pgtype.Text        `json:"subject"`
+	Body      string             `json:"body"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
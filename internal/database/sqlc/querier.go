@@ -8,13 +8,76 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// Only sent messages take a receipt, so a late or repeated receipt cannot overwrite the first one.
+	ApplyDeliveryReceipt(ctx context.Context, arg ApplyDeliveryReceiptParams) (int64, error)
+	BatchExists(ctx context.Context, batchID pgtype.UUID) (bool, error)
+	CancelBatchMessages(ctx context.Context, batchID pgtype.UUID) ([]CancelBatchMessagesRow, error)
+	// Only pending messages are cancelled. A row locked by a concurrent claim is re-checked once the
+	// claim commits, so a message that moved to 'sending' is left alone.
+	CancelMessage(ctx context.Context, id uuid.UUID) (int64, error)
+	// Claims due deliveries oldest first, along with deliveries whose lease expired while they were being posted.
+	ClaimCallbackDeliveries(ctx context.Context, arg ClaimCallbackDeliveriesParams) ([]ClaimCallbackDeliveriesRow, error)
+	// Most of the batch goes to the most urgent messages, oldest first. The last reserved_size slots
+	// go to the oldest of the remaining messages whatever their priority, so bulk traffic is never starved.
+	ClaimPendingMessages(ctx context.Context, arg ClaimPendingMessagesParams) ([]ClaimPendingMessagesRow, error)
+	// Stores the response of a key and keeps it for the idempotency window from now.
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (pgtype.Timestamptz, error)
+	CreateCallbackDelivery(ctx context.Context, arg CreateCallbackDeliveryParams) error
+	CreateDeadLetterMessage(ctx context.Context, id uuid.UUID) error
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (uuid.UUID, error)
+	CreateMessageAttempt(ctx context.Context, arg CreateMessageAttemptParams) error
+	CreateTemplate(ctx context.Context, arg CreateTemplateParams) (NotificationsTemplate, error)
+	DeleteDeadLetterMessage(ctx context.Context, messageID uuid.UUID) (int64, error)
+	// Removes keys past their expiry, which are only ignored by ReserveIdempotencyKey otherwise.
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	// Deletes delivered and failed deliveries last updated before finished_before.
+	DeleteFinishedCallbackDeliveries(ctx context.Context, finishedBefore pgtype.Timestamptz) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteTemplate(ctx context.Context, id uuid.UUID) (int64, error)
+	// Revoked keys are never returned, so they stop authenticating as soon as they are revoked.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error)
 	GetAllSentMessages(ctx context.Context, arg GetAllSentMessagesParams) ([]GetAllSentMessagesRow, error)
-	GetPendingMessages(ctx context.Context, limit int32) ([]GetPendingMessagesRow, error)
-	UpdateMessageStatus(ctx context.Context, arg UpdateMessageStatusParams) error
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (NotificationsIdempotencyKey, error)
+	GetMessageByID(ctx context.Context, id uuid.UUID) (GetMessageByIDRow, error)
+	GetMessageIDByExternalID(ctx context.Context, arg GetMessageIDByExternalIDParams) (uuid.UUID, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (NotificationsTemplate, error)
+	ListAPIKeys(ctx context.Context) ([]ListAPIKeysRow, error)
+	ListDeadLetterMessages(ctx context.Context, arg ListDeadLetterMessagesParams) ([]ListDeadLetterMessagesRow, error)
+	ListMessageAttempts(ctx context.Context, messageID uuid.UUID) ([]NotificationsMessageAttempt, error)
+	// Keyset paginated on (updated_at, id); pass the last row of the previous page as the cursor.
+	ListMessages(ctx context.Context, arg ListMessagesParams) ([]ListMessagesRow, error)
+	// Returns, for each recipient, the latest message with the same content created or sent since the given time.
+	// Failed, cancelled and undelivered messages never reached the recipient, so they are not duplicated by a new one.
+	ListRecentDuplicates(ctx context.Context, arg ListRecentDuplicatesParams) ([]ListRecentDuplicatesRow, error)
+	ListTemplates(ctx context.Context, arg ListTemplatesParams) ([]NotificationsTemplate, error)
+	// Only the instance holding the claim may record the outcome, so a post that outlived its lease
+	// cannot overwrite the outcome of the instance that claimed the delivery after it.
+	MarkCallbackDelivered(ctx context.Context, arg MarkCallbackDeliveredParams) (int64, error)
+	// Like MarkCallbackDelivered, only the instance holding the claim may give up on the delivery.
+	MarkCallbackFailed(ctx context.Context, arg MarkCallbackFailedParams) (int64, error)
+	RecoverExpiredMessages(ctx context.Context, maxRecoveries int32) ([]RecoverExpiredMessagesRow, error)
+	// Returns a claimed message to 'pending' without consuming the attempt counted by the claim.
+	// Like UpdateMessageStatus, only the instance holding the claim may release it.
+	ReleaseMessage(ctx context.Context, arg ReleaseMessageParams) (int64, error)
+	RequeueMessage(ctx context.Context, id uuid.UUID) error
+	// Inserts a new in-progress key, or takes over an expired one. Returns no rows if the key is still live.
+	// The ttl of an in-progress key is short, so keys of requests that never completed free up quickly.
+	// Keys are scoped to the API key of the request, so clients cannot collide on the same value.
+	ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (string, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (int64, error)
+	// Like MarkCallbackDelivered, only the instance holding the claim may schedule the retry.
+	ScheduleCallbackRetry(ctx context.Context, arg ScheduleCallbackRetryParams) (int64, error)
+	// Like UpdateMessageStatus, only the instance holding the claim may schedule the retry.
+	ScheduleMessageRetry(ctx context.Context, arg ScheduleMessageRetryParams) (int64, error)
+	// Only the instance holding the claim may record the outcome of a send, so a worker whose lease expired
+	// cannot overwrite a message the recovery sweeper released or another instance claimed again.
+	UpdateMessageStatus(ctx context.Context, arg UpdateMessageStatusParams) (int64, error)
+	UpdateTemplate(ctx context.Context, arg UpdateTemplateParams) (NotificationsTemplate, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/akshaysangma/go-notify/internal/database/sqlc"
+	"github.com/akshaysangma/go-notify/internal/templates"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// PostgresTemplateRepository stores message templates using sqlc generated queries.
+type PostgresTemplateRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewPostgresTemplateRepository returns PostgresTemplateRepository
+func NewPostgresTemplateRepository(pool PgxPoolInterface) (*PostgresTemplateRepository, error) {
+	if dBTX, ok := pool.(sqlc.DBTX); ok {
+		return &PostgresTemplateRepository{
+			queries: sqlc.New(dBTX),
+		}, nil
+	}
+	return nil, fmt.Errorf("unable to convert pool to dBTX")
+}
+
+// mapDBTemplateToDomain converts a sqlc.NotificationsTemplate to a templates.Template domain model.
+func mapDBTemplateToDomain(dbTmpl sqlc.NotificationsTemplate) *templates.Template {
+	return &templates.Template{
+		ID:        dbTmpl.ID.String(),
+		Name:      dbTmpl.Name,
+		Subject:   dbTmpl.Subject.String,
+		Body:      dbTmpl.Body,
+		CreatedAt: dbTmpl.CreatedAt.Time,
+		UpdatedAt: dbTmpl.UpdatedAt.Time,
+	}
+}
+
+// mapTemplateWriteError translates a failed insert or update of a template into a domain error.
+func mapTemplateWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return templates.ErrNameTaken
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return templates.ErrTemplateNotFound
+	}
+	return err
+}
+
+// Create call sqlc generated CreateTemplate for storing a new template.
+func (r *PostgresTemplateRepository) Create(ctx context.Context, tmpl templates.Template) (*templates.Template, error) {
+	id, err := uuid.Parse(tmpl.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template ID %q: %w", tmpl.ID, err)
+	}
+
+	dbTmpl, err := r.queries.CreateTemplate(ctx, sqlc.CreateTemplateParams{
+		ID:      id,
+		Name:    tmpl.Name,
+		Subject: pgtype.Text{String: tmpl.Subject, Valid: tmpl.Subject != ""},
+		Body:    tmpl.Body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert template: %w", mapTemplateWriteError(err))
+	}
+	return mapDBTemplateToDomain(dbTmpl), nil
+}
+
+// Get call sqlc generated GetTemplate for fetching a template.
+func (r *PostgresTemplateRepository) Get(ctx context.Context, templateID string) (*templates.Template, error) {
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		return nil, templates.ErrTemplateNotFound
+	}
+
+	dbTmpl, err := r.queries.GetTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, templates.ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch template: %w", err)
+	}
+	return mapDBTemplateToDomain(dbTmpl), nil
+}
+
+// List call sqlc generated ListTemplates for fetching a page of templates.
+func (r *PostgresTemplateRepository) List(ctx context.Context, limit, offset int32) ([]templates.Template, error) {
+	dbTmpls, err := r.queries.ListTemplates(ctx, sqlc.ListTemplatesParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	tmpls := make([]templates.Template, 0, len(dbTmpls))
+	for _, dbTmpl := range dbTmpls {
+		tmpls = append(tmpls, *mapDBTemplateToDomain(dbTmpl))
+	}
+	return tmpls, nil
+}
+
+// Update call sqlc generated UpdateTemplate for replacing the content of a template.
+func (r *PostgresTemplateRepository) Update(ctx context.Context, tmpl templates.Template) (*templates.Template, error) {
+	id, err := uuid.Parse(tmpl.ID)
+	if err != nil {
+		return nil, templates.ErrTemplateNotFound
+	}
+
+	dbTmpl, err := r.queries.UpdateTemplate(ctx, sqlc.UpdateTemplateParams{
+		ID:      id,
+		Name:    tmpl.Name,
+		Subject: pgtype.Text{String: tmpl.Subject, Valid: tmpl.Subject != ""},
+		Body:    tmpl.Body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", mapTemplateWriteError(err))
+	}
+	return mapDBTemplateToDomain(dbTmpl), nil
+}
+
+// Delete call sqlc generated DeleteTemplate for removing a template.
+func (r *PostgresTemplateRepository) Delete(ctx context.Context, templateID string) error {
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		return templates.ErrTemplateNotFound
+	}
+
+	deleted, err := r.queries.DeleteTemplate(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	if deleted == 0 {
+		return templates.ErrTemplateNotFound
+	}
+	return nil
+}
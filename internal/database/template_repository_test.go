@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akshaysangma/go-notify/internal/templates"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTemplateRepository_Lifecycle(t *testing.T) {
+	pool := newTestPool(t)
+	_, err := pool.Exec(context.Background(), "DELETE FROM notifications.templates")
+	require.NoError(t, err)
+	repo, err := NewPostgresTemplateRepository(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, templates.Template{ID: uuid.NewString(), Name: "reminder", Subject: "Hi {{.name}}", Body: "See you {{.date}}."})
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	_, err = repo.Create(ctx, templates.Template{ID: uuid.NewString(), Name: "welcome", Body: "Welcome!"})
+	require.NoError(t, err)
+
+	// Names are unique.
+	_, err = repo.Create(ctx, templates.Template{ID: uuid.NewString(), Name: "reminder", Body: "Again"})
+	assert.ErrorIs(t, err, templates.ErrNameTaken)
+
+	fetched, err := repo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi {{.name}}", fetched.Subject)
+
+	listed, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "reminder", listed[0].Name)
+	assert.Equal(t, "welcome", listed[1].Name)
+
+	created.Subject = ""
+	created.Body = "Updated {{.date}}."
+	updated, err := repo.Update(ctx, *created)
+	require.NoError(t, err)
+	assert.Empty(t, updated.Subject)
+	assert.Equal(t, "Updated {{.date}}.", updated.Body)
+	updated.Name = "welcome"
+	_, err = repo.Update(ctx, *updated)
+	assert.ErrorIs(t, err, templates.ErrNameTaken)
+
+	require.NoError(t, repo.Delete(ctx, created.ID))
+	_, err = repo.Get(ctx, created.ID)
+	assert.ErrorIs(t, err, templates.ErrTemplateNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, created.ID), templates.ErrTemplateNotFound)
+	_, err = repo.Update(ctx, *created)
+	assert.ErrorIs(t, err, templates.ErrTemplateNotFound)
+}
@@ -0,0 +1,30 @@
+package idempotency
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrKeyNotFound is returned when an idempotency key is not stored.
+var ErrKeyNotFound = fmt.Errorf("idempotency key not found")
+
+// Record is a stored Idempotency-Key along with the response of the request that first used it.
+type Record struct {
+	// The ID of the API key the request was made with, which scopes Key. Empty when authentication is disabled.
+	APIKeyID string
+	// The client supplied Idempotency-Key header value.
+	Key string
+	// The SHA-256 hash of the request the key was first used with.
+	RequestHash string
+	// The HTTP status code of the original response, zero while the request is in progress.
+	StatusCode int
+	// The body of the original response, which includes the created message IDs.
+	ResponseBody []byte
+	// The time after which the key may be reused for a new request.
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the original request finished and its response can be replayed.
+func (r *Record) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the contract on idempotency Records.
+// Keys are scoped to the API key they were used with, so the same key of two API keys never collides.
+type Repository interface {
+	// Reserve stores key of apiKeyID as in progress for the given request hash, expiring after lockTimeout
+	// so the key frees up if the request never completes. If an unexpired record already exists for key,
+	// it is returned with reserved set to false.
+	Reserve(ctx context.Context, apiKeyID, key, requestHash string, lockTimeout time.Duration) (record *Record, reserved bool, err error)
+
+	// Complete stores the response of the request that reserved key of apiKeyID, so replays return it
+	// until window has passed.
+	Complete(ctx context.Context, apiKeyID, key string, statusCode int, responseBody []byte, window time.Duration) error
+
+	// Release deletes key of apiKeyID, so the request can be retried with it.
+	Release(ctx context.Context, apiKeyID, key string) error
+
+	// DeleteExpired deletes every expired key along with its stored response and returns how many were deleted.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,46 @@
+package messages
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// nonPublicPrefixes are the special-purpose ranges that netip.Addr does not already report as non-public.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// IsPublicAddr reports whether addr is a public unicast address. Loopback, link-local (such as the
+// 169.254.169.254 metadata service), private and other special-purpose addresses are not, so callbacks
+// are never posted to the network of the server itself.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPublicHost reports whether the host of a callback URL may be public. IP literals must be public addresses
+// and localhost names are rejected; other names are checked when the callback is posted, once they are resolved.
+func isPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return IsPublicAddr(addr)
+	}
+	return true
+}
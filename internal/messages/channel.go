@@ -0,0 +1,111 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+)
+
+// Delivery channels shipped with the service.
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+	ChannelChat  = "chat"
+)
+
+// ChatCharacterLimit is the longest message text accepted by Slack-style incoming webhooks.
+const ChatCharacterLimit = 40000
+
+// CharacterLimit returns the maximum content length of messages on channel, zero for no limit.
+// Only sms messages are held to the configured smsLimit; email has no limit.
+func CharacterLimit(channel string, smsLimit int) int {
+	switch channel {
+	case ChannelSMS:
+		return smsLimit
+	case ChannelChat:
+		return ChatCharacterLimit
+	default:
+		return 0
+	}
+}
+
+// ErrInvalidRecipient is returned for a recipient that is not an address on its channel.
+var ErrInvalidRecipient = fmt.Errorf("invalid recipient")
+
+// phoneNumberPattern matches phone numbers in E.164 format: a plus sign, then up to 15 digits without a leading zero.
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{0,14}$`)
+
+// ValidateRecipient checks that recipient is an address on channel: a phone number in E.164 format for sms
+// and an email address for email. Channels without a known address format accept any recipient.
+func ValidateRecipient(channel, recipient string) error {
+	switch channel {
+	case ChannelSMS:
+		if !phoneNumberPattern.MatchString(recipient) {
+			return fmt.Errorf("%w: %q is not a phone number in E.164 format", ErrInvalidRecipient, recipient)
+		}
+	case ChannelEmail:
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("%w: %q is not an email address: %w", ErrInvalidRecipient, recipient, err)
+		}
+	}
+	return nil
+}
+
+// ErrUnsupportedChannel is returned for a channel without a registered sender.
+var ErrUnsupportedChannel = fmt.Errorf("unsupported channel")
+
+// ErrSenderUnavailable is wrapped by senders that refuse to send for now, such as while a circuit
+// breaker is open. The message is returned to 'pending' without consuming an attempt, and is not
+// picked up again before the RetryDelay of the error when it implements ClassifiedError.
+var ErrSenderUnavailable = fmt.Errorf("sender unavailable")
+
+// Sender defines the contract for delivering message content to a recipient over a single channel.
+type Sender interface {
+	Send(ctx context.Context, to, content string) (externalMessageID string, err error)
+}
+
+// SubjectSender is implemented by senders of channels whose messages carry a subject line, such as email.
+// Messages with a subject are sent through SendWithSubject when their sender implements it.
+// Like ProviderSender, it reports the provider that accepted the message, empty for senders
+// that are not spread over several providers.
+type SubjectSender interface {
+	SendWithSubject(ctx context.Context, to, subject, content string) (externalMessageID, provider string, err error)
+}
+
+// SenderRegistry routes each message to the sender registered for its channel.
+// Senders are registered at startup; Register must not be called concurrently with Sender.
+type SenderRegistry struct {
+	senders map[string]Sender
+}
+
+// NewSenderRegistry returns an empty SenderRegistry.
+func NewSenderRegistry() *SenderRegistry {
+	return &SenderRegistry{senders: make(map[string]Sender)}
+}
+
+// Register makes sender deliver the messages of channel, replacing any sender registered before.
+func (r *SenderRegistry) Register(channel string, sender Sender) {
+	r.senders[channel] = sender
+}
+
+// Sender returns the sender registered for channel.
+// Returns ErrUnsupportedChannel if there is none.
+func (r *SenderRegistry) Sender(channel string) (Sender, error) {
+	sender, ok := r.senders[channel]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedChannel, channel)
+	}
+	return sender, nil
+}
+
+// Channels returns the registered channels in alphabetical order.
+func (r *SenderRegistry) Channels() []string {
+	channels := make([]string, 0, len(r.senders))
+	for channel := range r.senders {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
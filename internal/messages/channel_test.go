@@ -0,0 +1,53 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSenderRegistry(t *testing.T) {
+	sms := new(MockSender)
+	chat := new(MockSender)
+	registry := NewSenderRegistry()
+	registry.Register(ChannelSMS, sms)
+	registry.Register(ChannelChat, chat)
+
+	sender, err := registry.Sender(ChannelChat)
+	require.NoError(t, err)
+	assert.Same(t, chat, sender)
+
+	_, err = registry.Sender("pigeon")
+	assert.ErrorIs(t, err, ErrUnsupportedChannel)
+
+	assert.Equal(t, []string{ChannelChat, ChannelSMS}, registry.Channels())
+}
+
+func TestValidateRecipient(t *testing.T) {
+	tests := []struct {
+		channel   string
+		recipient string
+		valid     bool
+	}{
+		{channel: ChannelSMS, recipient: "+15551234567", valid: true},
+		{channel: ChannelSMS, recipient: "15551234567"},
+		{channel: ChannelSMS, recipient: "+0551234567"},
+		{channel: ChannelSMS, recipient: "+1555123456789012"},
+		{channel: ChannelSMS, recipient: "+1 555 123 4567"},
+		{channel: ChannelSMS, recipient: "jane@example.org"},
+		{channel: ChannelEmail, recipient: "jane@example.org", valid: true},
+		{channel: ChannelEmail, recipient: "Jane Doe <jane@example.org>", valid: true},
+		{channel: ChannelEmail, recipient: "jane"},
+		{channel: ChannelEmail, recipient: "+15551234567"},
+		{channel: ChannelChat, recipient: "#alerts", valid: true},
+	}
+	for _, tt := range tests {
+		err := ValidateRecipient(tt.channel, tt.recipient)
+		if tt.valid {
+			assert.NoError(t, err, "%s %q", tt.channel, tt.recipient)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidRecipient, "%s %q", tt.channel, tt.recipient)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package messages
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrDeadLetterNotFound is returned when a message is not in the dead-letter queue.
+var ErrDeadLetterNotFound = fmt.Errorf("dead-letter message not found")
+
+// Attempt records the outcome of a single send attempt of a message.
+type Attempt struct {
+	// The message the attempt belongs to.
+	MessageID string `json:"message_id,omitempty" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	// The attempt number, starting at 1.
+	AttemptNumber int `json:"attempt_number" example:"1"`
+	// The scheduler instance that made the attempt.
+	InstanceID string `json:"instance_id" example:"go-notify-7d9f-1"`
+	// Whether the provider accepted the message.
+	Succeeded bool `json:"succeeded" example:"false"`
+	// The error returned by the provider, if any.
+	Error *string `json:"error,omitempty" example:"webhook responded with non-202 status code: 503"`
+	// The ID returned from the external webhook service, if any.
+	ExternalMessageID *string `json:"external_message_id,omitempty" example:"ext-msg-12345"`
+	// The provider the attempt ended on, for channels spread over several providers.
+	Provider string `json:"provider,omitempty" example:"primary"`
+	// The timestamp of the attempt.
+	AttemptedAt time.Time `json:"attempted_at" example:"2025-07-09T10:01:00Z"`
+}
+
+// DeadLetter represents a message that exhausted its send attempts.
+type DeadLetter struct {
+	// The ID of the failed message.
+	MessageID string `json:"message_id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	// The content of the failed message.
+	Content string `json:"content" example:"Your appointment is confirmed."`
+	// The phone number of the recipient.
+	Recipient string `json:"recipient" example:"+15551234567"`
+	// The reason of the final failure.
+	FailureReason string `json:"failure_reason" example:"webhook send failed: 503 service unavailable"`
+	// The number of attempts made before giving up.
+	AttemptCount int `json:"attempt_count" example:"5"`
+	// The full history of send attempts.
+	Attempts []Attempt `json:"attempts"`
+	// The timestamp when the message was moved to the dead-letter queue.
+	DeadLetteredAt time.Time `json:"dead_lettered_at" example:"2025-07-09T11:00:00Z"`
+}
@@ -9,48 +9,155 @@ import (
 
 // Domain-specific errors.
 var (
-	ErrContentTooLong = fmt.Errorf("message content exceeds character limit")
-	ErrRecipientEmpty = fmt.Errorf("recipient cannot be empty")
+	ErrContentTooLong     = fmt.Errorf("message content exceeds character limit")
+	ErrRecipientEmpty     = fmt.Errorf("recipient cannot be empty")
+	ErrMessageNotFound    = fmt.Errorf("message not found")
+	ErrSendAtInPast       = fmt.Errorf("send_at is in the past")
+	ErrSendAtTooFar       = fmt.Errorf("send_at is beyond the scheduling horizon")
+	ErrNotCancellable     = fmt.Errorf("only pending messages can be cancelled")
+	ErrBatchNotFound      = fmt.Errorf("batch not found")
+	ErrContentAndTemplate = fmt.Errorf("content and template_id cannot both be set")
+	ErrInvalidCallbackURL = fmt.Errorf("invalid callback URL")
+	ErrLeaseLost          = fmt.Errorf("message is no longer claimed by this instance")
 )
 
+// sendAtPastTolerance allows a send_at slightly in the past, to absorb client clock skew.
+const sendAtPastTolerance = 5 * time.Minute
+
 // Message represents the message entity in the domain.
 type Message struct {
 	// The unique identifier for the message.
 	ID string `json:"id" example:"a1b2c3d4-e5f6-7890-1234-567890abcdef"`
+	// The subject line, for channels that carry one such as email.
+	Subject string `json:"subject,omitempty" example:"Appointment confirmed"`
 	// The content of the message to be sent. Should not exceed content length limit.
 	Content string `json:"content" example:"Your appointment is confirmed."`
-	// The phone number of the recipient.
+	// The channel the message is delivered over, e.g. sms or chat.
+	Channel string `json:"channel,omitempty" example:"sms"`
+	// The recipient address on the channel, e.g. a phone number for sms.
 	Recipient string `json:"recipient" example:"+15551234567"`
 	// The current status of the message.
 	Status string `json:"status" example:"sent"`
-	// The ID returned from the external webhook service.
+	// The dispatch priority: critical, high, normal or bulk.
+	Priority string `json:"priority,omitempty" example:"normal"`
+	// The IANA time zone of the recipient, used for quiet hours. The configured default applies if empty.
+	Timezone string `json:"timezone,omitempty" example:"Europe/Berlin"`
+	// The URL notified when the message is sent or fails for good.
+	CallbackURL string `json:"callback_url,omitempty" example:"https://example.com/hooks/notify"`
+	// The ID of the batch the message was created in.
+	BatchID string `json:"batch_id,omitempty" example:"f0e1d2c3-b4a5-6789-0123-456789abcdef"`
+	// The name of the provider that accepted the message, for channels spread over several providers.
+	Provider string `json:"provider,omitempty" example:"primary"`
+	// The ID returned by the provider of the channel, if it returns one.
 	ExternalMessageID *string `json:"external_message_id,omitempty" example:"ext-msg-12345"`
 	// The reason for the last failure, if any.
 	LastFailureReason *string `json:"last_failure_reason,omitempty" example:"Webhook provider timed out"`
+	// Why the pending message is held back without being attempted, e.g. quiet hours or a per-recipient limit.
+	DeferredReason *string `json:"deferred_reason,omitempty" example:"quiet hours in Europe/Berlin until 2025-07-10T08:00:00+02:00"`
+	// The number of send attempts made so far.
+	AttemptCount int `json:"attempt_count,omitempty" example:"1"`
+	// The number of send attempts allowed before the message is marked as failed.
+	MaxAttempts int `json:"max_attempts,omitempty" example:"5"`
+	// The earliest time the message may be sent. Messages without it are sent on the next scheduler run.
+	SendAt *time.Time `json:"send_at,omitempty" example:"2025-07-10T09:00:00Z"`
+	// The earliest time the next send attempt may happen, set when a retry is scheduled or the message is deferred.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" example:"2025-07-09T10:05:00Z"`
+	// The instance holding the claim on the message, only set on claimed messages.
+	ClaimedBy string `json:"-"`
 	// The timestamp when the message was created.
 	CreatedAt time.Time `json:"created_at" example:"2025-07-09T10:00:00Z"`
 	// The timestamp when the message was last updated.
 	UpdatedAt time.Time `json:"updated_at" example:"2025-07-09T10:01:00Z"`
+	// The history of send attempts, only populated when a single message is fetched.
+	Attempts []Attempt `json:"attempts,omitempty"`
+}
+
+// BatchRequest describes a message to create for multiple recipients.
+type BatchRequest struct {
+	// The subject line sent to every recipient, for channels that carry one.
+	Subject string
+	// The content sent to every recipient. Must be empty when TemplateID is set.
+	Content string
+	// The ID of the template the content, and the subject if the request has none, are rendered from.
+	TemplateID string
+	// The template variables of each recipient, keyed by recipient address.
+	Variables map[string]map[string]any
+	// The channel the messages are delivered over, sms if empty.
+	Channel string
+	// The recipient addresses on the channel.
+	Recipients []string
+	// The dispatch priority of the messages, normal if empty.
+	Priority string
+	// The earliest time the messages may be sent, nil to send them on the next scheduler run.
+	SendAt *time.Time
+	// The IANA time zone of the recipients, used for quiet hours. The configured default applies if empty.
+	Timezone string
+	// The URL notified when each message is sent or fails for good, empty for no notification.
+	CallbackURL string
+}
+
+// Batch groups the messages created by a single request for multiple recipients.
+type Batch struct {
+	// The ID shared by every message of the batch.
+	ID string
+	// The created messages, in the order of the requested recipients.
+	Messages []Message
+	// The recipients skipped because they already have a message with the same content.
+	Deduplicated []Duplicate
+}
+
+// Duplicate identifies a recipient skipped because the same content was recently sent, or is about to be, to it.
+type Duplicate struct {
+	// The skipped recipient.
+	Recipient string
+	// The ID of the existing message with the same content.
+	MessageID string
+}
+
+// RecoveryResult summarises a sweep of messages whose 'sending' lease expired.
+type RecoveryResult struct {
+	// Messages returned to 'pending' so they are picked up again.
+	Recovered int
+	// Messages moved to 'failed' because they exceeded the allowed number of recoveries.
+	Failed int
 }
 
 // NewMessage is a constructor for creating a new Message, enforcing domain invariants.
+// A charLimit of zero or less does not limit the content.
 func NewMessage(content, recipient string, charLimit int) (*Message, error) {
 	if recipient == "" {
 		return nil, ErrRecipientEmpty
 	}
 
-	if len(content) > charLimit {
+	if charLimit > 0 && len(content) > charLimit {
 		return nil, fmt.Errorf("%w, limit : %v", ErrContentTooLong, charLimit)
 	}
 
 	return &Message{
-		ID:        uuid.New().String(),
-		Content:   content,
-		Recipient: recipient,
-		Status:    "pending",
+		ID:          uuid.New().String(),
+		Content:     content,
+		Channel:     ChannelSMS,
+		Recipient:   recipient,
+		Status:      "pending",
+		Priority:    PriorityNormal,
+		MaxAttempts: 1,
 	}, nil
 }
 
+// ScheduleAt defers the message until sendAt. The time may not be more than a few minutes
+// in the past, nor further than horizon from now. A horizon of zero disables the upper bound.
+func (m *Message) ScheduleAt(sendAt, now time.Time, horizon time.Duration) error {
+	if sendAt.Before(now.Add(-sendAtPastTolerance)) {
+		return fmt.Errorf("%w: %s", ErrSendAtInPast, sendAt.Format(time.RFC3339))
+	}
+	if horizon > 0 && sendAt.After(now.Add(horizon)) {
+		return fmt.Errorf("%w of %s", ErrSendAtTooFar, horizon)
+	}
+	sendAt = sendAt.UTC()
+	m.SendAt = &sendAt
+	return nil
+}
+
 // MarkAsSending updates the message status to 'sending'.
 func (m *Message) MarkAsSending() {
 	m.Status = "sending"
@@ -58,10 +165,15 @@ func (m *Message) MarkAsSending() {
 }
 
 // MarkAsSent updates the message status to 'sent' and stores the external ID.
+// Providers that do not identify accepted messages return an empty external ID, which is not stored.
 func (m *Message) MarkAsSent(externalID string) {
 	m.Status = "sent"
-	m.ExternalMessageID = &externalID
+	m.ExternalMessageID = nil
+	if externalID != "" {
+		m.ExternalMessageID = &externalID
+	}
 	m.LastFailureReason = nil
+	m.DeferredReason = nil
 	m.UpdatedAt = time.Now().UTC()
 }
 
@@ -69,5 +181,42 @@ func (m *Message) MarkAsSent(externalID string) {
 func (m *Message) MarkAsFailed(reason string) {
 	m.Status = "failed"
 	m.LastFailureReason = &reason
+	m.DeferredReason = nil
+	m.UpdatedAt = time.Now().UTC()
+}
+
+// Release returns a message that was claimed but not sent to 'pending', giving back the attempt
+// counted by the claim, and defers its next attempt until nextAttemptAt for the given reason.
+func (m *Message) Release(reason string, nextAttemptAt time.Time) {
+	m.Status = "pending"
+	m.AttemptCount = max(m.AttemptCount-1, 0)
+	m.DeferredReason = &reason
+	m.NextAttemptAt = &nextAttemptAt
+	m.UpdatedAt = time.Now().UTC()
+}
+
+// MarkAsCancelled updates the message status to 'cancelled'.
+// Returns ErrNotCancellable unless the message is still 'pending'.
+func (m *Message) MarkAsCancelled() error {
+	if m.Status != "pending" {
+		return fmt.Errorf("%w, message is %s", ErrNotCancellable, m.Status)
+	}
+	m.Status = "cancelled"
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// CanRetry reports whether the message has send attempts left.
+func (m *Message) CanRetry() bool {
+	return m.AttemptCount < m.MaxAttempts
+}
+
+// MarkForRetry returns the message to 'pending', records the reason and
+// defers the next attempt until nextAttemptAt.
+func (m *Message) MarkForRetry(reason string, nextAttemptAt time.Time) {
+	m.Status = "pending"
+	m.LastFailureReason = &reason
+	m.DeferredReason = nil
+	m.NextAttemptAt = &nextAttemptAt
 	m.UpdatedAt = time.Now().UTC()
 }
@@ -24,6 +24,7 @@ func TestNewMessage(t *testing.T) {
 		assert.Equal(t, content, msg.Content)
 		assert.Equal(t, recipient, msg.Recipient)
 		assert.Equal(t, "pending", msg.Status)
+		assert.Equal(t, 1, msg.MaxAttempts)
 	})
 
 	t.Run("Empty Recipient", func(t *testing.T) {
@@ -65,6 +66,24 @@ func TestMessageStateTransitions(t *testing.T) {
 		assert.True(t, msg.UpdatedAt.After(initialTime))
 	})
 
+	t.Run("MarkAsSent Without External ID", func(t *testing.T) {
+		sent := Message{Status: "sending"}
+		sent.MarkAsSent("")
+		assert.Equal(t, "sent", sent.Status)
+		assert.Nil(t, sent.ExternalMessageID)
+	})
+
+	t.Run("MarkForRetry", func(t *testing.T) {
+		initialTime := msg.UpdatedAt
+		reason := "webhook returned 503"
+		nextAttemptAt := time.Now().UTC().Add(time.Minute)
+		msg.MarkForRetry(reason, nextAttemptAt)
+		assert.Equal(t, "pending", msg.Status)
+		assert.Equal(t, reason, *msg.LastFailureReason)
+		assert.Equal(t, nextAttemptAt, *msg.NextAttemptAt)
+		assert.True(t, msg.UpdatedAt.After(initialTime))
+	})
+
 	t.Run("MarkAsFailed", func(t *testing.T) {
 		initialTime := msg.UpdatedAt
 		reason := "webhook timeout"
@@ -75,3 +94,55 @@ func TestMessageStateTransitions(t *testing.T) {
 		assert.True(t, msg.UpdatedAt.After(initialTime))
 	})
 }
+
+func TestMessage_MarkAsCancelled(t *testing.T) {
+	for _, status := range []string{"sending", "sent", "failed", "cancelled"} {
+		msg := &Message{Status: status}
+		assert.ErrorIs(t, msg.MarkAsCancelled(), ErrNotCancellable, "status %s", status)
+		assert.Equal(t, status, msg.Status)
+	}
+
+	msg := &Message{Status: "pending"}
+	assert.NoError(t, msg.MarkAsCancelled())
+	assert.Equal(t, "cancelled", msg.Status)
+	assert.False(t, msg.UpdatedAt.IsZero())
+}
+
+func TestMessage_CanRetry(t *testing.T) {
+	msg := &Message{AttemptCount: 1, MaxAttempts: 3}
+	assert.True(t, msg.CanRetry())
+
+	msg.AttemptCount = 3
+	assert.False(t, msg.CanRetry())
+}
+
+func TestMessage_ScheduleAt(t *testing.T) {
+	now := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	horizon := 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		sendAt  time.Time
+		wantErr error
+	}{
+		{name: "Future", sendAt: now.Add(time.Hour)},
+		{name: "Slightly In The Past", sendAt: now.Add(-time.Minute)},
+		{name: "Distant Past", sendAt: now.Add(-time.Hour), wantErr: ErrSendAtInPast},
+		{name: "At Horizon", sendAt: now.Add(horizon)},
+		{name: "Beyond Horizon", sendAt: now.Add(horizon + time.Second), wantErr: ErrSendAtTooFar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{}
+			err := msg.ScheduleAt(tt.sendAt, now, horizon)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, msg.SendAt)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.sendAt.Equal(*msg.SendAt))
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package messages
+
+import (
+	"fmt"
+	"math"
+)
+
+// Message priorities, from the most to the least urgent.
+const (
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityNormal   = "normal"
+	PriorityBulk     = "bulk"
+)
+
+// ErrInvalidPriority is returned for a priority other than critical, high, normal or bulk.
+var ErrInvalidPriority = fmt.Errorf("invalid message priority")
+
+// priorities lists the priorities by rank; a lower rank is dispatched first.
+var priorities = []string{PriorityCritical, PriorityHigh, PriorityNormal, PriorityBulk}
+
+// PriorityRank returns the dispatch rank of priority, lower ranks are claimed first.
+// An empty priority is treated as normal.
+func PriorityRank(priority string) (int16, error) {
+	if priority == "" {
+		priority = PriorityNormal
+	}
+	for rank, p := range priorities {
+		if p == priority {
+			return int16(rank), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidPriority, priority)
+}
+
+// PriorityFromRank returns the priority stored as rank. Unknown ranks are reported as normal.
+func PriorityFromRank(rank int16) string {
+	if rank < 0 || int(rank) >= len(priorities) {
+		return PriorityNormal
+	}
+	return priorities[rank]
+}
+
+// reservedSlots returns how many of limit claim slots are kept for the oldest messages regardless
+// of their priority. Any positive share reserves at least one slot once the batch has more than one.
+func reservedSlots(limit int, share float64) int {
+	if share <= 0 || limit <= 1 {
+		return 0
+	}
+	reserved := int(math.Round(float64(limit) * share))
+	return min(max(reserved, 1), limit-1)
+}
@@ -0,0 +1,42 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityRank(t *testing.T) {
+	for want, priority := range []string{PriorityCritical, PriorityHigh, PriorityNormal, PriorityBulk} {
+		rank, err := PriorityRank(priority)
+		require.NoError(t, err)
+		assert.Equal(t, int16(want), rank)
+		assert.Equal(t, priority, PriorityFromRank(rank))
+	}
+
+	rank, err := PriorityRank("")
+	require.NoError(t, err)
+	assert.Equal(t, PriorityNormal, PriorityFromRank(rank))
+
+	_, err = PriorityRank("urgent")
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+	assert.Equal(t, PriorityNormal, PriorityFromRank(42))
+}
+
+func TestReservedSlots(t *testing.T) {
+	tests := []struct {
+		limit    int
+		share    float64
+		expected int
+	}{
+		{limit: 10, share: 0, expected: 0},
+		{limit: 10, share: 0.2, expected: 2},
+		{limit: 10, share: 0.01, expected: 1},
+		{limit: 10, share: 1, expected: 9},
+		{limit: 1, share: 0.5, expected: 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, reservedSlots(tt.limit, tt.share), "limit %d, share %v", tt.limit, tt.share)
+	}
+}
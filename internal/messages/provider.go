@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"context"
+	"time"
+)
+
+// ProviderSender is implemented by senders that spread a channel over several providers.
+// Messages are sent through SendWithProvider when their sender implements it, so the provider
+// that accepted each message is recorded. On failure, provider is the last one tried.
+type ProviderSender interface {
+	SendWithProvider(ctx context.Context, to, content string) (externalMessageID, provider string, err error)
+}
+
+// ProviderHealth reports the recent delivery outcomes of a provider.
+type ProviderHealth struct {
+	// The name of the provider.
+	Name string `json:"name" example:"primary"`
+	// Providers with the lowest priority are tried first.
+	Priority int `json:"priority" example:"0"`
+	// The share of traffic among providers of the same priority.
+	Weight int `json:"weight" example:"3"`
+	// Whether the provider is tried in its turn; unhealthy providers are only tried as a last resort.
+	Healthy bool `json:"healthy" example:"true"`
+	// The number of retryable failures since the last successful send.
+	ConsecutiveFailures int `json:"consecutive_failures" example:"0"`
+	// The error of the last failure, if any.
+	LastError string `json:"last_error,omitempty" example:"webhook responded with non-202 status code: 503"`
+	// The timestamp of the last failure, if any.
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty" example:"2025-07-09T10:01:00Z"`
+	// The timestamp of the last successful send, if any.
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty" example:"2025-07-09T10:02:00Z"`
+}
@@ -0,0 +1,83 @@
+package messages
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Query-specific errors.
+var (
+	ErrInvalidStatus = fmt.Errorf("invalid message status")
+	ErrInvalidCursor = fmt.Errorf("invalid cursor")
+)
+
+// ValidStatus reports whether status is a known message status.
+func ValidStatus(status string) bool {
+	switch status {
+	case "pending", "sending", "sent", "failed", "cancelled", ReceiptDelivered, ReceiptUndelivered:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cursor marks the position of the last message of a page, ordered by (UpdatedAt, ID) descending.
+type Cursor struct {
+	UpdatedAt time.Time
+	ID        string
+}
+
+// Encode returns the opaque string form of the cursor handed out to clients.
+func (c Cursor) Encode() string {
+	raw := c.UpdatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	updatedAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{UpdatedAt: t, ID: id}, nil
+}
+
+// MessageFilter narrows down a message listing. Zero values are ignored.
+type MessageFilter struct {
+	// Only return messages in one of these statuses.
+	Statuses []string
+	// Only return messages sent to this recipient.
+	Recipient string
+	// Only return the message with this external ID.
+	ExternalMessageID string
+	// Inclusive lower and exclusive upper bounds on the creation time.
+	CreatedAfter, CreatedBefore *time.Time
+	// Inclusive lower and exclusive upper bounds on the last update time.
+	UpdatedAfter, UpdatedBefore *time.Time
+	// Only return messages after this position.
+	Cursor *Cursor
+	// Maximum number of messages to return.
+	Limit int32
+}
+
+// MessagePage is a page of messages, most recently updated first.
+type MessagePage struct {
+	Messages []Message
+	// Cursor of the next page, empty on the last page.
+	NextCursor string
+}
@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	cursor := Cursor{
+		UpdatedAt: time.Date(2025, 7, 9, 10, 1, 0, 123456000, time.UTC),
+		ID:        "a1b2c3d4-e5f6-7890-1234-567890abcdef",
+	}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	for _, invalid := range []string{"", "!!!", "bm8tY29tbWE", "eWVzdGVyZGF5LGExYjI"} {
+		_, err := DecodeCursor(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "cursor %q", invalid)
+	}
+}
+
+func TestValidStatus(t *testing.T) {
+	for _, status := range []string{"pending", "sending", "sent", "failed"} {
+		assert.True(t, ValidStatus(status))
+	}
+	assert.False(t, ValidStatus("done"))
+	assert.False(t, ValidStatus(""))
+}
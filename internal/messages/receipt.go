@@ -0,0 +1,55 @@
+package messages
+
+import (
+	"fmt"
+	"time"
+)
+
+// Delivery receipt errors.
+var (
+	ErrInvalidReceipt     = fmt.Errorf("invalid delivery receipt")
+	ErrNotAwaitingReceipt = fmt.Errorf("only sent messages take a delivery receipt")
+)
+
+// Delivery states reported by providers for messages they accepted.
+const (
+	ReceiptDelivered   = "delivered"
+	ReceiptUndelivered = "undelivered"
+)
+
+// DeliveryReceipt is a provider's report on whether a message it accepted reached the recipient.
+type DeliveryReceipt struct {
+	// The ID the provider returned when it accepted the message.
+	ExternalMessageID string
+	// Either ReceiptDelivered or ReceiptUndelivered.
+	Status string
+	// Why the message was not delivered, if the provider gave a reason.
+	Reason string
+}
+
+// Validate checks that the receipt names a message and a known delivery state.
+func (r DeliveryReceipt) Validate() error {
+	if r.ExternalMessageID == "" {
+		return fmt.Errorf("%w: external_message_id is required", ErrInvalidReceipt)
+	}
+	if r.Status != ReceiptDelivered && r.Status != ReceiptUndelivered {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidReceipt, r.Status)
+	}
+	return nil
+}
+
+// ApplyReceipt moves a sent message to the delivery state of the receipt.
+// Returns ErrNotAwaitingReceipt unless the message is 'sent'.
+func (m *Message) ApplyReceipt(receipt DeliveryReceipt) error {
+	if m.Status != "sent" {
+		return fmt.Errorf("%w, message is %s", ErrNotAwaitingReceipt, m.Status)
+	}
+	m.Status = receipt.Status
+	m.LastFailureReason = nil
+	if receipt.Status == ReceiptUndelivered && receipt.Reason != "" {
+		reason := receipt.Reason
+		m.LastFailureReason = &reason
+	}
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,36 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryReceipt_Validate(t *testing.T) {
+	assert.NoError(t, DeliveryReceipt{ExternalMessageID: "ext-1", Status: ReceiptDelivered}.Validate())
+	assert.NoError(t, DeliveryReceipt{ExternalMessageID: "ext-1", Status: ReceiptUndelivered, Reason: "handset off"}.Validate())
+	assert.ErrorIs(t, DeliveryReceipt{Status: ReceiptDelivered}.Validate(), ErrInvalidReceipt)
+	assert.ErrorIs(t, DeliveryReceipt{ExternalMessageID: "ext-1", Status: "sent"}.Validate(), ErrInvalidReceipt)
+}
+
+func TestMessage_ApplyReceipt(t *testing.T) {
+	for _, status := range []string{"pending", "sending", "failed", "cancelled", ReceiptDelivered, ReceiptUndelivered} {
+		msg := &Message{Status: status}
+		assert.ErrorIs(t, msg.ApplyReceipt(DeliveryReceipt{Status: ReceiptDelivered}), ErrNotAwaitingReceipt, "status %s", status)
+		assert.Equal(t, status, msg.Status)
+	}
+
+	staleReason := "503 service unavailable"
+	delivered := &Message{Status: "sent", LastFailureReason: &staleReason}
+	require.NoError(t, delivered.ApplyReceipt(DeliveryReceipt{Status: ReceiptDelivered}))
+	assert.Equal(t, ReceiptDelivered, delivered.Status)
+	assert.Nil(t, delivered.LastFailureReason)
+	assert.False(t, delivered.UpdatedAt.IsZero())
+
+	undelivered := &Message{Status: "sent"}
+	require.NoError(t, undelivered.ApplyReceipt(DeliveryReceipt{Status: ReceiptUndelivered, Reason: "handset off"}))
+	assert.Equal(t, ReceiptUndelivered, undelivered.Status)
+	require.NotNil(t, undelivered.LastFailureReason)
+	assert.Equal(t, "handset off", *undelivered.LastFailureReason)
+}
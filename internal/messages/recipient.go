@@ -0,0 +1,103 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTimezone is returned for a time zone that is not a known IANA name.
+var ErrInvalidTimezone = fmt.Errorf("invalid time zone")
+
+// RecipientCounter counts the sends to each recipient over fixed windows.
+type RecipientCounter interface {
+	// CountRecipientSend records a send to recipient on channel in the window starting at windowStart
+	// and returns the number of sends recorded in that window so far, this one included.
+	CountRecipientSend(ctx context.Context, channel, recipient string, windowStart time.Time, window time.Duration) (int64, error)
+	// UncountRecipientSend removes a send recorded by CountRecipientSend in the window starting at windowStart
+	// that was not made after all, e.g. because the message was deferred or the send failed.
+	UncountRecipientSend(ctx context.Context, channel, recipient string, windowStart time.Time, window time.Duration) error
+}
+
+// RecipientPolicy limits how often and when each recipient is sent messages.
+// Messages held back by the policy stay 'pending' with a deferred reason and do not use an attempt.
+type RecipientPolicy struct {
+	// Sends allowed per recipient and channel in each window, zero for no limit.
+	MaxPerWindow int
+	// Length of the fixed windows the sends are counted over.
+	Window time.Duration
+	// Daily period during which messages other than critical ones are held back.
+	QuietHours QuietHours
+	// Time zone of recipients whose messages do not name one.
+	DefaultLocation *time.Location
+	// New messages are skipped if the recipient has a message with the same content created or sent
+	// within this window. Zero disables deduplication.
+	DedupWindow time.Duration
+}
+
+// QuietHours is a daily period, in the recipient's local time, during which non-critical messages are held back.
+// A period ending before it starts spans midnight.
+type QuietHours struct {
+	// Start and End are the local times of day, as offsets from midnight.
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseQuietHours parses a period from its start and end times of day in 24-hour "15:04" format.
+// Two empty times disable quiet hours.
+func ParseQuietHours(start, end string) (QuietHours, error) {
+	if start == "" && end == "" {
+		return QuietHours{}, nil
+	}
+	startOffset, err := parseTimeOfDay(start)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	endOffset, err := parseTimeOfDay(end)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	return QuietHours{Start: startOffset, End: endOffset}, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Enabled reports whether the period is set.
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+// Until reports whether t falls in quiet hours in loc and, if it does, returns the time they end.
+func (q QuietHours) Until(t time.Time, loc *time.Location) (time.Time, bool) {
+	if !q.Enabled() {
+		return time.Time{}, false
+	}
+	local := t.In(loc)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	// End of the period on the given day, built from the wall clock so DST changes are respected.
+	endOn := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days,
+			int(q.End/time.Hour), int(q.End%time.Hour/time.Minute), 0, 0, loc)
+	}
+
+	if q.Start < q.End {
+		if offset >= q.Start && offset < q.End {
+			return endOn(0), true
+		}
+		return time.Time{}, false
+	}
+	if offset >= q.Start {
+		return endOn(1), true
+	}
+	if offset < q.End {
+		return endOn(0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,58 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	quietHours, err := ParseQuietHours("21:30", "08:00")
+	require.NoError(t, err)
+	assert.Equal(t, QuietHours{Start: 21*time.Hour + 30*time.Minute, End: 8 * time.Hour}, quietHours)
+	assert.True(t, quietHours.Enabled())
+
+	disabled, err := ParseQuietHours("", "")
+	require.NoError(t, err)
+	assert.False(t, disabled.Enabled())
+
+	_, err = ParseQuietHours("21:00", "")
+	assert.Error(t, err)
+	_, err = ParseQuietHours("9pm", "08:00")
+	assert.Error(t, err)
+}
+
+func TestQuietHours_Until(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	overnight := QuietHours{Start: 21 * time.Hour, End: 8 * time.Hour}
+	daytime := QuietHours{Start: 12 * time.Hour, End: 14 * time.Hour}
+
+	tests := []struct {
+		name       string
+		quietHours QuietHours
+		at         time.Time
+		wantQuiet  bool
+		wantEnd    time.Time
+	}{
+		{"Before Midnight", overnight, time.Date(2025, 7, 9, 22, 15, 0, 0, berlin), true, time.Date(2025, 7, 10, 8, 0, 0, 0, berlin)},
+		{"After Midnight", overnight, time.Date(2025, 7, 10, 3, 0, 0, 0, berlin), true, time.Date(2025, 7, 10, 8, 0, 0, 0, berlin)},
+		{"Outside Overnight", overnight, time.Date(2025, 7, 10, 8, 0, 0, 0, berlin), false, time.Time{}},
+		{"Inside Daytime", daytime, time.Date(2025, 7, 10, 13, 0, 0, 0, berlin), true, time.Date(2025, 7, 10, 14, 0, 0, 0, berlin)},
+		{"Outside Daytime", daytime, time.Date(2025, 7, 10, 20, 0, 0, 0, berlin), false, time.Time{}},
+		// 20:30 UTC is 22:30 in Berlin during summer time.
+		{"Recipient Time Zone", overnight, time.Date(2025, 7, 9, 20, 30, 0, 0, time.UTC), true, time.Date(2025, 7, 10, 8, 0, 0, 0, berlin)},
+		// Clocks go back an hour overnight, quiet hours still end at 08:00 local time.
+		{"Across DST Change", overnight, time.Date(2025, 10, 25, 23, 0, 0, 0, berlin), true, time.Date(2025, 10, 26, 8, 0, 0, 0, berlin)},
+		{"Disabled", QuietHours{}, time.Date(2025, 7, 10, 3, 0, 0, 0, berlin), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, quiet := tt.quietHours.Until(tt.at, berlin)
+			assert.Equal(t, tt.wantQuiet, quiet)
+			assert.True(t, tt.wantEnd.Equal(end), "want %s, got %s", tt.wantEnd, end)
+		})
+	}
+}
@@ -1,18 +1,81 @@
 package messages
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // MessageRepository defines the contract on Message entities.
 type MessageRepository interface {
-	// GetPendingMessages retrieves a batch of unsent messages, up to the specified limit.
-	GetPendingMessages(ctx context.Context, limit int32) ([]Message, error)
+	// ClaimPendingMessages atomically moves a batch of unsent messages, up to the specified limit,
+	// to 'sending' and records instanceID as their owner. Messages claimed by another instance are skipped.
+	// The claim is only valid for the lease duration, after which the message can be recovered.
+	// Messages are claimed by priority, except for the last reserved slots which go to the oldest
+	// remaining messages so lower priorities keep moving.
+	ClaimPendingMessages(ctx context.Context, instanceID string, lease time.Duration, limit, reserved int32) ([]Message, error)
+
+	// RecoverExpiredMessages returns 'sending' messages with an expired lease to 'pending',
+	// or to 'failed' once they have already been recovered maxRecoveries times.
+	// The status callbacks of failed messages are queued along with the update.
+	RecoverExpiredMessages(ctx context.Context, maxRecoveries int32) (RecoveryResult, error)
 
 	// UpdateMessageStatus updates a message's status to sent and records its external message ID.
+	// Returns ErrLeaseLost if the message is no longer 'sending' under the claim of msg.ClaimedBy.
 	UpdateMessageStatus(ctx context.Context, msg Message) error
 
-	// GetSentMessages retrieves a paginated list of sent messages.
+	// ScheduleRetry persists a message returned to 'pending' by MarkForRetry, along with its next attempt time.
+	// Returns ErrLeaseLost if the message is no longer 'sending' under the claim of msg.ClaimedBy.
+	ScheduleRetry(ctx context.Context, msg Message) error
+
+	// ReleaseMessage persists a message returned to 'pending' by Release. The attempt counted
+	// when the message was claimed is given back, as no send was made.
+	// Returns ErrLeaseLost if the message is no longer 'sending' under the claim of msg.ClaimedBy.
+	ReleaseMessage(ctx context.Context, msg Message) error
+
+	// MoveToDeadLetter marks a message as 'failed' and copies it, with its attempt history, to the dead-letter queue.
+	// Returns ErrLeaseLost if the message is no longer 'sending' under the claim of msg.ClaimedBy.
+	MoveToDeadLetter(ctx context.Context, msg Message) error
+
+	// RecordAttempt stores the outcome of a single send attempt.
+	RecordAttempt(ctx context.Context, attempt Attempt) error
+
+	// GetDeadLetters retrieves a paginated list of dead-lettered messages.
+	GetDeadLetters(ctx context.Context, limit, offset int32) ([]DeadLetter, error)
+
+	// RequeueDeadLetter removes a message from the dead-letter queue and returns it to 'pending' with fresh attempts.
+	RequeueDeadLetter(ctx context.Context, messageID string) error
+
+	// GetMessageByID retrieves a single message with its attempt history.
+	// Returns ErrMessageNotFound if no message has the given ID.
+	GetMessageByID(ctx context.Context, messageID string) (*Message, error)
+
+	// CancelMessage persists a message cancelled by MarkAsCancelled. Returns ErrNotCancellable
+	// if the message is no longer 'pending', e.g. because a scheduler claimed it concurrently.
+	// The status callback of the message is queued along with the update.
+	CancelMessage(ctx context.Context, msg Message) error
+
+	// GetMessageIDByExternalID returns the ID of the latest message accepted by provider under externalMessageID.
+	// Returns ErrMessageNotFound if there is none.
+	GetMessageIDByExternalID(ctx context.Context, provider, externalMessageID string) (string, error)
+
+	// ApplyDeliveryReceipt persists a message updated by ApplyReceipt. Returns ErrNotAwaitingReceipt
+	// if the message is no longer 'sent', e.g. because a concurrent receipt was applied first.
+	ApplyDeliveryReceipt(ctx context.Context, msg Message) error
+
+	// CancelBatch cancels every 'pending' message of a batch and returns how many were cancelled.
+	// Returns ErrBatchNotFound if the batch has no messages. Status callbacks are queued along with the update.
+	CancelBatch(ctx context.Context, batchID string) (int, error)
+
+	// ListMessages retrieves the messages matching filter, most recently updated first.
+	ListMessages(ctx context.Context, filter MessageFilter) ([]Message, error)
+
+	// GetSentMessages retrieves a paginated list of sent messages, including those with a delivery receipt.
 	GetSentMessages(ctx context.Context, limit, offset int32) ([]Message, error)
 
 	// CreateMessages batch-inserts new messages into the database.
 	CreateMessages(ctx context.Context, msgs []*Message) error
+
+	// FindRecentDuplicates returns, by recipient, the ID of the latest message on channel with the same content
+	// created or sent since the given time. Failed, cancelled and undelivered messages are ignored.
+	FindRecentDuplicates(ctx context.Context, channel, content string, recipients []string, since time.Time) (map[string]string, error)
 }
@@ -0,0 +1,34 @@
+package messages
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how failed sends are retried.
+type RetryPolicy struct {
+	// Total number of send attempts allowed per message, including the first one.
+	MaxAttempts int
+	// Delay before the first retry. Each following retry doubles it.
+	BaseDelay time.Duration
+	// Upper bound for the delay between two attempts.
+	MaxDelay time.Duration
+	// Fraction (0-1) of the delay that is randomised to spread out retries.
+	Jitter float64
+}
+
+// Backoff returns the delay to wait after the given failed attempt (starting at 1).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	return delay
+}
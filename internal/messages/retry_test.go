@@ -0,0 +1,33 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Run("Exponential growth", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+		assert.Equal(t, 1*time.Second, policy.Backoff(1))
+		assert.Equal(t, 2*time.Second, policy.Backoff(2))
+		assert.Equal(t, 4*time.Second, policy.Backoff(3))
+		assert.Equal(t, 8*time.Second, policy.Backoff(4))
+	})
+
+	t.Run("Capped at max delay", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+		assert.Equal(t, 10*time.Second, policy.Backoff(5))
+		assert.Equal(t, 10*time.Second, policy.Backoff(100))
+	})
+
+	t.Run("Jitter stays within bounds", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: 10 * time.Second, MaxDelay: time.Minute, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			delay := policy.Backoff(1)
+			assert.GreaterOrEqual(t, delay, 5*time.Second)
+			assert.LessOrEqual(t, delay, 10*time.Second)
+		}
+	})
+}
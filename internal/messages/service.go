@@ -2,16 +2,24 @@ package messages
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"sync"
 	"time"
 
+	"github.com/akshaysangma/go-notify/internal/templates"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-// WebhookSender defines the contract for sending messages to an external webhook service.
-type WebhookSender interface {
-	Send(ctx context.Context, to, content string) (externalMessageID string, err error)
+// ClassifiedError is implemented by sender errors that know whether the failure is transient.
+type ClassifiedError interface {
+	error
+	// Retryable reports whether sending the same message again may succeed.
+	Retryable() bool
+	// RetryDelay returns the minimum delay requested by the provider before retrying, zero if none.
+	RetryDelay() time.Duration
 }
 
 // CacheService defines the contract for caching sent messages.
@@ -19,46 +27,109 @@ type CacheService interface {
 	CacheSentMessage(ctx context.Context, messageID, externalMessageID string, sentAt time.Time) error
 }
 
+// TemplateStore provides the templates messages are rendered from.
+type TemplateStore interface {
+	// GetTemplate returns templates.ErrTemplateNotFound if no template has the given ID.
+	GetTemplate(ctx context.Context, templateID string) (*templates.Template, error)
+}
+
+// StatusNotifier tells the creator of a message about its terminal status.
+// Cancellations and failures from expired leases are queued by the repository instead, in the same transaction.
+type StatusNotifier interface {
+	// NotifyStatus queues an event with the current status of msg for delivery to its callback URL.
+	NotifyStatus(ctx context.Context, msg Message) error
+}
+
+// RateLimiter paces outbound sends.
+type RateLimiter interface {
+	// Wait blocks until a send may go ahead, or returns an error once ctx is done.
+	Wait(ctx context.Context) error
+	// Available returns the number of sends that may go ahead without waiting.
+	Available() int
+}
+
 // MessageService implements the core business logic for message handling.
 type MessageService struct {
-	repo         MessageRepository
-	webhook      WebhookSender
-	logger       *zap.Logger
-	cacheService CacheService
-	workerCount  int
-	jobTimeout   time.Duration
+	repo            MessageRepository
+	senders         *SenderRegistry
+	logger          *zap.Logger
+	cacheService    CacheService
+	workerCount     int
+	jobTimeout      time.Duration
+	instanceID      string
+	lease           time.Duration
+	retryPolicy     RetryPolicy
+	scheduleHorizon time.Duration // how far ahead messages may be scheduled, zero for no limit
+	priorityReserve float64       // share of each batch kept for the oldest messages whatever their priority
+	limiter         RateLimiter   // paces sends across all channels, nil for no limit
+	recipientCount  RecipientCounter
+	recipientPolicy RecipientPolicy
+	templates       TemplateStore
+	notifier        StatusNotifier // delivers status events to callback URLs, nil if callbacks are disabled
 }
 
 func NewMessageService(
 	repo MessageRepository,
-	webhook WebhookSender,
+	senders *SenderRegistry,
 	logger *zap.Logger,
 	cacheService CacheService,
 	workerCount int,
 	jobTimeout time.Duration,
+	instanceID string,
+	lease time.Duration,
+	retryPolicy RetryPolicy,
+	scheduleHorizon time.Duration,
+	priorityReserve float64,
+	limiter RateLimiter,
+	recipientCount RecipientCounter,
+	recipientPolicy RecipientPolicy,
+	templates TemplateStore,
+	notifier StatusNotifier,
 ) *MessageService {
 	return &MessageService{
-		repo:         repo,
-		webhook:      webhook,
-		logger:       logger,
-		cacheService: cacheService,
-		workerCount:  workerCount,
-		jobTimeout:   jobTimeout,
+		repo:            repo,
+		senders:         senders,
+		logger:          logger,
+		cacheService:    cacheService,
+		workerCount:     workerCount,
+		jobTimeout:      jobTimeout,
+		instanceID:      instanceID,
+		lease:           lease,
+		retryPolicy:     retryPolicy,
+		scheduleHorizon: scheduleHorizon,
+		priorityReserve: priorityReserve,
+		limiter:         limiter,
+		recipientCount:  recipientCount,
+		recipientPolicy: recipientPolicy,
+		templates:       templates,
+		notifier:        notifier,
 	}
 }
 
-// FetchAndSendPending is called by the scheduler. It fetches pending messages
-// and uses a worker pool to process and send them concurrently.
-func (s *MessageService) FetchAndSendPending(ctx context.Context, limit int) error {
-	s.logger.Info("Fetching pending messages to process.", zap.Int("limit", limit))
-	pendingMsgs, err := s.repo.GetPendingMessages(ctx, int32(limit))
+// FetchAndSendPending is called by the scheduler. It claims pending messages for this
+// instance and uses a worker pool to process and send them concurrently.
+// Messages are claimed by priority, with a share of the batch reserved for the oldest messages.
+// With a rate limiter, no more messages are claimed than can be sent right away, and each send
+// waits for its turn. Returns the number of claimed messages.
+func (s *MessageService) FetchAndSendPending(ctx context.Context, limit int) (int, error) {
+	if s.limiter != nil {
+		// Claimed messages waiting for the limiter would hold their lease for nothing.
+		limit = min(limit, max(s.limiter.Available(), 1))
+	}
+	reserved := reservedSlots(limit, s.priorityReserve)
+	s.logger.Info("Claiming pending messages to process.",
+		zap.Int("limit", limit),
+		zap.Int("reserved", reserved),
+		zap.String("instance_id", s.instanceID),
+	)
+	pendingMsgs, err := s.repo.ClaimPendingMessages(ctx, s.instanceID, s.lease, int32(limit), int32(reserved))
 	if err != nil {
-		return fmt.Errorf("failed to get pending messages: %w", err)
+		return 0, fmt.Errorf("failed to claim pending messages: %w", err)
 	}
 
 	if len(pendingMsgs) == 0 {
 		s.logger.Info("No pending messages to process.")
-		return nil
+		return 0, nil
 	}
 
 	jobs := make(chan Message, len(pendingMsgs))
@@ -76,7 +147,7 @@ func (s *MessageService) FetchAndSendPending(ctx context.Context, limit int) err
 
 	wg.Wait()
 	s.logger.Info("Finished processing message batch.", zap.Int("processed_count", len(pendingMsgs)))
-	return nil
+	return len(pendingMsgs), nil
 }
 
 // worker represents a single routine that processes messages from the jobs channel.
@@ -107,41 +178,73 @@ func (s *MessageService) worker(ctx context.Context, wg *sync.WaitGroup, id int,
 func (s *MessageService) sendMessage(ctx context.Context, msg Message) error {
 	logFields := []zap.Field{
 		zap.String("message_id", msg.ID),
+		zap.String("channel", msg.Channel),
 		zap.String("recipient", msg.Recipient),
 	}
+	// The message was already moved to 'sending' when it was claimed, so no other
+	// worker or instance can pick it up.
 	s.logger.Info("Attempting to send message", logFields...)
 
-	// Mark the message as 'sending' to prevent other workers from picking it up.
-	msg.MarkAsSending()
-	if err := s.repo.UpdateMessageStatus(ctx, msg); err != nil {
-		s.logger.Error("Failed to mark message as 'sending'", append(logFields, zap.Error(err))...)
-		return fmt.Errorf("failed to update status to sending for message %s: %w", msg.ID, err)
+	reason, until, countedIn, held := s.holdBack(ctx, msg)
+	if held {
+		// Nothing is sent, so the message goes back to the queue without an attempt.
+		s.deferMessage(ctx, msg, reason, until, logFields)
+		return nil
 	}
 
-	externalMessageID, webhookErr := s.webhook.Send(ctx, msg.Recipient, msg.Content)
-	if webhookErr != nil {
-		s.logger.Error("Failed to send message via webhook", append(logFields, zap.Error(webhookErr))...)
-		msg.MarkAsFailed(fmt.Sprintf("webhook send failed: %v", webhookErr))
-		// Avoid shadowing the original webhookErr.
-		if updateErr := s.repo.UpdateMessageStatus(ctx, msg); updateErr != nil {
-			s.logger.Error("Failed to update message status to 'failed'", append(logFields, zap.Error(updateErr))...)
+	// The token is only taken for messages about to be sent, so held back messages do not use up the rate.
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			sendErr := fmt.Errorf("%w: rate limit: %w", ErrSenderUnavailable, err)
+			s.uncountRecipientSend(ctx, msg, countedIn, logFields)
+			s.releaseMessage(ctx, msg, sendErr, logFields)
+			return fmt.Errorf("message %s not sent: %w", msg.ID, sendErr)
 		}
-		return fmt.Errorf("failed to send message %s: %w", msg.ID, webhookErr)
 	}
 
-	s.logger.Info("Message successfully sent via webhook, marking as 'sent' in DB",
+	var externalMessageID, provider string
+	sender, sendErr := s.senders.Sender(msg.Channel)
+	if sendErr == nil {
+		externalMessageID, provider, sendErr = send(ctx, sender, msg)
+	}
+	if provider != "" {
+		logFields = append(logFields, zap.String("provider", provider))
+	}
+	if sendErr != nil {
+		// Only messages that reached the recipient count towards their limit.
+		s.uncountRecipientSend(ctx, msg, countedIn, logFields)
+	}
+	if errors.Is(sendErr, ErrSenderUnavailable) {
+		// Nothing was sent, so the message goes back to the queue without an attempt.
+		s.releaseMessage(ctx, msg, sendErr, logFields)
+		return fmt.Errorf("message %s not sent: %w", msg.ID, sendErr)
+	}
+	s.recordAttempt(ctx, msg, externalMessageID, provider, sendErr)
+	if sendErr != nil {
+		s.logger.Error("Failed to send message", append(logFields, zap.Error(sendErr))...)
+		s.handleSendFailure(ctx, msg, sendErr, logFields)
+		return fmt.Errorf("failed to send message %s: %w", msg.ID, sendErr)
+	}
+
+	s.logger.Info("Message successfully sent, marking as 'sent' in DB",
 		append(logFields, zap.String("external_id", externalMessageID))...)
 
 	msg.MarkAsSent(externalMessageID)
-	// If the webhook send succeeded but this DB update fails, the message remains
-	// in the 'sending' state and will be retried.
+	msg.Provider = provider
+	// If the send succeeded but this DB update fails, the message remains
+	// in the 'sending' state until its lease expires and the recovery sweeper retries it.
 	if err := s.repo.UpdateMessageStatus(ctx, msg); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			s.logLeaseLost(msg, logFields)
+			return nil
+		}
 		s.logger.Error("Failed to mark message as 'sent' in DB after successful send", append(logFields, zap.Error(err))...)
 		return fmt.Errorf("failed to mark message %s as sent in DB: %w", msg.ID, err)
 	}
 
 	s.logger.Info("Message successfully processed and marked as sent",
 		append(logFields, zap.String("external_id", externalMessageID))...)
+	s.notifyStatus(ctx, msg, logFields)
 
 	// try to cache
 	cacheCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -157,6 +260,233 @@ func (s *MessageService) sendMessage(ctx context.Context, msg Message) error {
 	return nil
 }
 
+// send delivers msg through sender, including its subject when the sender supports one.
+// The provider is only reported by senders spread over several providers.
+func send(ctx context.Context, sender Sender, msg Message) (externalMessageID, provider string, err error) {
+	if subjectSender, ok := sender.(SubjectSender); ok && msg.Subject != "" {
+		return subjectSender.SendWithSubject(ctx, msg.Recipient, msg.Subject, msg.Content)
+	}
+	if providerSender, ok := sender.(ProviderSender); ok {
+		return providerSender.SendWithProvider(ctx, msg.Recipient, msg.Content)
+	}
+	externalMessageID, err = sender.Send(ctx, msg.Recipient, msg.Content)
+	return externalMessageID, "", err
+}
+
+// holdBack reports whether the recipient policy keeps msg from being sent now, and if so why and until when.
+// Otherwise countedIn is the start of the window the send was counted in, zero if it was not counted,
+// so the count can be given back with uncountRecipientSend if the send is not made.
+// Quiet hours are checked first, so messages held back by them do not count towards the recipient limit.
+// Critical messages are exempt from both.
+func (s *MessageService) holdBack(ctx context.Context, msg Message) (reason string, until, countedIn time.Time, held bool) {
+	now := time.Now()
+	policy := s.recipientPolicy
+	if msg.Priority == PriorityCritical {
+		return "", time.Time{}, time.Time{}, false
+	}
+
+	if policy.QuietHours.Enabled() {
+		loc := s.recipientLocation(msg)
+		if end, quiet := policy.QuietHours.Until(now, loc); quiet {
+			return fmt.Sprintf("quiet hours in %s until %s", loc, end.Format(time.RFC3339)), end, time.Time{}, true
+		}
+	}
+
+	if policy.MaxPerWindow > 0 && s.recipientCount != nil {
+		// The send is counted before it is made, so concurrent workers cannot exceed the limit together.
+		windowStart := now.Truncate(policy.Window)
+		count, err := s.recipientCount.CountRecipientSend(ctx, msg.Channel, msg.Recipient, windowStart, policy.Window)
+		if err != nil {
+			// An unavailable counter should not stop delivery.
+			s.logger.Warn("Failed to count recipient sends, sending without limit", zap.String("message_id", msg.ID), zap.Error(err))
+			return "", time.Time{}, time.Time{}, false
+		}
+		if count > int64(policy.MaxPerWindow) {
+			// Held back messages must not use up the window of the recipient either.
+			s.uncountRecipientSend(ctx, msg, windowStart, []zap.Field{zap.String("message_id", msg.ID)})
+			windowEnd := windowStart.Add(policy.Window).UTC()
+			return fmt.Sprintf("recipient limit of %d messages per %s reached until %s", policy.MaxPerWindow, policy.Window, windowEnd.Format(time.RFC3339)), windowEnd, time.Time{}, true
+		}
+		return "", time.Time{}, windowStart, false
+	}
+	return "", time.Time{}, time.Time{}, false
+}
+
+// uncountRecipientSend gives back a send counted by holdBack in the window starting at windowStart,
+// if any, for a message that was not sent. Failing to do so is only logged, the count expires with its window.
+func (s *MessageService) uncountRecipientSend(ctx context.Context, msg Message, windowStart time.Time, logFields []zap.Field) {
+	if windowStart.IsZero() {
+		return
+	}
+	if err := s.recipientCount.UncountRecipientSend(ctx, msg.Channel, msg.Recipient, windowStart, s.recipientPolicy.Window); err != nil {
+		s.logger.Warn("Failed to give back recipient send count", append(logFields, zap.Error(err))...)
+	}
+}
+
+// recipientLocation returns the time zone of the recipient of msg, or the default one if the message has none.
+func (s *MessageService) recipientLocation(msg Message) *time.Location {
+	if msg.Timezone != "" {
+		// Time zones are validated when messages are created.
+		if loc, err := time.LoadLocation(msg.Timezone); err == nil {
+			return loc
+		}
+	}
+	if s.recipientPolicy.DefaultLocation != nil {
+		return s.recipientPolicy.DefaultLocation
+	}
+	return time.UTC
+}
+
+// releaseMessage returns a message its sender refused to send to 'pending', deferred by the delay
+// the sender asked for if any.
+func (s *MessageService) releaseMessage(ctx context.Context, msg Message, sendErr error, logFields []zap.Field) {
+	_, retryDelay := classifySendError(sendErr)
+	s.deferMessage(ctx, msg, fmt.Sprintf("%s send deferred: %v", msg.Channel, sendErr), time.Now().UTC().Add(retryDelay), logFields)
+}
+
+// deferMessage returns a claimed message that was not sent to 'pending' until nextAttemptAt, recording why.
+func (s *MessageService) deferMessage(ctx context.Context, msg Message, reason string, nextAttemptAt time.Time, logFields []zap.Field) {
+	msg.Release(reason, nextAttemptAt)
+	if err := s.repo.ReleaseMessage(ctx, msg); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			s.logLeaseLost(msg, logFields)
+			return
+		}
+		s.logger.Error("Failed to release message", append(logFields, zap.Error(err))...)
+		return
+	}
+	s.logger.Warn("Message deferred without an attempt", append(logFields,
+		zap.String("reason", reason),
+		zap.Time("next_attempt_at", nextAttemptAt),
+	)...)
+}
+
+// handleSendFailure schedules a retry with exponential backoff while the failure is transient
+// and the message has attempts left, otherwise it moves the message to the dead-letter queue.
+func (s *MessageService) handleSendFailure(ctx context.Context, msg Message, sendErr error, logFields []zap.Field) {
+	reason := fmt.Sprintf("%s send failed: %v", msg.Channel, sendErr)
+	retryable, retryDelay := classifySendError(sendErr)
+	logFields = append(logFields,
+		zap.Int("attempt", msg.AttemptCount),
+		zap.Int("max_attempts", msg.MaxAttempts),
+		zap.Bool("retryable", retryable),
+	)
+
+	if retryable && msg.CanRetry() {
+		// Honour the provider's Retry-After when it asks for a longer pause than our backoff.
+		delay := max(s.retryPolicy.Backoff(msg.AttemptCount), retryDelay)
+		nextAttemptAt := time.Now().UTC().Add(delay)
+		msg.MarkForRetry(reason, nextAttemptAt)
+		// Avoid shadowing the original send error.
+		if updateErr := s.repo.ScheduleRetry(ctx, msg); updateErr != nil {
+			if errors.Is(updateErr, ErrLeaseLost) {
+				s.logLeaseLost(msg, logFields)
+				return
+			}
+			s.logger.Error("Failed to schedule message retry", append(logFields, zap.Error(updateErr))...)
+			return
+		}
+		s.logger.Warn("Scheduled message retry", append(logFields, zap.Time("next_attempt_at", nextAttemptAt))...)
+		return
+	}
+
+	msg.MarkAsFailed(reason)
+	if updateErr := s.repo.MoveToDeadLetter(ctx, msg); updateErr != nil {
+		if errors.Is(updateErr, ErrLeaseLost) {
+			s.logLeaseLost(msg, logFields)
+			return
+		}
+		s.logger.Error("Failed to move message to dead-letter queue", append(logFields, zap.Error(updateErr))...)
+		return
+	}
+	s.logger.Warn("Message exhausted its attempts and was moved to the dead-letter queue", logFields...)
+	s.notifyStatus(ctx, msg, logFields)
+}
+
+// notifyStatus queues a status event for the callback URL of msg, if it has one.
+// The status change is already stored, so failing to queue the event is only logged.
+func (s *MessageService) notifyStatus(ctx context.Context, msg Message, logFields []zap.Field) {
+	if msg.CallbackURL == "" || s.notifier == nil {
+		return
+	}
+	if err := s.notifier.NotifyStatus(ctx, msg); err != nil {
+		s.logger.Error("Failed to queue status callback", append(logFields, zap.Error(err))...)
+	}
+}
+
+// validateCallbackURL checks that callbackURL is an absolute http or https URL and that callbacks are enabled.
+// URLs pointing at loopback, link-local or private addresses are rejected, so callbacks cannot reach internal services.
+func (s *MessageService) validateCallbackURL(callbackURL string) error {
+	if s.notifier == nil {
+		return fmt.Errorf("%w: callbacks are not configured", ErrInvalidCallbackURL)
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidCallbackURL, callbackURL)
+	}
+	if !isPublicHost(u.Hostname()) {
+		return fmt.Errorf("%w: %q is not a public host", ErrInvalidCallbackURL, u.Hostname())
+	}
+	return nil
+}
+
+// logLeaseLost records that the outcome of msg was not stored because its lease expired while it was processed.
+// The message was recovered by the sweeper, and possibly claimed again, so its current state is left alone.
+func (s *MessageService) logLeaseLost(msg Message, logFields []zap.Field) {
+	s.logger.Warn("Message lease expired during processing, outcome not stored",
+		append(logFields, zap.String("status", msg.Status), zap.String("claimed_by", msg.ClaimedBy))...)
+}
+
+// classifySendError reports whether a send error is worth retrying and the delay requested by the provider.
+// Validation errors and unsupported channels are permanent; unclassified errors are assumed to be transient.
+func classifySendError(err error) (retryable bool, retryDelay time.Duration) {
+	var classified ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Retryable(), classified.RetryDelay()
+	}
+	if errors.Is(err, ErrContentTooLong) || errors.Is(err, ErrRecipientEmpty) || errors.Is(err, ErrUnsupportedChannel) {
+		return false, 0
+	}
+	return true, 0
+}
+
+// recordAttempt stores the outcome of a send attempt. Failing to record it does not fail the send.
+func (s *MessageService) recordAttempt(ctx context.Context, msg Message, externalMessageID, provider string, sendErr error) {
+	attempt := Attempt{
+		MessageID:     msg.ID,
+		AttemptNumber: msg.AttemptCount,
+		InstanceID:    s.instanceID,
+		Succeeded:     sendErr == nil,
+		Provider:      provider,
+	}
+	if sendErr != nil {
+		errMsg := sendErr.Error()
+		attempt.Error = &errMsg
+	} else if externalMessageID != "" {
+		attempt.ExternalMessageID = &externalMessageID
+	}
+
+	if err := s.repo.RecordAttempt(ctx, attempt); err != nil {
+		s.logger.Warn("Failed to record message attempt", zap.String("message_id", msg.ID), zap.Error(err))
+	}
+}
+
+// RecoverExpiredMessages is called by the recovery sweeper. It releases messages left in
+// 'sending' by a crashed or timed-out worker once their lease has expired.
+func (s *MessageService) RecoverExpiredMessages(ctx context.Context, maxRecoveries int) (RecoveryResult, error) {
+	result, err := s.repo.RecoverExpiredMessages(ctx, int32(maxRecoveries))
+	if err != nil {
+		return result, fmt.Errorf("failed to recover expired messages: %w", err)
+	}
+	if result.Recovered > 0 || result.Failed > 0 {
+		s.logger.Warn("Recovered messages with expired lease",
+			zap.Int("recovered_count", result.Recovered),
+			zap.Int("failed_count", result.Failed),
+		)
+	}
+	return result, nil
+}
+
 // GetAllSentMessages take limit and offset to return paginated sent message from database.
 func (s *MessageService) GetAllSentMessages(ctx context.Context, limit, offset int32) ([]Message, error) {
 	s.logger.Debug("Attempting to retrieve sent messages", zap.Int32("limit", limit), zap.Int32("offset", offset))
@@ -174,27 +504,296 @@ func (s *MessageService) GetAllSentMessages(ctx context.Context, limit, offset i
 	return msgs, nil
 }
 
-// CreateMessages insert a message for multiple recipients in the database
-func (s *MessageService) CreateMessages(ctx context.Context, content string, recipients []string, charLimit int) error {
+// GetDeadLetters take limit and offset to return paginated dead-lettered messages from database.
+func (s *MessageService) GetDeadLetters(ctx context.Context, limit, offset int32) ([]DeadLetter, error) {
+	deadLetters, err := s.repo.GetDeadLetters(ctx, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to retrieve dead-letter messages", zap.Error(err), zap.Int32("limit", limit), zap.Int32("offset", offset))
+		return nil, fmt.Errorf("failed to get dead-letter messages: %w", err)
+	}
+
+	if deadLetters == nil {
+		return []DeadLetter{}, nil
+	}
+
+	return deadLetters, nil
+}
+
+// RequeueDeadLetter returns a dead-lettered message to the queue with a fresh set of attempts.
+func (s *MessageService) RequeueDeadLetter(ctx context.Context, messageID string) error {
+	if err := s.repo.RequeueDeadLetter(ctx, messageID); err != nil {
+		return fmt.Errorf("failed to requeue message %s: %w", messageID, err)
+	}
+	s.logger.Info("Requeued dead-letter message", zap.String("message_id", messageID))
+	return nil
+}
+
+// GetMessageByID retrieves a message, including its delivery history.
+func (s *MessageService) GetMessageByID(ctx context.Context, messageID string) (*Message, error) {
+	msg, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message %s: %w", messageID, err)
+	}
+	return msg, nil
+}
+
+// CancelMessage cancels a message that has not been sent yet.
+// Returns ErrMessageNotFound if it does not exist and ErrNotCancellable if it is no longer 'pending'.
+func (s *MessageService) CancelMessage(ctx context.Context, messageID string) (*Message, error) {
+	msg, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message %s: %w", messageID, err)
+	}
+	if err := msg.MarkAsCancelled(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CancelMessage(ctx, *msg); err != nil {
+		return nil, fmt.Errorf("failed to cancel message %s: %w", messageID, err)
+	}
+
+	s.logger.Info("Cancelled message", zap.String("message_id", messageID))
+	return msg, nil
+}
+
+// ApplyDeliveryReceipt moves the message provider accepted under the receipt's external ID to its delivery state
+// and notifies its callback URL. A repeated receipt returns the message unchanged.
+func (s *MessageService) ApplyDeliveryReceipt(ctx context.Context, provider string, receipt DeliveryReceipt) (*Message, error) {
+	if err := receipt.Validate(); err != nil {
+		return nil, err
+	}
+	messageID, err := s.repo.GetMessageIDByExternalID(ctx, provider, receipt.ExternalMessageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message %s of provider %s: %w", receipt.ExternalMessageID, provider, err)
+	}
+	msg, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message %s: %w", messageID, err)
+	}
+	if msg.Status == receipt.Status {
+		return msg, nil
+	}
+	if err := msg.ApplyReceipt(receipt); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ApplyDeliveryReceipt(ctx, *msg); err != nil {
+		return nil, fmt.Errorf("failed to apply delivery receipt to message %s: %w", messageID, err)
+	}
+
+	logFields := []zap.Field{zap.String("message_id", messageID), zap.String("provider", provider), zap.String("status", msg.Status)}
+	s.logger.Info("Applied delivery receipt", logFields...)
+	s.notifyStatus(ctx, *msg, logFields)
+	return msg, nil
+}
+
+// CancelBatch cancels every message of a batch that has not been sent yet and returns how many were cancelled.
+func (s *MessageService) CancelBatch(ctx context.Context, batchID string) (int, error) {
+	cancelled, err := s.repo.CancelBatch(ctx, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel batch %s: %w", batchID, err)
+	}
+
+	s.logger.Info("Cancelled batch", zap.String("batch_id", batchID), zap.Int("cancelled_count", cancelled))
+	return cancelled, nil
+}
+
+// ListMessages retrieves a page of messages matching filter.
+// NextCursor of the result is set when more messages may follow.
+func (s *MessageService) ListMessages(ctx context.Context, filter MessageFilter) (MessagePage, error) {
+	for _, status := range filter.Statuses {
+		if !ValidStatus(status) {
+			return MessagePage{}, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+		}
+	}
+
+	// Fetch one extra message to know whether there is a next page.
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	msgs, err := s.repo.ListMessages(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list messages", zap.Error(err))
+		return MessagePage{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	page := MessagePage{Messages: msgs}
+	if page.Messages == nil {
+		page.Messages = []Message{}
+	}
+	if len(page.Messages) > int(limit) {
+		page.Messages = page.Messages[:limit]
+		last := page.Messages[len(page.Messages)-1]
+		page.NextCursor = Cursor{UpdatedAt: last.UpdatedAt, ID: last.ID}.Encode()
+	}
+	return page, nil
+}
+
+// dedupKey identifies messages considered duplicates of each other.
+type dedupKey struct {
+	recipient string
+	content   string
+}
+
+// dropDuplicates removes the messages whose recipient already has a message with the same content created or sent
+// within the dedup window, or earlier in msgs, and returns the remaining messages along with the duplicates.
+// Messages rendered from a template are looked up once per distinct content.
+// Two concurrent requests may still both create the same message; Idempotency-Key covers retried requests.
+func (s *MessageService) dropDuplicates(ctx context.Context, channel string, msgs []*Message, now time.Time) ([]*Message, []Duplicate, error) {
+	var contents []string
+	recipients := make(map[string][]string)
+	for _, msg := range msgs {
+		if _, ok := recipients[msg.Content]; !ok {
+			contents = append(contents, msg.Content)
+		}
+		recipients[msg.Content] = append(recipients[msg.Content], msg.Recipient)
+	}
+
+	existing := make(map[dedupKey]string, len(msgs))
+	for _, content := range contents {
+		found, err := s.repo.FindRecentDuplicates(ctx, channel, content, recipients[content], now.Add(-s.recipientPolicy.DedupWindow))
+		if err != nil {
+			s.logger.Error("Failed to look up duplicate messages", zap.Error(err))
+			return nil, nil, fmt.Errorf("failed to look up duplicate messages: %w", err)
+		}
+		for recipient, messageID := range found {
+			existing[dedupKey{recipient: recipient, content: content}] = messageID
+		}
+	}
+
+	var kept []*Message
+	var duplicates []Duplicate
+	for _, msg := range msgs {
+		key := dedupKey{recipient: msg.Recipient, content: msg.Content}
+		if messageID, ok := existing[key]; ok {
+			duplicates = append(duplicates, Duplicate{Recipient: msg.Recipient, MessageID: messageID})
+			continue
+		}
+		existing[key] = msg.ID
+		kept = append(kept, msg)
+	}
+	return kept, duplicates, nil
+}
+
+// loadTemplate fetches and compiles the template of req, nil if the request does not use one.
+func (s *MessageService) loadTemplate(ctx context.Context, req BatchRequest) (*templates.Compiled, error) {
+	if req.TemplateID == "" {
+		return nil, nil
+	}
+	if req.Content != "" {
+		return nil, ErrContentAndTemplate
+	}
+	if s.templates == nil {
+		return nil, fmt.Errorf("%w: templates are not configured", templates.ErrTemplateNotFound)
+	}
+	tmpl, err := s.templates.GetTemplate(ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Compile()
+}
+
+// CreateMessages insert a message for multiple recipients in the database.
+// All messages share a batch ID, which is returned along with the created messages.
+// Every recipient must be an address on the channel, see ValidateRecipient.
+// Requests naming a template have its subject and body rendered with the variables of each recipient,
+// and the rendered content of every recipient must fit the character limit of the channel:
+// smsCharLimit for sms, see CharacterLimit for the others.
+// With deduplication enabled, recipients that already have a message with the same content are skipped
+// and reported as duplicates of that message.
+func (s *MessageService) CreateMessages(ctx context.Context, req BatchRequest, smsCharLimit int) (*Batch, error) {
+	if _, err := PriorityRank(req.Priority); err != nil {
+		return nil, err
+	}
+	channel := req.Channel
+	if channel == "" {
+		channel = ChannelSMS
+	}
+	if _, err := s.senders.Sender(channel); err != nil {
+		return nil, err
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, req.Timezone)
+		}
+	}
+	if req.CallbackURL != "" {
+		if err := s.validateCallbackURL(req.CallbackURL); err != nil {
+			return nil, err
+		}
+	}
+	compiled, err := s.loadTemplate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	charLimit := CharacterLimit(channel, smsCharLimit)
+	batch := &Batch{ID: uuid.New().String(), Messages: []Message{}}
+	now := time.Now()
+
 	var msgsToCreate []*Message
-	for _, recipient := range recipients {
+	for _, recipient := range req.Recipients {
+		if recipient != "" {
+			if err := ValidateRecipient(channel, recipient); err != nil {
+				return nil, err
+			}
+		}
+		subject, content := req.Subject, req.Content
+		if compiled != nil {
+			var renderedSubject string
+			renderedSubject, content, err = compiled.Render(req.Variables[recipient])
+			if err != nil {
+				return nil, fmt.Errorf("invalid message for recipient %s: %w", recipient, err)
+			}
+			if subject == "" {
+				subject = renderedSubject
+			}
+		}
 		msg, err := NewMessage(content, recipient, charLimit)
 		if err != nil {
-			return fmt.Errorf("invalid message for recipients %v: %w", recipients, err)
+			if compiled != nil {
+				return nil, fmt.Errorf("invalid message for recipient %s: %w", recipient, err)
+			}
+			return nil, fmt.Errorf("invalid message for recipients %v: %w", req.Recipients, err)
+		}
+		if req.SendAt != nil {
+			if err := msg.ScheduleAt(*req.SendAt, now, s.scheduleHorizon); err != nil {
+				return nil, fmt.Errorf("invalid message for recipients %v: %w", req.Recipients, err)
+			}
+		}
+		if s.retryPolicy.MaxAttempts > 0 {
+			msg.MaxAttempts = s.retryPolicy.MaxAttempts
 		}
+		if req.Priority != "" {
+			msg.Priority = req.Priority
+		}
+		msg.Channel = channel
+		msg.Subject = subject
+		msg.Timezone = req.Timezone
+		msg.CallbackURL = req.CallbackURL
+		msg.BatchID = batch.ID
 		msgsToCreate = append(msgsToCreate, msg)
 	}
 
+	if s.recipientPolicy.DedupWindow > 0 && len(msgsToCreate) > 0 {
+		msgsToCreate, batch.Deduplicated, err = s.dropDuplicates(ctx, channel, msgsToCreate, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch.Deduplicated) > 0 {
+			s.logger.Info("Skipped duplicate messages", zap.String("batch_id", batch.ID), zap.Int("deduplicated_count", len(batch.Deduplicated)))
+		}
+	}
+
 	if len(msgsToCreate) == 0 {
-		return nil
+		return batch, nil
 	}
 
-	err := s.repo.CreateMessages(ctx, msgsToCreate)
-	if err != nil {
+	if err := s.repo.CreateMessages(ctx, msgsToCreate); err != nil {
 		s.logger.Error("Failed to bulk insert messages", zap.Error(err))
-		return fmt.Errorf("could not save messages: %w", err)
+		return nil, fmt.Errorf("could not save messages: %w", err)
 	}
 
-	s.logger.Info("Successfully created messages for multiple recipients", zap.Int("count", len(msgsToCreate)))
-	return nil
+	for _, msg := range msgsToCreate {
+		batch.Messages = append(batch.Messages, *msg)
+	}
+
+	s.logger.Info("Successfully created messages for multiple recipients", zap.String("batch_id", batch.ID), zap.Int("count", len(msgsToCreate)))
+	return batch, nil
 }
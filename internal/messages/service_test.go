@@ -3,11 +3,16 @@ package messages
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/akshaysangma/go-notify/internal/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -16,36 +21,171 @@ type MockMessageRepository struct {
 	mock.Mock
 }
 
-func (m *MockMessageRepository) GetPendingMessages(ctx context.Context, limit int32) ([]Message, error) {
-	args := m.Called(ctx, limit)
+func (m *MockMessageRepository) ClaimPendingMessages(ctx context.Context, instanceID string, lease time.Duration, limit, reserved int32) ([]Message, error) {
+	args := m.Called(ctx, instanceID, lease, limit, reserved)
 	return args.Get(0).([]Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) RecoverExpiredMessages(ctx context.Context, maxRecoveries int32) (RecoveryResult, error) {
+	args := m.Called(ctx, maxRecoveries)
+	return args.Get(0).(RecoveryResult), args.Error(1)
+}
+
 func (m *MockMessageRepository) UpdateMessageStatus(ctx context.Context, msg Message) error {
 	args := m.Called(ctx, msg)
 	return args.Error(0)
 }
 
+func (m *MockMessageRepository) ScheduleRetry(ctx context.Context, msg Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ReleaseMessage(ctx context.Context, msg Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) MoveToDeadLetter(ctx context.Context, msg Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) RecordAttempt(ctx context.Context, attempt Attempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetDeadLetters(ctx context.Context, limit, offset int32) ([]DeadLetter, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]DeadLetter), args.Error(1)
+}
+
+func (m *MockMessageRepository) RequeueDeadLetter(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
 func (m *MockMessageRepository) GetSentMessages(ctx context.Context, limit, offset int32) ([]Message, error) {
 	args := m.Called(ctx, limit, offset)
 	return args.Get(0).([]Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) GetMessageByID(ctx context.Context, messageID string) (*Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListMessages(ctx context.Context, filter MessageFilter) ([]Message, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CancelMessage(ctx context.Context, msg Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetMessageIDByExternalID(ctx context.Context, provider, externalMessageID string) (string, error) {
+	args := m.Called(ctx, provider, externalMessageID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) ApplyDeliveryReceipt(ctx context.Context, msg Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) CancelBatch(ctx context.Context, batchID string) (int, error) {
+	args := m.Called(ctx, batchID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockMessageRepository) CreateMessages(ctx context.Context, msgs []*Message) error {
 	args := m.Called(ctx, msgs)
 	return args.Error(0)
 }
 
-// MockWebhookSender is a mock of WebhookSender
-type MockWebhookSender struct {
+func (m *MockMessageRepository) FindRecentDuplicates(ctx context.Context, channel, content string, recipients []string, since time.Time) (map[string]string, error) {
+	args := m.Called(ctx, channel, content, recipients, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+// MockSender is a mock of Sender
+type MockSender struct {
 	mock.Mock
 }
 
-func (m *MockWebhookSender) Send(ctx context.Context, to, content string) (string, error) {
+func (m *MockSender) Send(ctx context.Context, to, content string) (string, error) {
 	args := m.Called(ctx, to, content)
 	return args.String(0), args.Error(1)
 }
 
+// MockSubjectSender is a mock of a Sender that also implements SubjectSender
+type MockSubjectSender struct {
+	MockSender
+}
+
+func (m *MockSubjectSender) SendWithSubject(ctx context.Context, to, subject, content string) (string, string, error) {
+	args := m.Called(ctx, to, subject, content)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+// MockProviderSender is a mock of a Sender that also implements ProviderSender
+type MockProviderSender struct {
+	MockSender
+}
+
+func (m *MockProviderSender) SendWithProvider(ctx context.Context, to, content string) (string, string, error) {
+	args := m.Called(ctx, to, content)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+// fakeRateLimiter is a test implementation of RateLimiter counting the sends it let through.
+type fakeRateLimiter struct {
+	available int
+	err       error
+	waits     atomic.Int32
+}
+
+func (l *fakeRateLimiter) Wait(ctx context.Context) error {
+	l.waits.Add(1)
+	return l.err
+}
+
+func (l *fakeRateLimiter) Available() int { return l.available }
+
+// classifiedError is a test implementation of ClassifiedError.
+type classifiedError struct {
+	retryable  bool
+	retryDelay time.Duration
+}
+
+func (e classifiedError) Error() string             { return "classified error" }
+func (e classifiedError) Retryable() bool           { return e.retryable }
+func (e classifiedError) RetryDelay() time.Duration { return e.retryDelay }
+
+// MockRecipientCounter is a mock of RecipientCounter
+type MockRecipientCounter struct {
+	mock.Mock
+}
+
+func (m *MockRecipientCounter) CountRecipientSend(ctx context.Context, channel, recipient string, windowStart time.Time, window time.Duration) (int64, error) {
+	args := m.Called(ctx, channel, recipient, windowStart, window)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRecipientCounter) UncountRecipientSend(ctx context.Context, channel, recipient string, windowStart time.Time, window time.Duration) error {
+	args := m.Called(ctx, channel, recipient, windowStart, window)
+	return args.Error(0)
+}
+
 // MockCacheService is a mock of CacheService
 type MockCacheService struct {
 	mock.Mock
@@ -56,66 +196,709 @@ func (m *MockCacheService) CacheSentMessage(ctx context.Context, messageID, exte
 	return args.Error(0)
 }
 
+// MockTemplateStore is a mock of TemplateStore
+type MockTemplateStore struct {
+	mock.Mock
+}
+
+func (m *MockTemplateStore) GetTemplate(ctx context.Context, templateID string) (*templates.Template, error) {
+	args := m.Called(ctx, templateID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*templates.Template), args.Error(1)
+}
+
+// MockStatusNotifier is a mock of StatusNotifier
+type MockStatusNotifier struct {
+	mock.Mock
+}
+
+func (m *MockStatusNotifier) NotifyStatus(ctx context.Context, msg Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
 func TestMessageService_FetchAndSendPending(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookSender)
+	mockSender := new(MockSender)
+	senders := NewSenderRegistry()
+	senders.Register(ChannelSMS, mockSender)
 	mockCache := new(MockCacheService)
 	logger := zap.NewNop()
-	service := NewMessageService(mockRepo, mockWebhook, logger, mockCache, 2, 10*time.Second)
+	service := NewMessageService(mockRepo, senders, logger, mockCache, 2, 10*time.Second, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
 
-	pendingMsg := Message{ID: "msg1", Content: "test", Recipient: "+123", Status: "pending"}
+	claimedMsg := Message{ID: "msg1", Channel: ChannelSMS, Content: "test", Recipient: "+123", Status: "sending"}
 
 	t.Run("Success Case", func(t *testing.T) {
-		mockRepo.On("GetPendingMessages", mock.Anything, int32(10)).Return([]Message{pendingMsg}, nil).Once()
-		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
-			return m.ID == pendingMsg.ID && m.Status == "sending"
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(10), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("ext-123", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(a Attempt) bool {
+			return a.MessageID == claimedMsg.ID && a.Succeeded && *a.ExternalMessageID == "ext-123" && a.InstanceID == "instance-1"
 		})).Return(nil).Once()
-		mockWebhook.On("Send", mock.Anything, pendingMsg.Recipient, pendingMsg.Content).Return("ext-123", nil).Once()
 		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
-			return m.ID == pendingMsg.ID && m.Status == "sent"
+			return m.ID == claimedMsg.ID && m.Status == "sent"
 		})).Return(nil).Once()
-		mockCache.On("CacheSentMessage", mock.Anything, pendingMsg.ID, "ext-123", mock.Anything).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, claimedMsg.ID, "ext-123", mock.Anything).Return(nil).Once()
 
-		err := service.FetchAndSendPending(context.Background(), 10)
+		_, err := service.FetchAndSendPending(context.Background(), 10)
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
-		mockWebhook.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
 		mockCache.AssertExpectations(t)
 	})
 
 	t.Run("No Pending Messages", func(t *testing.T) {
-		mockRepo.On("GetPendingMessages", mock.Anything, int32(5)).Return([]Message{}, nil).Once()
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(5), int32(0)).Return([]Message{}, nil).Once()
 
-		err := service.FetchAndSendPending(context.Background(), 5)
+		_, err := service.FetchAndSendPending(context.Background(), 5)
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 		// Ensure other mocks were not called
-		mockWebhook.AssertNotCalled(t, "Send")
+		mockSender.AssertNotCalled(t, "Send")
+	})
+
+	t.Run("Rate Limited Claim", func(t *testing.T) {
+		limiter := &fakeRateLimiter{available: 1}
+		limitedService := NewMessageService(mockRepo, senders, logger, mockCache, 2, 10*time.Second, "instance-1", time.Minute, RetryPolicy{}, 0, 0, limiter, nil, RecipientPolicy{}, nil, nil)
+
+		// Only as many messages as the limiter lets through right away are claimed.
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("ext1", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, claimedMsg.ID, "ext1", mock.Anything).Return(nil).Once()
+
+		claimed, err := limitedService.FetchAndSendPending(context.Background(), 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claimed)
+		assert.Equal(t, int32(1), limiter.waits.Load())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Rate Limit Wait Fails - Released Without Attempt", func(t *testing.T) {
+		limiter := &fakeRateLimiter{available: 1, err: errors.New("rate limit wait would exceed the context deadline")}
+		limitedService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute, RetryPolicy{}, 0, 0, limiter, nil, RecipientPolicy{}, nil, nil)
+		limitedMsg := claimedMsg
+		limitedMsg.AttemptCount = 1
+		limitedMsg.Content = "rate limited"
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{limitedMsg}, nil).Once()
+		mockRepo.On("ReleaseMessage", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == limitedMsg.ID && m.Status == "pending" && m.AttemptCount == 0 && m.DeferredReason != nil
+		})).Return(nil).Once()
+
+		_, err := limitedService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), limiter.waits.Load())
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, limitedMsg.Recipient, limitedMsg.Content)
+	})
+
+	t.Run("Priority Reserve", func(t *testing.T) {
+		reserveService := NewMessageService(mockRepo, senders, logger, mockCache, 2, 10*time.Second, "instance-1", time.Minute, RetryPolicy{}, 0, 0.2, nil, nil, RecipientPolicy{}, nil, nil)
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(10), int32(2)).Return([]Message{}, nil).Once()
+
+		_, err := reserveService.FetchAndSendPending(context.Background(), 10)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Webhook Fails", func(t *testing.T) {
 		webhookErr := errors.New("webhook failed")
-		mockRepo.On("GetPendingMessages", mock.Anything, int32(1)).Return([]Message{pendingMsg}, nil).Once()
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("", webhookErr).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(a Attempt) bool {
+			return a.MessageID == claimedMsg.ID && !a.Succeeded && *a.Error == webhookErr.Error()
+		})).Return(nil).Once()
+		mockRepo.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == claimedMsg.ID && m.Status == "failed"
+		})).Return(nil).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+		mockCache.AssertNotCalled(t, "CacheSentMessage")
+	})
+
+	t.Run("Webhook Fails - Retry Scheduled", func(t *testing.T) {
+		retryService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+		retryableMsg := claimedMsg
+		retryableMsg.AttemptCount = 2
+		retryableMsg.MaxAttempts = 3
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{retryableMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, retryableMsg.Recipient, retryableMsg.Content).Return("", errors.New("503 service unavailable")).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		before := time.Now().UTC()
+		mockRepo.On("ScheduleRetry", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			// Second failed attempt backs off for twice the base delay.
+			return m.ID == retryableMsg.ID && m.Status == "pending" &&
+				m.NextAttemptAt != nil && !m.NextAttemptAt.Before(before.Add(2*time.Minute)) &&
+				m.LastFailureReason != nil
+		})).Return(nil).Once()
+
+		_, err := retryService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Webhook Fails - Permanent Error Dead-Lettered", func(t *testing.T) {
+		retryService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+		retryableMsg := claimedMsg
+		retryableMsg.AttemptCount = 1
+		retryableMsg.MaxAttempts = 3
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{retryableMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, retryableMsg.Recipient, retryableMsg.Content).Return("", classifiedError{retryable: false}).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == retryableMsg.ID && m.Status == "failed"
+		})).Return(nil).Once()
+
+		_, err := retryService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Webhook Fails - Throttled Honours Retry-After", func(t *testing.T) {
+		retryService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Hour}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+		retryableMsg := claimedMsg
+		retryableMsg.AttemptCount = 1
+		retryableMsg.MaxAttempts = 3
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{retryableMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, retryableMsg.Recipient, retryableMsg.Content).Return("", classifiedError{retryable: true, retryDelay: 10 * time.Minute}).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		before := time.Now().UTC()
+		mockRepo.On("ScheduleRetry", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == retryableMsg.ID && !m.NextAttemptAt.Before(before.Add(10*time.Minute))
+		})).Return(nil).Once()
+
+		_, err := retryService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Subject Sent Through SubjectSender", func(t *testing.T) {
+		emailSender := new(MockSubjectSender)
+		senders.Register(ChannelEmail, emailSender)
+		emailMsg := Message{ID: "msg2", Channel: ChannelEmail, Subject: "Hello", Content: "test", Recipient: "jane@example.org", Status: "sending"}
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{emailMsg}, nil).Once()
+		emailSender.On("SendWithSubject", mock.Anything, emailMsg.Recipient, emailMsg.Subject, emailMsg.Content).Return("<id@example.com>", "", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == emailMsg.ID && m.Status == "sent" && *m.ExternalMessageID == "<id@example.com>"
+		})).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, emailMsg.ID, "<id@example.com>", mock.Anything).Return(nil).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		emailSender.AssertExpectations(t)
+		emailSender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Provider Recorded Through ProviderSender", func(t *testing.T) {
+		providerSender := new(MockProviderSender)
+		providerSenders := NewSenderRegistry()
+		providerSenders.Register(ChannelSMS, providerSender)
+		providerService := NewMessageService(mockRepo, providerSenders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		providerSender.On("SendWithProvider", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("ext-backup-1", "backup", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(a Attempt) bool {
+			return a.Succeeded && a.Provider == "backup"
+		})).Return(nil).Once()
 		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
-			return m.ID == pendingMsg.ID && m.Status == "sending"
+			return m.ID == claimedMsg.ID && m.Status == "sent" && m.Provider == "backup"
+		})).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, claimedMsg.ID, "ext-backup-1", mock.Anything).Return(nil).Once()
+
+		_, err := providerService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		providerSender.AssertExpectations(t)
+	})
+
+	t.Run("Provider Recorded Through SubjectSender", func(t *testing.T) {
+		// Senders such as the sms circuit breaker implement SubjectSender and report the provider from it.
+		subjectSender := new(MockSubjectSender)
+		subjectSenders := NewSenderRegistry()
+		subjectSenders.Register(ChannelSMS, subjectSender)
+		subjectService := NewMessageService(mockRepo, subjectSenders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+		subjectMsg := claimedMsg
+		subjectMsg.Subject = "Reminder"
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{subjectMsg}, nil).Once()
+		subjectSender.On("SendWithSubject", mock.Anything, subjectMsg.Recipient, subjectMsg.Subject, subjectMsg.Content).Return("ext-backup-2", "backup", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(a Attempt) bool {
+			return a.Succeeded && a.Provider == "backup"
 		})).Return(nil).Once()
-		mockWebhook.On("Send", mock.Anything, pendingMsg.Recipient, pendingMsg.Content).Return("", webhookErr).Once()
 		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
-			return m.ID == pendingMsg.ID && m.Status == "failed"
+			return m.ID == subjectMsg.ID && m.Status == "sent" && m.Provider == "backup"
+		})).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, subjectMsg.ID, "ext-backup-2", mock.Anything).Return(nil).Once()
+
+		_, err := subjectService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		subjectSender.AssertExpectations(t)
+	})
+
+	t.Run("Sender Unavailable - Released Without Attempt", func(t *testing.T) {
+		unavailableMsg := claimedMsg
+		unavailableMsg.AttemptCount = 2
+		unavailableMsg.MaxAttempts = 2
+		unavailableErr := fmt.Errorf("%w: %w", ErrSenderUnavailable, classifiedError{retryable: true, retryDelay: time.Minute})
+		before := time.Now().UTC()
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{unavailableMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, unavailableMsg.Recipient, unavailableMsg.Content).Return("", unavailableErr).Once()
+		mockRepo.On("ReleaseMessage", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == unavailableMsg.ID && m.Status == "pending" && m.AttemptCount == 1 &&
+				m.NextAttemptAt != nil && !m.NextAttemptAt.Before(before.Add(time.Minute)) &&
+				m.DeferredReason != nil
 		})).Return(nil).Once()
 
-		err := service.FetchAndSendPending(context.Background(), 1)
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Quiet Hours - Deferred Without Attempt", func(t *testing.T) {
+		// Quiet hours around the current time of day in UTC.
+		now := time.Now().UTC()
+		hour := time.Duration(now.Hour()) * time.Hour
+		quietHours := QuietHours{Start: hour, End: (hour + 2*time.Hour) % (24 * time.Hour)}
+		limiter := &fakeRateLimiter{available: 1}
+		quietService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{}, 0, 0, limiter, nil, RecipientPolicy{QuietHours: quietHours}, nil, nil)
+		quietMsg := claimedMsg
+		quietMsg.AttemptCount = 1
+		quietMsg.Priority = PriorityNormal
+		quietMsg.Timezone = "UTC"
+		quietMsg.Content = "quiet hours"
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{quietMsg}, nil).Once()
+		mockRepo.On("ReleaseMessage", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == quietMsg.ID && m.Status == "pending" && m.AttemptCount == 0 &&
+				m.NextAttemptAt != nil && m.NextAttemptAt.After(now) &&
+				m.DeferredReason != nil && strings.HasPrefix(*m.DeferredReason, "quiet hours in UTC until")
+		})).Return(nil).Once()
+
+		_, err := quietService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, quietMsg.Recipient, quietMsg.Content)
+		// Held back messages do not take a send token.
+		assert.Equal(t, int32(0), limiter.waits.Load())
+	})
+
+	t.Run("Quiet Hours - Critical Messages Sent", func(t *testing.T) {
+		now := time.Now().UTC()
+		hour := time.Duration(now.Hour()) * time.Hour
+		quietHours := QuietHours{Start: hour, End: (hour + 2*time.Hour) % (24 * time.Hour)}
+		quietService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{QuietHours: quietHours}, nil, nil)
+		criticalMsg := claimedMsg
+		criticalMsg.Priority = PriorityCritical
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{criticalMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, criticalMsg.Recipient, criticalMsg.Content).Return("ext-critical", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == criticalMsg.ID && m.Status == "sent"
+		})).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, criticalMsg.ID, "ext-critical", mock.Anything).Return(nil).Once()
+
+		_, err := quietService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("Recipient Limit - Throttled Without Attempt", func(t *testing.T) {
+		counter := new(MockRecipientCounter)
+		throttledService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{}, 0, 0, nil, counter, RecipientPolicy{MaxPerWindow: 3, Window: time.Hour}, nil, nil)
+		throttledMsg := claimedMsg
+		throttledMsg.AttemptCount = 1
+		throttledMsg.Content = "throttled"
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{throttledMsg}, nil).Once()
+		counter.On("CountRecipientSend", mock.Anything, ChannelSMS, throttledMsg.Recipient, mock.Anything, time.Hour).Return(int64(4), nil).Once()
+		counter.On("UncountRecipientSend", mock.Anything, ChannelSMS, throttledMsg.Recipient, mock.Anything, time.Hour).Return(nil).Once()
+		mockRepo.On("ReleaseMessage", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == throttledMsg.ID && m.Status == "pending" && m.AttemptCount == 0 &&
+				m.NextAttemptAt != nil && m.NextAttemptAt.Equal(m.NextAttemptAt.Truncate(time.Hour)) &&
+				m.DeferredReason != nil && strings.HasPrefix(*m.DeferredReason, "recipient limit of 3 messages per 1h0m0s reached")
+		})).Return(nil).Once()
+
+		_, err := throttledService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		counter.AssertExpectations(t)
+		mockSender.AssertNotCalled(t, "Send", mock.Anything, throttledMsg.Recipient, throttledMsg.Content)
+	})
+
+	t.Run("Recipient Limit - Failed Send Gives Back Count", func(t *testing.T) {
+		counter := new(MockRecipientCounter)
+		countedService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}, 0, 0, nil, counter, RecipientPolicy{MaxPerWindow: 3, Window: time.Hour}, nil, nil)
+		failingMsg := claimedMsg
+		failingMsg.AttemptCount = 1
+		failingMsg.MaxAttempts = 3
+		failingMsg.Content = "fails once"
+		var countedIn time.Time
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{failingMsg}, nil).Once()
+		counter.On("CountRecipientSend", mock.Anything, ChannelSMS, failingMsg.Recipient, mock.Anything, time.Hour).Run(func(args mock.Arguments) {
+			countedIn = args.Get(3).(time.Time)
+		}).Return(int64(1), nil).Once()
+		mockSender.On("Send", mock.Anything, failingMsg.Recipient, failingMsg.Content).Return("", errors.New("503 service unavailable")).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("ScheduleRetry", mock.Anything, mock.Anything).Return(nil).Once()
+		counter.On("UncountRecipientSend", mock.Anything, ChannelSMS, failingMsg.Recipient, mock.MatchedBy(func(windowStart time.Time) bool {
+			return windowStart.Equal(countedIn)
+		}), time.Hour).Return(nil).Once()
+
+		_, err := countedService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		counter.AssertExpectations(t)
+	})
+
+	t.Run("Recipient Limit - Critical Messages Not Counted", func(t *testing.T) {
+		counter := new(MockRecipientCounter)
+		countedService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{}, 0, 0, nil, counter, RecipientPolicy{MaxPerWindow: 3, Window: time.Hour}, nil, nil)
+		criticalMsg := claimedMsg
+		criticalMsg.Priority = PriorityCritical
+		criticalMsg.Content = "critical"
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{criticalMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, criticalMsg.Recipient, criticalMsg.Content).Return("ext-critical-2", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, criticalMsg.ID, "ext-critical-2", mock.Anything).Return(nil).Once()
+
+		_, err := countedService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		counter.AssertNotCalled(t, "CountRecipientSend", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Recipient Limit - Counter Down Sends Anyway", func(t *testing.T) {
+		counter := new(MockRecipientCounter)
+		throttledService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{}, 0, 0, nil, counter, RecipientPolicy{MaxPerWindow: 3, Window: time.Hour}, nil, nil)
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		counter.On("CountRecipientSend", mock.Anything, ChannelSMS, claimedMsg.Recipient, mock.Anything, time.Hour).Return(int64(0), errors.New("redis down")).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("ext-counted", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == claimedMsg.ID && m.Status == "sent"
+		})).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, claimedMsg.ID, "ext-counted", mock.Anything).Return(nil).Once()
+
+		_, err := throttledService.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		counter.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported Channel - Dead-Lettered", func(t *testing.T) {
+		retryService := NewMessageService(mockRepo, senders, logger, mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+			RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+		pigeonMsg := claimedMsg
+		pigeonMsg.Channel = "pigeon"
+		pigeonMsg.AttemptCount = 1
+		pigeonMsg.MaxAttempts = 3
+
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{pigeonMsg}, nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(a Attempt) bool {
+			return !a.Succeeded && a.Error != nil
+		})).Return(nil).Once()
+		mockRepo.On("MoveToDeadLetter", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == pigeonMsg.ID && m.Status == "failed"
+		})).Return(nil).Once()
+
+		_, err := retryService.FetchAndSendPending(context.Background(), 1)
 		assert.NoError(t, err)
 
 		mockRepo.AssertExpectations(t)
-		mockWebhook.AssertExpectations(t)
-		mockCache.AssertNotCalled(t, "CacheSentMessage")
+	})
+}
+
+func TestMessageService_StatusCallbacks(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockSender := new(MockSender)
+	senders := NewSenderRegistry()
+	senders.Register(ChannelSMS, mockSender)
+	mockCache := new(MockCacheService)
+	mockNotifier := new(MockStatusNotifier)
+	service := NewMessageService(mockRepo, senders, zap.NewNop(), mockCache, 1, 10*time.Second, "instance-1", time.Minute,
+		RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}, 0, 0, nil, nil, RecipientPolicy{}, nil, mockNotifier)
+
+	claimedMsg := Message{ID: "msg1", Channel: ChannelSMS, Content: "test", Recipient: "+123", Status: "sending",
+		AttemptCount: 1, MaxAttempts: 3, CallbackURL: "https://example.com/hooks"}
+
+	t.Run("Sent", func(t *testing.T) {
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("ext-123", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, claimedMsg.ID, "ext-123", mock.Anything).Return(nil).Once()
+		mockNotifier.On("NotifyStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == claimedMsg.ID && m.Status == "sent" && *m.ExternalMessageID == "ext-123"
+		})).Return(nil).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("Failed For Good", func(t *testing.T) {
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("", classifiedError{retryable: false}).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("MoveToDeadLetter", mock.Anything, mock.Anything).Return(nil).Once()
+		// A failure to queue the callback does not change the outcome of the send.
+		mockNotifier.On("NotifyStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == claimedMsg.ID && m.Status == "failed" && m.LastFailureReason != nil
+		})).Return(errors.New("db down")).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("Retry Scheduled Is Not Terminal", func(t *testing.T) {
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("", errors.New("503 service unavailable")).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("ScheduleRetry", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertNumberOfCalls(t, "NotifyStatus", 2)
+	})
+
+	t.Run("No Callback URL", func(t *testing.T) {
+		noCallbackMsg := claimedMsg
+		noCallbackMsg.CallbackURL = ""
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{noCallbackMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, noCallbackMsg.Recipient, noCallbackMsg.Content).Return("ext-456", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		mockCache.On("CacheSentMessage", mock.Anything, noCallbackMsg.ID, "ext-456", mock.Anything).Return(nil).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertNumberOfCalls(t, "NotifyStatus", 2)
+	})
+
+	t.Run("Lease Lost After Send", func(t *testing.T) {
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("ext-789", nil).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateMessageStatus", mock.Anything, mock.Anything).Return(ErrLeaseLost).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		// The message now belongs to the sweeper or another instance, so nothing is notified or cached.
+		mockNotifier.AssertNumberOfCalls(t, "NotifyStatus", 2)
+		mockCache.AssertNotCalled(t, "CacheSentMessage", mock.Anything, claimedMsg.ID, "ext-789", mock.Anything)
+	})
+
+	t.Run("Lease Lost Before Dead-Letter", func(t *testing.T) {
+		mockRepo.On("ClaimPendingMessages", mock.Anything, "instance-1", time.Minute, int32(1), int32(0)).Return([]Message{claimedMsg}, nil).Once()
+		mockSender.On("Send", mock.Anything, claimedMsg.Recipient, claimedMsg.Content).Return("", classifiedError{retryable: false}).Once()
+		mockRepo.On("RecordAttempt", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("MoveToDeadLetter", mock.Anything, mock.Anything).Return(ErrLeaseLost).Once()
+
+		_, err := service.FetchAndSendPending(context.Background(), 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertNumberOfCalls(t, "NotifyStatus", 2)
+	})
+
+	t.Run("Create With Callback URL", func(t *testing.T) {
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].CallbackURL == "https://example.com/hooks"
+		})).Return(nil).Once()
+
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, CallbackURL: "https://example.com/hooks"}, 100)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+
+		_, err = service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, CallbackURL: "/hooks"}, 100)
+		assert.ErrorIs(t, err, ErrInvalidCallbackURL)
+	})
+
+	t.Run("Callback URL To Internal Host", func(t *testing.T) {
+		for _, callbackURL := range []string{
+			"http://localhost:8080/hooks",
+			"http://127.0.0.1/hooks",
+			"http://169.254.169.254/latest/meta-data",
+			"http://10.0.0.5/hooks",
+			"http://[::1]/hooks",
+			"http://[::ffff:192.168.1.1]/hooks",
+		} {
+			_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, CallbackURL: callbackURL}, 100)
+			assert.ErrorIs(t, err, ErrInvalidCallbackURL, callbackURL)
+		}
+	})
+
+	t.Run("Callbacks Not Configured", func(t *testing.T) {
+		disabledService := NewMessageService(mockRepo, senders, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+
+		_, err := disabledService.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, CallbackURL: "https://example.com/hooks"}, 100)
+		assert.ErrorIs(t, err, ErrInvalidCallbackURL)
+	})
+}
+
+func TestMessageService_ApplyDeliveryReceipt(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockNotifier := new(MockStatusNotifier)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, mockNotifier)
+	receipt := DeliveryReceipt{ExternalMessageID: "ext-1", Status: ReceiptUndelivered, Reason: "handset off"}
+
+	t.Run("Applied", func(t *testing.T) {
+		mockRepo.On("GetMessageIDByExternalID", mock.Anything, "primary", "ext-1").Return("msg1", nil).Once()
+		mockRepo.On("GetMessageByID", mock.Anything, "msg1").Return(&Message{ID: "msg1", Status: "sent", CallbackURL: "https://example.com/hooks"}, nil).Once()
+		mockRepo.On("ApplyDeliveryReceipt", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == "msg1" && m.Status == ReceiptUndelivered && *m.LastFailureReason == "handset off"
+		})).Return(nil).Once()
+		mockNotifier.On("NotifyStatus", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == "msg1" && m.Status == ReceiptUndelivered
+		})).Return(nil).Once()
+
+		msg, err := service.ApplyDeliveryReceipt(context.Background(), "primary", receipt)
+		require.NoError(t, err)
+		assert.Equal(t, ReceiptUndelivered, msg.Status)
+		mockRepo.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+	})
+
+	t.Run("Repeated Receipt", func(t *testing.T) {
+		mockRepo.On("GetMessageIDByExternalID", mock.Anything, "primary", "ext-1").Return("msg1", nil).Once()
+		mockRepo.On("GetMessageByID", mock.Anything, "msg1").Return(&Message{ID: "msg1", Status: ReceiptUndelivered}, nil).Once()
+
+		msg, err := service.ApplyDeliveryReceipt(context.Background(), "primary", receipt)
+		require.NoError(t, err)
+		assert.Equal(t, ReceiptUndelivered, msg.Status)
+		mockRepo.AssertNumberOfCalls(t, "ApplyDeliveryReceipt", 1)
+		mockNotifier.AssertNumberOfCalls(t, "NotifyStatus", 1)
+	})
+
+	t.Run("Conflicting Receipt", func(t *testing.T) {
+		mockRepo.On("GetMessageIDByExternalID", mock.Anything, "primary", "ext-1").Return("msg1", nil).Once()
+		mockRepo.On("GetMessageByID", mock.Anything, "msg1").Return(&Message{ID: "msg1", Status: ReceiptDelivered}, nil).Once()
+
+		_, err := service.ApplyDeliveryReceipt(context.Background(), "primary", receipt)
+		assert.ErrorIs(t, err, ErrNotAwaitingReceipt)
+	})
+
+	t.Run("Unknown Message", func(t *testing.T) {
+		mockRepo.On("GetMessageIDByExternalID", mock.Anything, "primary", "ext-2").Return("", ErrMessageNotFound).Once()
+
+		_, err := service.ApplyDeliveryReceipt(context.Background(), "primary", DeliveryReceipt{ExternalMessageID: "ext-2", Status: ReceiptDelivered})
+		assert.ErrorIs(t, err, ErrMessageNotFound)
+	})
+
+	t.Run("Invalid Receipt", func(t *testing.T) {
+		_, err := service.ApplyDeliveryReceipt(context.Background(), "primary", DeliveryReceipt{ExternalMessageID: "ext-1", Status: "read"})
+		assert.ErrorIs(t, err, ErrInvalidReceipt)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_RecoverExpiredMessages(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		expected := RecoveryResult{Recovered: 2, Failed: 1}
+		mockRepo.On("RecoverExpiredMessages", mock.Anything, int32(3)).Return(expected, nil).Once()
+
+		result, err := service.RecoverExpiredMessages(context.Background(), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository Fails", func(t *testing.T) {
+		repoErr := errors.New("db error")
+		mockRepo.On("RecoverExpiredMessages", mock.Anything, int32(3)).Return(RecoveryResult{}, repoErr).Once()
+
+		_, err := service.RecoverExpiredMessages(context.Background(), 3)
+		assert.ErrorIs(t, err, repoErr)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_DeadLetters(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+
+	t.Run("Get Dead Letters", func(t *testing.T) {
+		expected := []DeadLetter{{MessageID: "1", FailureReason: "boom", AttemptCount: 5}}
+		mockRepo.On("GetDeadLetters", mock.Anything, int32(10), int32(0)).Return(expected, nil).Once()
+
+		deadLetters, err := service.GetDeadLetters(context.Background(), 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, deadLetters)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Get Dead Letters - Empty List", func(t *testing.T) {
+		mockRepo.On("GetDeadLetters", mock.Anything, int32(10), int32(0)).Return([]DeadLetter(nil), nil).Once()
+
+		deadLetters, err := service.GetDeadLetters(context.Background(), 10, 0)
+		assert.NoError(t, err)
+		assert.NotNil(t, deadLetters)
+		assert.Empty(t, deadLetters)
+	})
+
+	t.Run("Requeue - Not Found", func(t *testing.T) {
+		mockRepo.On("RequeueDeadLetter", mock.Anything, "missing").Return(ErrDeadLetterNotFound).Once()
+
+		err := service.RequeueDeadLetter(context.Background(), "missing")
+		assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+		mockRepo.AssertExpectations(t)
 	})
 }
 
 func TestMessageService_GetAllSentMessages(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
-	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		expectedMessages := []Message{{ID: "1", Status: "sent"}}
@@ -145,40 +928,384 @@ func TestMessageService_GetAllSentMessages(t *testing.T) {
 	})
 }
 
+func TestMessageService_GetMessageByID(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &Message{ID: "1", Status: "sent"}
+		mockRepo.On("GetMessageByID", mock.Anything, "1").Return(expected, nil).Once()
+
+		msg, err := service.GetMessageByID(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, msg)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo.On("GetMessageByID", mock.Anything, "2").Return(nil, ErrMessageNotFound).Once()
+
+		_, err := service.GetMessageByID(context.Background(), "2")
+		assert.ErrorIs(t, err, ErrMessageNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_ListMessages(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+	updatedAt := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+
+	t.Run("More Pages", func(t *testing.T) {
+		msgs := []Message{
+			{ID: "a1b2c3d4-e5f6-7890-1234-567890abcde1", UpdatedAt: updatedAt},
+			{ID: "a1b2c3d4-e5f6-7890-1234-567890abcde2", UpdatedAt: updatedAt},
+			{ID: "a1b2c3d4-e5f6-7890-1234-567890abcde3", UpdatedAt: updatedAt},
+		}
+		mockRepo.On("ListMessages", mock.Anything, MessageFilter{Statuses: []string{"sent"}, Limit: 3}).Return(msgs, nil).Once()
+
+		page, err := service.ListMessages(context.Background(), MessageFilter{Statuses: []string{"sent"}, Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, msgs[:2], page.Messages)
+		cursor, err := DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, Cursor{UpdatedAt: updatedAt, ID: msgs[1].ID}, *cursor)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Last Page", func(t *testing.T) {
+		mockRepo.On("ListMessages", mock.Anything, MessageFilter{Limit: 3}).Return([]Message(nil), nil).Once()
+
+		page, err := service.ListMessages(context.Background(), MessageFilter{Limit: 2})
+		assert.NoError(t, err)
+		assert.Empty(t, page.Messages)
+		assert.NotNil(t, page.Messages)
+		assert.Empty(t, page.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Invalid Status", func(t *testing.T) {
+		_, err := service.ListMessages(context.Background(), MessageFilter{Statuses: []string{"done"}, Limit: 2})
+		assert.ErrorIs(t, err, ErrInvalidStatus)
+	})
+}
+
+func TestMessageService_CancelMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil, RecipientPolicy{}, nil, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.On("GetMessageByID", mock.Anything, "1").Return(&Message{ID: "1", Status: "pending"}, nil).Once()
+		mockRepo.On("CancelMessage", mock.Anything, mock.MatchedBy(func(m Message) bool {
+			return m.ID == "1" && m.Status == "cancelled"
+		})).Return(nil).Once()
+
+		msg, err := service.CancelMessage(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, "cancelled", msg.Status)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Already Sent", func(t *testing.T) {
+		mockRepo.On("GetMessageByID", mock.Anything, "2").Return(&Message{ID: "2", Status: "sent"}, nil).Once()
+
+		_, err := service.CancelMessage(context.Background(), "2")
+		assert.ErrorIs(t, err, ErrNotCancellable)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Claimed Concurrently", func(t *testing.T) {
+		mockRepo.On("GetMessageByID", mock.Anything, "3").Return(&Message{ID: "3", Status: "pending"}, nil).Once()
+		mockRepo.On("CancelMessage", mock.Anything, mock.Anything).Return(ErrNotCancellable).Once()
+
+		_, err := service.CancelMessage(context.Background(), "3")
+		assert.ErrorIs(t, err, ErrNotCancellable)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("Batch", func(t *testing.T) {
+		mockRepo.On("CancelBatch", mock.Anything, "b1").Return(2, nil).Once()
+
+		cancelled, err := service.CancelBatch(context.Background(), "b1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, cancelled)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestMessageService_CreateMessages(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
-	service := NewMessageService(mockRepo, nil, zap.NewNop(), nil, 0, 0)
+	senders := NewSenderRegistry()
+	senders.Register(ChannelSMS, new(MockSender))
+	service := NewMessageService(mockRepo, senders, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{MaxAttempts: 4}, 24*time.Hour, 0, nil, nil, RecipientPolicy{}, nil, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		recipients := []string{"+111", "+222"}
 		content := "hello"
 		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
-			return len(msgs) == 2 && msgs[0].Recipient == "+111"
+			return len(msgs) == 2 && msgs[0].Recipient == "+111" && msgs[0].MaxAttempts == 4 &&
+				msgs[0].BatchID != "" && msgs[0].BatchID == msgs[1].BatchID
 		})).Return(nil).Once()
 
-		err := service.CreateMessages(context.Background(), content, recipients, 100)
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: content, Recipients: recipients}, 100)
 		assert.NoError(t, err)
+		assert.NotEmpty(t, batch.ID)
+		require.Len(t, batch.Messages, 2)
+		for i, msg := range batch.Messages {
+			assert.Equal(t, recipients[i], msg.Recipient)
+			assert.Equal(t, batch.ID, msg.BatchID)
+			assert.Equal(t, "pending", msg.Status)
+			assert.NotEmpty(t, msg.ID)
+		}
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Invalid Content", func(t *testing.T) {
 		recipients := []string{"+111"}
 		content := "too long"
-		err := service.CreateMessages(context.Background(), content, recipients, 5)
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: content, Recipients: recipients}, 5)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, ErrContentTooLong)
 		mockRepo.AssertNotCalled(t, "CreateMessages")
 	})
 
+	t.Run("Scheduled", func(t *testing.T) {
+		sendAt := time.Now().Add(time.Hour)
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].SendAt != nil && msgs[0].SendAt.Equal(sendAt)
+		})).Return(nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, SendAt: &sendAt}, 100)
+		assert.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		assert.True(t, batch.Messages[0].SendAt.Equal(sendAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Priority", func(t *testing.T) {
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Priority == PriorityCritical
+		})).Return(nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: "123456", Recipients: []string{"+111"}, Priority: PriorityCritical}, 100)
+		assert.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		assert.Equal(t, PriorityCritical, batch.Messages[0].Priority)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Chat Channel", func(t *testing.T) {
+		senders.Register(ChannelChat, new(MockSender))
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Channel == ChannelChat && msgs[0].Recipient == "#alerts"
+		})).Return(nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: "deploy done", Recipients: []string{"#alerts"}, Channel: ChannelChat}, 100)
+		assert.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		assert.Equal(t, ChannelChat, batch.Messages[0].Channel)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email Longer Than SMS Limit", func(t *testing.T) {
+		senders.Register(ChannelEmail, new(MockSender))
+		content := "<p>" + strings.Repeat("Your monthly report is ready. ", 20) + "</p>"
+		require.Greater(t, len(content), 250)
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Channel == ChannelEmail && msgs[0].Content == content
+		})).Return(nil).Once()
+
+		// The sms limit does not apply to email.
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: content, Recipients: []string{"jane@example.org"}, Channel: ChannelEmail, Subject: "Report"}, 250)
+		assert.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		mockRepo.AssertExpectations(t)
+
+		// It still applies to sms.
+		_, err = service.CreateMessages(context.Background(), BatchRequest{Content: content, Recipients: []string{"+111"}}, 250)
+		assert.ErrorIs(t, err, ErrContentTooLong)
+	})
+
+	t.Run("Invalid Recipient", func(t *testing.T) {
+		// An email address is not an sms recipient, nor the other way round.
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111", "jane@example.org"}}, 100)
+		assert.ErrorIs(t, err, ErrInvalidRecipient)
+		_, err = service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, Channel: ChannelEmail, Subject: "Hi"}, 100)
+		assert.ErrorIs(t, err, ErrInvalidRecipient)
+		mockRepo.AssertNotCalled(t, "CreateMessages")
+	})
+
+	t.Run("Unsupported Channel", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, Channel: "pigeon"}, 100)
+		assert.ErrorIs(t, err, ErrUnsupportedChannel)
+	})
+
+	t.Run("Invalid Priority", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, Priority: "urgent"}, 100)
+		assert.ErrorIs(t, err, ErrInvalidPriority)
+	})
+
+	t.Run("Timezone", func(t *testing.T) {
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Timezone == "America/New_York"
+		})).Return(nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, Timezone: "America/New_York"}, 100)
+		assert.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Timezone", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, Timezone: "Mars/Olympus_Mons"}, 100)
+		assert.ErrorIs(t, err, ErrInvalidTimezone)
+	})
+
+	t.Run("Scheduled Beyond Horizon", func(t *testing.T) {
+		sendAt := time.Now().Add(48 * time.Hour)
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111"}, SendAt: &sendAt}, 100)
+		assert.ErrorIs(t, err, ErrSendAtTooFar)
+	})
+
 	t.Run("Repository Fails", func(t *testing.T) {
 		repoErr := errors.New("db error")
 		recipients := []string{"+111"}
 		content := "hello"
 		mockRepo.On("CreateMessages", mock.Anything, mock.Anything).Return(repoErr).Once()
 
-		err := service.CreateMessages(context.Background(), content, recipients, 100)
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: content, Recipients: recipients}, 100)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), repoErr.Error())
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestMessageService_CreateMessages_Dedup(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	senders := NewSenderRegistry()
+	senders.Register(ChannelSMS, new(MockSender))
+	service := NewMessageService(mockRepo, senders, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil,
+		RecipientPolicy{DedupWindow: time.Hour}, nil, nil)
+
+	t.Run("Recent Duplicates Skipped", func(t *testing.T) {
+		before := time.Now()
+		mockRepo.On("FindRecentDuplicates", mock.Anything, ChannelSMS, "hello", []string{"+111", "+222", "+111"}, mock.MatchedBy(func(since time.Time) bool {
+			return !since.Before(before.Add(-time.Hour)) && since.Before(time.Now().Add(-59*time.Minute))
+		})).Return(map[string]string{"+222": "existing-id"}, nil).Once()
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Recipient == "+111"
+		})).Return(nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: "hello", Recipients: []string{"+111", "+222", "+111"}}, 100)
+		require.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		assert.Equal(t, []Duplicate{
+			{Recipient: "+222", MessageID: "existing-id"},
+			// Repeated in the same request.
+			{Recipient: "+111", MessageID: batch.Messages[0].ID},
+		}, batch.Deduplicated)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("All Duplicates", func(t *testing.T) {
+		mockRepo.On("FindRecentDuplicates", mock.Anything, ChannelSMS, "again", []string{"+333"}, mock.Anything).
+			Return(map[string]string{"+333": "existing-id"}, nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{Content: "again", Recipients: []string{"+333"}}, 100)
+		require.NoError(t, err)
+		assert.Empty(t, batch.Messages)
+		assert.Len(t, batch.Deduplicated, 1)
+		mockRepo.AssertNotCalled(t, "CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Content == "again"
+		}))
+	})
+
+	t.Run("Lookup Fails", func(t *testing.T) {
+		mockRepo.On("FindRecentDuplicates", mock.Anything, ChannelSMS, "broken", []string{"+444"}, mock.Anything).
+			Return(nil, errors.New("db error")).Once()
+
+		_, err := service.CreateMessages(context.Background(), BatchRequest{Content: "broken", Recipients: []string{"+444"}}, 100)
+		assert.ErrorContains(t, err, "db error")
+	})
+}
+
+func TestMessageService_CreateMessages_Template(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockTemplates := new(MockTemplateStore)
+	senders := NewSenderRegistry()
+	senders.Register(ChannelSMS, new(MockSender))
+	service := NewMessageService(mockRepo, senders, zap.NewNop(), nil, 0, 0, "instance-1", time.Minute, RetryPolicy{}, 0, 0, nil, nil,
+		RecipientPolicy{DedupWindow: time.Hour}, mockTemplates, nil)
+	mockTemplates.On("GetTemplate", mock.Anything, "tmpl-1").
+		Return(&templates.Template{ID: "tmpl-1", Name: "reminder", Subject: "For {{.name}}", Body: "Hi {{.name}}, see you at {{.time}}."}, nil)
+	mockTemplates.On("GetTemplate", mock.Anything, "missing").Return(nil, templates.ErrTemplateNotFound)
+
+	t.Run("Rendered Per Recipient", func(t *testing.T) {
+		// Each distinct content is checked for duplicates on its own.
+		mockRepo.On("FindRecentDuplicates", mock.Anything, ChannelSMS, "Hi Ann, see you at 9:00.", []string{"+111"}, mock.Anything).
+			Return(map[string]string{}, nil).Once()
+		mockRepo.On("FindRecentDuplicates", mock.Anything, ChannelSMS, "Hi Bob, see you at 10:00.", []string{"+222"}, mock.Anything).
+			Return(map[string]string{"+222": "existing-id"}, nil).Once()
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Recipient == "+111"
+		})).Return(nil).Once()
+
+		batch, err := service.CreateMessages(context.Background(), BatchRequest{
+			TemplateID: "tmpl-1",
+			Recipients: []string{"+111", "+222"},
+			Variables: map[string]map[string]any{
+				"+111": {"name": "Ann", "time": "9:00"},
+				"+222": {"name": "Bob", "time": "10:00"},
+			},
+		}, 100)
+		require.NoError(t, err)
+		require.Len(t, batch.Messages, 1)
+		assert.Equal(t, "Hi Ann, see you at 9:00.", batch.Messages[0].Content)
+		assert.Equal(t, "For Ann", batch.Messages[0].Subject)
+		assert.Equal(t, []Duplicate{{Recipient: "+222", MessageID: "existing-id"}}, batch.Deduplicated)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Request Subject Wins", func(t *testing.T) {
+		mockRepo.On("FindRecentDuplicates", mock.Anything, ChannelSMS, "Hi Cy, see you at 8:00.", []string{"+333"}, mock.Anything).
+			Return(map[string]string{}, nil).Once()
+		mockRepo.On("CreateMessages", mock.Anything, mock.MatchedBy(func(msgs []*Message) bool {
+			return len(msgs) == 1 && msgs[0].Subject == "Reminder"
+		})).Return(nil).Once()
+
+		_, err := service.CreateMessages(context.Background(), BatchRequest{
+			TemplateID: "tmpl-1",
+			Subject:    "Reminder",
+			Recipients: []string{"+333"},
+			Variables:  map[string]map[string]any{"+333": {"name": "Cy", "time": "8:00"}},
+		}, 100)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Rendered Content Too Long", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{
+			TemplateID: "tmpl-1",
+			Recipients: []string{"+111", "+222"},
+			Variables: map[string]map[string]any{
+				"+111": {"name": "Ann", "time": "9:00"},
+				"+222": {"name": "Bartholomew", "time": "10:00"},
+			},
+		}, 26)
+		assert.ErrorIs(t, err, ErrContentTooLong)
+		assert.ErrorContains(t, err, "+222")
+	})
+
+	t.Run("Missing Variable", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{
+			TemplateID: "tmpl-1",
+			Recipients: []string{"+111"},
+			Variables:  map[string]map[string]any{"+111": {"name": "Ann"}},
+		}, 100)
+		assert.ErrorIs(t, err, templates.ErrRenderFailed)
+	})
+
+	t.Run("Unknown Template", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{TemplateID: "missing", Recipients: []string{"+111"}}, 100)
+		assert.ErrorIs(t, err, templates.ErrTemplateNotFound)
+	})
+
+	t.Run("Content And Template", func(t *testing.T) {
+		_, err := service.CreateMessages(context.Background(), BatchRequest{TemplateID: "tmpl-1", Content: "hello", Recipients: []string{"+111"}}, 100)
+		assert.ErrorIs(t, err, ErrContentAndTemplate)
+	})
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+)
+
+// Sender wraps a messages.Sender so it sends no faster than its token bucket allows.
+// A send that cannot get a token before its context is done fails with a *LimitedError, which
+// wraps messages.ErrSenderUnavailable so the message is left 'pending' without using an attempt.
+type Sender struct {
+	sender messages.Sender
+	bucket *TokenBucket
+}
+
+func NewSender(sender messages.Sender, bucket *TokenBucket) *Sender {
+	return &Sender{
+		sender: sender,
+		bucket: bucket,
+	}
+}
+
+// Send waits for a token, then sends the content through the wrapped sender.
+func (s *Sender) Send(ctx context.Context, to, content string) (string, error) {
+	if err := s.bucket.Wait(ctx); err != nil {
+		limitedErr := &LimitedError{Err: err}
+		var waitErr *WaitTooLongError
+		if errors.As(err, &waitErr) {
+			limitedErr.Delay = waitErr.Delay
+		}
+		return "", limitedErr
+	}
+	return s.sender.Send(ctx, to, content)
+}
+
+// LimitedError is returned by Sender when no token is available in time. It implements
+// messages.ClassifiedError, so a FailoverSender moves on to the next provider and the message
+// is deferred until the token would have been ready.
+type LimitedError struct {
+	// Delay is how long until a token is available, zero if unknown.
+	Delay time.Duration
+	Err   error
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("%v: %v", messages.ErrSenderUnavailable, e.Err)
+}
+
+func (e *LimitedError) Unwrap() []error {
+	return []error{messages.ErrSenderUnavailable, e.Err}
+}
+
+// Retryable reports that the send may succeed once a token is available.
+func (e *LimitedError) Retryable() bool {
+	return true
+}
+
+// RetryDelay returns how long until a token is available.
+func (e *LimitedError) RetryDelay() time.Duration {
+	return e.Delay
+}
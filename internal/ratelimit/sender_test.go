@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSender is a mock of the messages.Sender interface.
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, to, content string) (string, error) {
+	args := m.Called(ctx, to, content)
+	return args.String(0), args.Error(1)
+}
+
+func TestSender_Send(t *testing.T) {
+	mockSender := new(MockSender)
+	sender := NewSender(mockSender, NewTokenBucket(1, 1))
+	mockSender.On("Send", mock.Anything, "+15551234567", "hello").Return("ext-1", nil).Once()
+
+	externalID, err := sender.Send(context.Background(), "+15551234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "ext-1", externalID)
+
+	// The next token is a second away, past the deadline of the send.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = sender.Send(ctx, "+15551234567", "hello")
+	assert.ErrorIs(t, err, messages.ErrSenderUnavailable)
+	var classified messages.ClassifiedError
+	require.ErrorAs(t, err, &classified)
+	assert.True(t, classified.Retryable())
+	assert.InDelta(t, time.Second, classified.RetryDelay(), float64(10*time.Millisecond))
+	mockSender.AssertExpectations(t)
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WaitTooLongError is returned by TokenBucket.Wait when the next token would only be ready after
+// the deadline of the context.
+type WaitTooLongError struct {
+	// Delay is how long the caller would have had to wait for the token.
+	Delay time.Duration
+}
+
+func (e *WaitTooLongError) Error() string {
+	return fmt.Sprintf("rate limit wait of %s would exceed the context deadline", e.Delay)
+}
+
+// TokenBucket is a token bucket rate limiter. It holds up to burst tokens, refilled at rate
+// tokens per second, and every call takes one token.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket returns a full bucket allowing rate calls per second with bursts of up to burst calls.
+// A burst below 1 is raised to 1.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := &TokenBucket{
+		rate:  rate,
+		burst: float64(max(burst, 1)),
+		now:   time.Now,
+	}
+	b.tokens = b.burst
+	b.last = b.now()
+	return b
+}
+
+// Allow takes a token if one is available and reports whether it did.
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Available returns the number of calls that may go through without waiting.
+func (b *TokenBucket) Available() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	return int(b.tokens)
+}
+
+// Wait blocks until a token is available and takes it. It returns an error without taking
+// the token if ctx is done first, or a *WaitTooLongError if its deadline is too close for the token to be ready.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.refill()
+	b.tokens--
+	if b.tokens >= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	// The token is reserved now, so concurrent callers queue up behind it.
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && b.now().Add(delay).After(deadline) {
+		b.tokens++
+		b.mu.Unlock()
+		return &WaitTooLongError{Delay: delay}
+	}
+	b.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// refill must be called with b.mu held.
+func (b *TokenBucket) refill() {
+	now := b.now()
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	if elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+	}
+}
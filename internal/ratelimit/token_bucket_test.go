@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket_Allow(t *testing.T) {
+	now := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	bucket := NewTokenBucket(2, 3)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	// The bucket starts full.
+	assert.Equal(t, 3, bucket.Available())
+	for range 3 {
+		assert.True(t, bucket.Allow())
+	}
+	assert.False(t, bucket.Allow())
+
+	// Two tokens are added per second.
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, bucket.Allow())
+	assert.False(t, bucket.Allow())
+
+	// It never holds more than the burst.
+	now = now.Add(time.Hour)
+	assert.Equal(t, 3, bucket.Available())
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	t.Run("Paces Callers", func(t *testing.T) {
+		bucket := NewTokenBucket(50, 1)
+		start := time.Now()
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, bucket.Wait(context.Background()))
+			}()
+		}
+		wg.Wait()
+
+		// The first call uses the burst, the other four wait 20ms each.
+		assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+	})
+
+	t.Run("Deadline Too Close", func(t *testing.T) {
+		bucket := NewTokenBucket(1, 1)
+		require.True(t, bucket.Allow())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		assert.Error(t, bucket.Wait(ctx))
+		assert.Less(t, time.Since(start), 10*time.Millisecond)
+	})
+
+	t.Run("Cancelled Wait Returns Its Token", func(t *testing.T) {
+		bucket := NewTokenBucket(10, 1)
+		require.True(t, bucket.Allow())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		assert.ErrorIs(t, bucket.Wait(ctx), context.Canceled)
+
+		// The cancelled caller did not keep its reservation, so the next token is ready after 100ms.
+		time.Sleep(100 * time.Millisecond)
+		assert.True(t, bucket.Allow())
+	})
+}
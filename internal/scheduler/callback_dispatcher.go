@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/config"
+	"go.uber.org/zap"
+)
+
+// CallbackDeliverer defines the interface for the callbacks service that the callback dispatcher will use.
+type CallbackDeliverer interface {
+	DeliverDue(ctx context.Context, limit int) (int, error)
+	DeleteFinished(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// CallbackDispatcher periodically posts due status callbacks, including retries of earlier failed posts,
+// and deletes the deliveries that finished longer than the retention ago.
+type CallbackDispatcher struct {
+	callbackService CallbackDeliverer
+	logger          *zap.Logger
+	config          config.CallbacksConfig
+	isRunning       atomic.Bool
+	stopChan        chan struct{}
+	wg              sync.WaitGroup
+}
+
+func NewCallbackDispatcher(service CallbackDeliverer,
+	logger *zap.Logger,
+	config config.CallbacksConfig) *CallbackDispatcher {
+
+	return &CallbackDispatcher{
+		callbackService: service,
+		logger:          logger,
+		config:          config,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start begins the dispatcher's loop in a new goroutine.
+func (d *CallbackDispatcher) Start() error {
+	if !d.isRunning.CompareAndSwap(false, true) {
+		return ErrAlreadyRunning
+	}
+
+	d.stopChan = make(chan struct{})
+	d.wg.Add(1)
+	go d.loop()
+
+	d.logger.Info("Callback dispatcher started.",
+		zap.Duration("runs_every", d.config.RunsEvery),
+		zap.Int("batch_size", d.config.BatchSize),
+		zap.Duration("retention", d.config.Retention),
+	)
+	return nil
+}
+
+// Stop gracefully shuts down the dispatcher, waiting for the callbacks being posted.
+func (d *CallbackDispatcher) Stop() error {
+	if !d.isRunning.CompareAndSwap(true, false) {
+		return ErrNotRunning
+	}
+
+	close(d.stopChan)
+	d.wg.Wait()
+	d.logger.Info("Callback dispatcher stopped gracefully.")
+	return nil
+}
+
+func (d *CallbackDispatcher) loop() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(d.config.RunsEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.dispatch()
+		case <-d.stopChan:
+			return
+		}
+	}
+}
+
+// dispatch runs a single delivery pass, followed by the cleanup of finished deliveries.
+func (d *CallbackDispatcher) dispatch() {
+	// Posts must finish before their lease expires, or another instance may post them again.
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.LeaseDuration)
+	defer cancel()
+
+	delivered, err := d.callbackService.DeliverDue(ctx, d.config.BatchSize)
+	if err != nil {
+		d.logger.Error("Callback dispatch failed.", zap.Error(err))
+	} else if delivered > 0 {
+		d.logger.Info("Callback dispatch completed.", zap.Int("claimed_count", delivered))
+	}
+
+	deleted, err := d.callbackService.DeleteFinished(ctx, d.config.Retention)
+	if err != nil {
+		d.logger.Error("Callback cleanup failed.", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		d.logger.Info("Deleted finished callback deliveries.", zap.Int64("deleted_count", deleted))
+	}
+}
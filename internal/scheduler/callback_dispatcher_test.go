@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockCallbackDeliverer is a mock implementation of the CallbackDeliverer interface.
+type MockCallbackDeliverer struct {
+	mock.Mock
+}
+
+func (m *MockCallbackDeliverer) DeliverDue(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCallbackDeliverer) DeleteFinished(ctx context.Context, retention time.Duration) (int64, error) {
+	args := m.Called(ctx, retention)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestCallbackDispatcher_StartStop(t *testing.T) {
+	dispatcher := NewCallbackDispatcher(new(MockCallbackDeliverer), zap.NewNop(), config.CallbacksConfig{RunsEvery: time.Hour})
+
+	assert.NoError(t, dispatcher.Start())
+	assert.Equal(t, ErrAlreadyRunning, dispatcher.Start())
+	assert.NoError(t, dispatcher.Stop())
+	assert.Equal(t, ErrNotRunning, dispatcher.Stop())
+}
+
+func TestCallbackDispatcher_DeliversEachRun(t *testing.T) {
+	mockService := new(MockCallbackDeliverer)
+	cfg := config.CallbacksConfig{RunsEvery: 20 * time.Millisecond, BatchSize: 5, LeaseDuration: time.Second, Retention: time.Hour}
+	dispatcher := NewCallbackDispatcher(mockService, zap.NewNop(), cfg)
+
+	var runs, cleanups atomic.Int32
+	countRun := func(mock.Arguments) { runs.Add(1) }
+	countCleanup := func(mock.Arguments) { cleanups.Add(1) }
+	// A failed run does not stop the dispatcher, nor the cleanup of that run.
+	mockService.On("DeliverDue", mock.Anything, 5).Return(0, errors.New("db error")).Run(countRun).Once()
+	mockService.On("DeliverDue", mock.Anything, 5).Return(2, nil).Run(countRun)
+	mockService.On("DeleteFinished", mock.Anything, time.Hour).Return(int64(0), errors.New("db error")).Run(countCleanup).Once()
+	mockService.On("DeleteFinished", mock.Anything, time.Hour).Return(int64(3), nil).Run(countCleanup)
+
+	dispatcher.Start()
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 3 && cleanups.Load() >= 3
+	}, time.Second, 10*time.Millisecond)
+	dispatcher.Stop()
+}
@@ -20,7 +20,8 @@ var (
 
 // MessageService defines the interface for the message service that the scheduler will use.
 type MessageDispatchScheduler interface {
-	FetchAndSendPending(ctx context.Context, limit int) error
+	// FetchAndSendPending sends a batch of up to limit pending messages and returns how many it claimed.
+	FetchAndSendPending(ctx context.Context, limit int) (int, error)
 }
 
 type MessageDispatchSchedulerImpl struct {
@@ -62,6 +63,7 @@ func (s *MessageDispatchSchedulerImpl) Start() error {
 		zap.Duration("runs_every", s.config.RunsEvery),
 		zap.Int("allowed_message_rate", s.config.MessageRate),
 		zap.Int("worker_count", s.workerPoolSize),
+		zap.String("instance_id", s.config.InstanceID),
 	)
 
 	return nil
@@ -120,7 +122,7 @@ func (s *MessageDispatchSchedulerImpl) execute() {
 	batchCtx, cancel := context.WithTimeout(context.Background(), processingTimeout)
 	defer cancel()
 
-	err := s.messageService.FetchAndSendPending(batchCtx, s.config.MessageRate)
+	err := s.dispatch(batchCtx)
 	if err != nil {
 		// Check if the error was due to our intentional cancellation.
 		if errors.Is(err, context.DeadlineExceeded) {
@@ -132,3 +134,25 @@ func (s *MessageDispatchSchedulerImpl) execute() {
 		s.logger.Info("Message processing batch completed successfully.")
 	}
 }
+
+// dispatch sends one batch of pending messages. With a send rate configured, throughput is
+// paced by the rate limiter rather than the tick, so it keeps pulling batches until none are
+// left, the tick runs out of time or the scheduler is stopped.
+func (s *MessageDispatchSchedulerImpl) dispatch(ctx context.Context) error {
+	for {
+		claimed, err := s.messageService.FetchAndSendPending(ctx, s.config.MessageRate)
+		if err != nil {
+			return err
+		}
+		if s.config.SendRate <= 0 || claimed == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stopChan:
+			return nil
+		default:
+		}
+	}
+}
@@ -16,13 +16,13 @@ type MockMessageService struct {
 	mock.Mock
 }
 
-func (m *MockMessageService) FetchAndSendPending(ctx context.Context, limit int) error {
+func (m *MockMessageService) FetchAndSendPending(ctx context.Context, limit int) (int, error) {
 	args := m.Called(ctx, limit)
 	// Simulate work
 	if delay, ok := ctx.Value("delay").(time.Duration); ok {
 		time.Sleep(delay)
 	}
-	return args.Error(0)
+	return args.Int(0), args.Error(1)
 }
 
 func TestScheduler_StartStop(t *testing.T) {
@@ -70,7 +70,7 @@ func TestScheduler_LoopExecution(t *testing.T) {
 	// Expect FetchAndSendPending to be called.
 	// We use a channel to wait for the call to happen.
 	callSignal := make(chan struct{})
-	mockService.On("FetchAndSendPending", mock.Anything, cfg.MessageRate).Return(nil).Run(func(args mock.Arguments) {
+	mockService.On("FetchAndSendPending", mock.Anything, cfg.MessageRate).Return(0, nil).Run(func(args mock.Arguments) {
 		// Signal that the method was called.
 		// Use a non-blocking send in case the test times out first.
 		select {
@@ -107,7 +107,7 @@ func TestScheduler_SkipOverlapExecution(t *testing.T) {
 	// The first call will be slow, causing the second tick to be skipped.
 	// The third tick should proceed as normal.
 	callCount := 0
-	mockService.On("FetchAndSendPending", mock.Anything, cfg.MessageRate).Return(nil).Run(func(args mock.Arguments) {
+	mockService.On("FetchAndSendPending", mock.Anything, cfg.MessageRate).Return(0, nil).Run(func(args mock.Arguments) {
 		callCount++
 		if callCount == 1 {
 			// Make the first call take longer than the tick interval.
@@ -127,3 +127,31 @@ func TestScheduler_SkipOverlapExecution(t *testing.T) {
 	// Assert that the mock was called exactly twice.
 	mockService.AssertNumberOfCalls(t, "FetchAndSendPending", 2)
 }
+
+func TestScheduler_ContinuousPullWithSendRate(t *testing.T) {
+	mockService := new(MockMessageService)
+	logger := zap.NewNop()
+	cfg := config.SchedulerConfig{
+		RunsEvery:   50 * time.Millisecond,
+		MessageRate: 10,
+		GracePeriod: 10 * time.Millisecond,
+		SendRate:    100,
+	}
+	scheduler := NewMessageDispatchSchedulerImpl(mockService, logger, cfg)
+
+	// A tick keeps pulling batches until one comes back empty.
+	drained := make(chan struct{})
+	mockService.On("FetchAndSendPending", mock.Anything, cfg.MessageRate).Return(10, nil).Twice()
+	mockService.On("FetchAndSendPending", mock.Anything, cfg.MessageRate).Return(0, nil).Run(func(args mock.Arguments) {
+		close(drained)
+	}).Once()
+
+	scheduler.execute()
+
+	select {
+	case <-drained:
+	default:
+		t.Fatal("tick returned before the pending messages were drained")
+	}
+	mockService.AssertNumberOfCalls(t, "FetchAndSendPending", 3)
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/config"
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"go.uber.org/zap"
+)
+
+// MessageRecoverer defines the interface for the message service that the recovery sweeper will use.
+type MessageRecoverer interface {
+	RecoverExpiredMessages(ctx context.Context, maxRecoveries int) (messages.RecoveryResult, error)
+}
+
+// IdempotencyKeyExpirer defines the interface for the idempotency key store that the recovery sweeper cleans up.
+type IdempotencyKeyExpirer interface {
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// RecoveryStats holds the cumulative outcome of all sweeps since the application started.
+type RecoveryStats struct {
+	Recovered int64
+	Failed    int64
+}
+
+// RecoverySweeper periodically returns messages stuck in 'sending' with an expired lease
+// back to 'pending', so crashed or timed-out sends are retried. It also deletes expired idempotency keys.
+type RecoverySweeper struct {
+	messageService MessageRecoverer
+	keys           IdempotencyKeyExpirer
+	logger         *zap.Logger
+	config         config.SchedulerConfig
+	recovered      atomic.Int64
+	failed         atomic.Int64
+	isRunning      atomic.Bool
+	stopChan       chan struct{}
+	wg             sync.WaitGroup
+}
+
+func NewRecoverySweeper(service MessageRecoverer,
+	keys IdempotencyKeyExpirer,
+	logger *zap.Logger,
+	config config.SchedulerConfig) *RecoverySweeper {
+
+	return &RecoverySweeper{
+		messageService: service,
+		keys:           keys,
+		logger:         logger,
+		config:         config,
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// Start begins the sweeper's loop in a new goroutine.
+func (s *RecoverySweeper) Start() error {
+	if !s.isRunning.CompareAndSwap(false, true) {
+		return ErrAlreadyRunning
+	}
+
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.loop()
+
+	s.logger.Info("Recovery sweeper started.",
+		zap.Duration("runs_every", s.config.RecoveryInterval),
+		zap.Duration("lease_duration", s.config.LeaseDuration),
+		zap.Int("max_recoveries", s.config.MaxRecoveries),
+	)
+	return nil
+}
+
+// Stop gracefully shuts down the sweeper.
+func (s *RecoverySweeper) Stop() error {
+	if !s.isRunning.CompareAndSwap(true, false) {
+		return ErrNotRunning
+	}
+
+	close(s.stopChan)
+	s.wg.Wait()
+	s.logger.Info("Recovery sweeper stopped gracefully.")
+	return nil
+}
+
+// Stats returns the number of messages recovered and failed since startup.
+func (s *RecoverySweeper) Stats() RecoveryStats {
+	return RecoveryStats{
+		Recovered: s.recovered.Load(),
+		Failed:    s.failed.Load(),
+	}
+}
+
+func (s *RecoverySweeper) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.RecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// sweep runs a single recovery pass, followed by the cleanup of expired idempotency keys.
+func (s *RecoverySweeper) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.RecoveryInterval)
+	defer cancel()
+
+	s.recover(ctx)
+
+	deleted, err := s.keys.DeleteExpired(ctx)
+	if err != nil {
+		s.logger.Error("Idempotency key cleanup failed.", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		s.logger.Info("Deleted expired idempotency keys.", zap.Int64("deleted_count", deleted))
+	}
+}
+
+// recover returns the messages with an expired lease to 'pending', or fails them.
+func (s *RecoverySweeper) recover(ctx context.Context) {
+	result, err := s.messageService.RecoverExpiredMessages(ctx, s.config.MaxRecoveries)
+	if err != nil {
+		s.logger.Error("Recovery sweep failed.", zap.Error(err))
+		return
+	}
+
+	s.recovered.Add(int64(result.Recovered))
+	s.failed.Add(int64(result.Failed))
+	if result.Recovered > 0 || result.Failed > 0 {
+		s.logger.Info("Recovery sweep completed.",
+			zap.Int("recovered_count", result.Recovered),
+			zap.Int("failed_count", result.Failed),
+			zap.Int64("total_recovered", s.recovered.Load()),
+			zap.Int64("total_failed", s.failed.Load()),
+		)
+	}
+}
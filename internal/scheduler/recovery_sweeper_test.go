@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akshaysangma/go-notify/internal/config"
+	"github.com/akshaysangma/go-notify/internal/messages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockMessageRecoverer is a mock implementation of the MessageRecoverer interface.
+type MockMessageRecoverer struct {
+	mock.Mock
+}
+
+func (m *MockMessageRecoverer) RecoverExpiredMessages(ctx context.Context, maxRecoveries int) (messages.RecoveryResult, error) {
+	args := m.Called(ctx, maxRecoveries)
+	return args.Get(0).(messages.RecoveryResult), args.Error(1)
+}
+
+// MockIdempotencyKeyExpirer is a mock implementation of the IdempotencyKeyExpirer interface.
+type MockIdempotencyKeyExpirer struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyKeyExpirer) DeleteExpired(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRecoverySweeper_StartStop(t *testing.T) {
+	sweeper := NewRecoverySweeper(new(MockMessageRecoverer), new(MockIdempotencyKeyExpirer), zap.NewNop(), config.SchedulerConfig{RecoveryInterval: time.Hour})
+
+	assert.NoError(t, sweeper.Start())
+	assert.Equal(t, ErrAlreadyRunning, sweeper.Start())
+	assert.NoError(t, sweeper.Stop())
+	assert.Equal(t, ErrNotRunning, sweeper.Stop())
+}
+
+func TestRecoverySweeper_AccumulatesStats(t *testing.T) {
+	mockService := new(MockMessageRecoverer)
+	cfg := config.SchedulerConfig{RecoveryInterval: 20 * time.Millisecond, MaxRecoveries: 3}
+	mockKeys := new(MockIdempotencyKeyExpirer)
+	mockKeys.On("DeleteExpired", mock.Anything).Return(int64(0), nil)
+	sweeper := NewRecoverySweeper(mockService, mockKeys, zap.NewNop(), cfg)
+
+	mockService.On("RecoverExpiredMessages", mock.Anything, 3).Return(messages.RecoveryResult{Recovered: 2, Failed: 1}, nil).Once()
+	mockService.On("RecoverExpiredMessages", mock.Anything, 3).Return(messages.RecoveryResult{}, errors.New("db error")).Once()
+	mockService.On("RecoverExpiredMessages", mock.Anything, 3).Return(messages.RecoveryResult{Recovered: 1}, nil)
+
+	sweeper.Start()
+	assert.Eventually(t, func() bool {
+		return sweeper.Stats().Recovered >= 3
+	}, time.Second, 10*time.Millisecond)
+	sweeper.Stop()
+
+	stats := sweeper.Stats()
+	assert.Equal(t, int64(1), stats.Failed)
+}
+
+func TestRecoverySweeper_DeletesExpiredIdempotencyKeys(t *testing.T) {
+	mockService, mockKeys := new(MockMessageRecoverer), new(MockIdempotencyKeyExpirer)
+	sweeper := NewRecoverySweeper(mockService, mockKeys, zap.NewNop(), config.SchedulerConfig{RecoveryInterval: 20 * time.Millisecond})
+
+	var cleanups atomic.Int32
+	countCleanup := func(mock.Arguments) { cleanups.Add(1) }
+	// Keys are cleaned up even when recovery fails, and a failed cleanup does not stop the sweeper.
+	mockService.On("RecoverExpiredMessages", mock.Anything, 0).Return(messages.RecoveryResult{}, errors.New("db error"))
+	mockKeys.On("DeleteExpired", mock.Anything).Return(int64(0), errors.New("db error")).Run(countCleanup).Once()
+	mockKeys.On("DeleteExpired", mock.Anything).Return(int64(5), nil).Run(countCleanup)
+
+	sweeper.Start()
+	assert.Eventually(t, func() bool {
+		return cleanups.Load() >= 3
+	}, time.Second, 10*time.Millisecond)
+	sweeper.Stop()
+}
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Domain-specific errors.
+var (
+	ErrTemplateNotFound = fmt.Errorf("template not found")
+	ErrInvalidTemplate  = fmt.Errorf("invalid template")
+	ErrNameTaken        = fmt.Errorf("template name already in use")
+	ErrRenderFailed     = fmt.Errorf("failed to render template")
+)
+
+// Template is a reusable message, written in Go text/template syntax, that is rendered
+// with the variables of each recipient when messages are created from it.
+type Template struct {
+	// The unique identifier for the template.
+	ID string `json:"id" example:"c3d4e5f6-a7b8-9012-3456-7890abcdef12"`
+	// The unique name of the template.
+	Name string `json:"name" example:"appointment-reminder"`
+	// The optional subject line, for channels that carry one such as email.
+	Subject string `json:"subject,omitempty" example:"Your appointment on {{.date}}"`
+	// The message content.
+	Body string `json:"body" example:"Hi {{.name}}, see you on {{.date}}."`
+	// The timestamp when the template was created.
+	CreatedAt time.Time `json:"created_at" example:"2025-07-09T10:00:00Z"`
+	// The timestamp when the template was last updated.
+	UpdatedAt time.Time `json:"updated_at" example:"2025-07-09T10:01:00Z"`
+}
+
+// Compiled is a parsed Template, ready to be rendered for many recipients.
+type Compiled struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// Validate checks that the template has a name and a body, and that its subject and body parse.
+func (t *Template) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidTemplate)
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("%w: body cannot be empty", ErrInvalidTemplate)
+	}
+	_, err := t.Compile()
+	return err
+}
+
+// Compile parses the subject and body of the template. Rendering fails on variables that are not supplied.
+func (t *Template) Compile() (*Compiled, error) {
+	body, err := template.New("body").Option("missingkey=error").Parse(t.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: body: %w", ErrInvalidTemplate, err)
+	}
+	compiled := &Compiled{body: body}
+	if t.Subject != "" {
+		compiled.subject, err = template.New("subject").Option("missingkey=error").Parse(t.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("%w: subject: %w", ErrInvalidTemplate, err)
+		}
+	}
+	return compiled, nil
+}
+
+// Render executes the template with vars. The subject is empty if the template has none.
+func (c *Compiled) Render(vars map[string]any) (subject, body string, err error) {
+	if c.subject != nil {
+		if subject, err = execute(c.subject, vars); err != nil {
+			return "", "", err
+		}
+	}
+	if body, err = execute(c.body, vars); err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+func execute(tmpl *template.Template, vars map[string]any) (string, error) {
+	if vars == nil {
+		// A nil map does not report missing keys.
+		vars = map[string]any{}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,56 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    Template
+		wantErr bool
+	}{
+		{"Valid", Template{Name: "reminder", Subject: "Hi {{.name}}", Body: "See you on {{.date}}."}, false},
+		{"Empty Name", Template{Body: "Hello"}, true},
+		{"Empty Body", Template{Name: "reminder", Body: "  "}, true},
+		{"Invalid Body", Template{Name: "reminder", Body: "Hi {{.name"}, true},
+		{"Invalid Subject", Template{Name: "reminder", Subject: "{{if}}", Body: "Hello"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tmpl.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTemplate)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCompiled_Render(t *testing.T) {
+	tmpl := Template{Name: "reminder", Subject: "Appointment on {{.date}}", Body: "Hi {{.name}}, see you on {{.date}}."}
+	compiled, err := tmpl.Compile()
+	require.NoError(t, err)
+
+	subject, body, err := compiled.Render(map[string]any{"name": "Ann", "date": "July 10"})
+	require.NoError(t, err)
+	assert.Equal(t, "Appointment on July 10", subject)
+	assert.Equal(t, "Hi Ann, see you on July 10.", body)
+
+	_, _, err = compiled.Render(map[string]any{"name": "Ann"})
+	assert.ErrorIs(t, err, ErrRenderFailed)
+	_, _, err = compiled.Render(nil)
+	assert.ErrorIs(t, err, ErrRenderFailed)
+
+	// Templates without a subject render an empty one.
+	noSubject, err := (&Template{Name: "plain", Body: "Hello"}).Compile()
+	require.NoError(t, err)
+	subject, body, err = noSubject.Render(nil)
+	require.NoError(t, err)
+	assert.Empty(t, subject)
+	assert.Equal(t, "Hello", body)
+}
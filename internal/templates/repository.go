@@ -0,0 +1,23 @@
+package templates
+
+import "context"
+
+// Repository defines the contract on Template entities.
+type Repository interface {
+	// Create stores a new template and returns it with its timestamps.
+	// Returns ErrNameTaken if another template has the same name.
+	Create(ctx context.Context, tmpl Template) (*Template, error)
+
+	// Get retrieves a template. Returns ErrTemplateNotFound if no template has the given ID.
+	Get(ctx context.Context, templateID string) (*Template, error)
+
+	// List retrieves a paginated list of templates ordered by name.
+	List(ctx context.Context, limit, offset int32) ([]Template, error)
+
+	// Update replaces the name, subject and body of a template.
+	// Returns ErrTemplateNotFound if it does not exist and ErrNameTaken if another template has the same name.
+	Update(ctx context.Context, tmpl Template) (*Template, error)
+
+	// Delete removes a template. Returns ErrTemplateNotFound if it does not exist.
+	Delete(ctx context.Context, templateID string) error
+}
@@ -0,0 +1,81 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Service manages message templates.
+type Service struct {
+	repo   Repository
+	logger *zap.Logger
+}
+
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateTemplate validates and stores a new template.
+func (s *Service) CreateTemplate(ctx context.Context, tmpl Template) (*Template, error) {
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	tmpl.ID = uuid.New().String()
+	created, err := s.repo.Create(ctx, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template %q: %w", tmpl.Name, err)
+	}
+	s.logger.Info("Created template", zap.String("template_id", created.ID), zap.String("name", created.Name))
+	return created, nil
+}
+
+// GetTemplate retrieves a template by ID.
+func (s *Service) GetTemplate(ctx context.Context, templateID string) (*Template, error) {
+	tmpl, err := s.repo.Get(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %s: %w", templateID, err)
+	}
+	return tmpl, nil
+}
+
+// ListTemplates take limit and offset to return paginated templates ordered by name.
+func (s *Service) ListTemplates(ctx context.Context, limit, offset int32) ([]Template, error) {
+	tmpls, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list templates", zap.Error(err))
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	if tmpls == nil {
+		return []Template{}, nil
+	}
+	return tmpls, nil
+}
+
+// UpdateTemplate validates and replaces the name, subject and body of a template.
+// Messages already created from it keep the content they were rendered with.
+func (s *Service) UpdateTemplate(ctx context.Context, tmpl Template) (*Template, error) {
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update template %s: %w", tmpl.ID, err)
+	}
+	s.logger.Info("Updated template", zap.String("template_id", updated.ID), zap.String("name", updated.Name))
+	return updated, nil
+}
+
+// DeleteTemplate removes a template. Messages already created from it are not affected.
+func (s *Service) DeleteTemplate(ctx context.Context, templateID string) error {
+	if err := s.repo.Delete(ctx, templateID); err != nil {
+		return fmt.Errorf("failed to delete template %s: %w", templateID, err)
+	}
+	s.logger.Info("Deleted template", zap.String("template_id", templateID))
+	return nil
+}
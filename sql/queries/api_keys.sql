@@ -0,0 +1,40 @@
+-- name: CreateAPIKey :one
+INSERT INTO notifications.api_keys (
+    id,
+    name,
+    key_prefix,
+    key_hash,
+    scopes
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING created_at;
+
+-- name: GetAPIKeyByHash :one
+-- Revoked keys are never returned, so they stop authenticating as soon as they are revoked.
+SELECT
+    id,
+    name,
+    key_prefix,
+    scopes,
+    created_at
+FROM notifications.api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL;
+
+-- name: ListAPIKeys :many
+SELECT
+    id,
+    name,
+    key_prefix,
+    scopes,
+    created_at,
+    (revoked_at IS NOT NULL)::boolean AS revoked
+FROM notifications.api_keys
+ORDER BY created_at ASC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE notifications.api_keys
+SET revoked_at = NOW()
+WHERE id = $1
+  AND revoked_at IS NULL;
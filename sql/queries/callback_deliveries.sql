@@ -0,0 +1,81 @@
+-- name: CreateCallbackDelivery :exec
+INSERT INTO notifications.callback_deliveries (
+    id,
+    message_id,
+    url,
+    payload,
+    max_attempts
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ClaimCallbackDeliveries :many
+-- Claims due deliveries oldest first, along with deliveries whose lease expired while they were being posted.
+WITH due AS (
+    SELECT id
+    FROM notifications.callback_deliveries
+    WHERE (status = 'pending' AND next_attempt_at <= NOW())
+       OR (status = 'delivering' AND lease_expires_at < NOW())
+    ORDER BY next_attempt_at ASC
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE notifications.callback_deliveries
+SET
+    status = 'delivering',
+    claimed_by = sqlc.arg(claimed_by)::text,
+    lease_expires_at = NOW() + sqlc.arg(lease_duration)::interval,
+    attempt_count = attempt_count + 1,
+    updated_at = NOW()
+WHERE id IN (SELECT id FROM due)
+RETURNING
+    id,
+    message_id,
+    url,
+    payload,
+    attempt_count,
+    max_attempts;
+
+-- name: DeleteFinishedCallbackDeliveries :execrows
+-- Deletes delivered and failed deliveries last updated before finished_before.
+DELETE FROM notifications.callback_deliveries
+WHERE status IN ('delivered', 'failed')
+  AND updated_at < sqlc.arg(finished_before);
+
+-- name: MarkCallbackDelivered :execrows
+-- Only the instance holding the claim may record the outcome, so a post that outlived its lease
+-- cannot overwrite the outcome of the instance that claimed the delivery after it.
+UPDATE notifications.callback_deliveries
+SET
+    status = 'delivered',
+    last_error = NULL,
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'delivering'
+  AND claimed_by = sqlc.arg(claimed_by);
+
+-- name: ScheduleCallbackRetry :execrows
+-- Like MarkCallbackDelivered, only the instance holding the claim may schedule the retry.
+UPDATE notifications.callback_deliveries
+SET
+    status = 'pending',
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_error = sqlc.arg(last_error),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'delivering'
+  AND claimed_by = sqlc.arg(claimed_by);
+
+-- name: MarkCallbackFailed :execrows
+-- Like MarkCallbackDelivered, only the instance holding the claim may give up on the delivery.
+UPDATE notifications.callback_deliveries
+SET
+    status = 'failed',
+    last_error = sqlc.arg(last_error),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'delivering'
+  AND claimed_by = sqlc.arg(claimed_by);
@@ -0,0 +1,50 @@
+-- name: CreateDeadLetterMessage :exec
+INSERT INTO notifications.dead_letter_messages (
+    message_id,
+    failure_reason,
+    attempt_count,
+    attempts
+)
+SELECT
+    m.id,
+    COALESCE(m.last_failure_reason, 'unknown'),
+    m.attempt_count,
+    COALESCE(
+        (
+            SELECT jsonb_agg(
+                jsonb_build_object(
+                    'attempt_number', a.attempt_number,
+                    'instance_id', a.instance_id,
+                    'succeeded', a.succeeded,
+                    'error', a.error,
+                    'external_message_id', a.external_message_id,
+                    'provider', a.provider,
+                    'attempted_at', a.attempted_at
+                ) ORDER BY a.attempted_at
+            )
+            FROM notifications.message_attempts a
+            WHERE a.message_id = m.id
+        ),
+        '[]'::jsonb
+    )
+FROM notifications.messages m
+WHERE m.id = $1
+ON CONFLICT (message_id) DO NOTHING;
+
+-- name: ListDeadLetterMessages :many
+SELECT
+    d.message_id,
+    m.content,
+    m.recipient,
+    d.failure_reason,
+    d.attempt_count,
+    d.attempts,
+    d.dead_lettered_at
+FROM notifications.dead_letter_messages d
+JOIN notifications.messages m ON m.id = d.message_id
+ORDER BY d.dead_lettered_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: DeleteDeadLetterMessage :execrows
+DELETE FROM notifications.dead_letter_messages
+WHERE message_id = $1;
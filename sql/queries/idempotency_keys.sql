@@ -0,0 +1,54 @@
+-- name: ReserveIdempotencyKey :one
+-- Inserts a new in-progress key, or takes over an expired one. Returns no rows if the key is still live.
+-- The ttl of an in-progress key is short, so keys of requests that never completed free up quickly.
+-- Keys are scoped to the API key of the request, so clients cannot collide on the same value.
+INSERT INTO notifications.idempotency_keys (
+    api_key_id,
+    key,
+    request_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, NOW() + sqlc.arg(ttl)::interval
+)
+ON CONFLICT (api_key_id, key) DO UPDATE
+SET
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    response_body = NULL,
+    created_at = NOW(),
+    expires_at = EXCLUDED.expires_at
+WHERE notifications.idempotency_keys.expires_at <= NOW()
+RETURNING key;
+
+-- name: GetIdempotencyKey :one
+SELECT
+    key,
+    request_hash,
+    status_code,
+    response_body,
+    created_at,
+    expires_at,
+    api_key_id
+FROM notifications.idempotency_keys
+WHERE api_key_id = $1
+  AND key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+-- Stores the response of a key and keeps it for the idempotency window from now.
+UPDATE notifications.idempotency_keys
+SET
+    status_code = $3,
+    response_body = $4,
+    expires_at = NOW() + sqlc.arg(ttl)::interval
+WHERE api_key_id = $1
+  AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+-- Removes keys past their expiry, which are only ignored by ReserveIdempotencyKey otherwise.
+DELETE FROM notifications.idempotency_keys
+WHERE expires_at <= NOW();
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM notifications.idempotency_keys
+WHERE api_key_id = $1
+  AND key = $2;
@@ -0,0 +1,27 @@
+-- name: CreateMessageAttempt :exec
+INSERT INTO notifications.message_attempts (
+    message_id,
+    attempt_number,
+    instance_id,
+    succeeded,
+    error,
+    external_message_id,
+    provider
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListMessageAttempts :many
+SELECT
+    id,
+    message_id,
+    attempt_number,
+    instance_id,
+    succeeded,
+    error,
+    external_message_id,
+    attempted_at,
+    provider
+FROM notifications.message_attempts
+WHERE message_id = $1
+ORDER BY attempted_at ASC, id ASC;
@@ -1,47 +1,279 @@
--- name: GetPendingMessages :many
-SELECT
+-- name: ClaimPendingMessages :many
+-- Most of the batch goes to the most urgent messages, oldest first. The last reserved_size slots
+-- go to the oldest of the remaining messages whatever their priority, so bulk traffic is never starved.
+WITH prioritized AS (
+    SELECT id
+    FROM notifications.messages
+    WHERE status = 'pending'
+      AND send_at <= NOW()
+      AND next_attempt_at <= NOW()
+    ORDER BY priority ASC, created_at ASC
+    LIMIT sqlc.arg(batch_size)::int - sqlc.arg(reserved_size)::int
+    FOR UPDATE SKIP LOCKED
+), reserved AS (
+    SELECT id
+    FROM notifications.messages
+    WHERE status = 'pending'
+      AND send_at <= NOW()
+      AND next_attempt_at <= NOW()
+      AND id NOT IN (SELECT id FROM prioritized)
+    ORDER BY created_at ASC
+    LIMIT sqlc.arg(reserved_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE notifications.messages
+SET
+    status = 'sending',
+    claimed_by = sqlc.arg(claimed_by)::text,
+    lease_expires_at = NOW() + sqlc.arg(lease_duration)::interval,
+    attempt_count = attempt_count + 1,
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM prioritized
+    UNION ALL
+    SELECT id FROM reserved
+)
+RETURNING
     id,
     content,
-    recipient_phone_number,
+    recipient,
+    channel,
+    subject,
+    timezone,
+    callback_url,
     status,
     external_message_id,
+    attempt_count,
+    max_attempts,
+    priority,
     created_at,
-    updated_at
-FROM notifications.messages
-WHERE status = 'pending'
-ORDER BY created_at ASC
-LIMIT $1;
+    updated_at;
 
--- name: UpdateMessageStatus :exec
+-- name: UpdateMessageStatus :execrows
+-- Only the instance holding the claim may record the outcome of a send, so a worker whose lease expired
+-- cannot overwrite a message the recovery sweeper released or another instance claimed again.
 UPDATE notifications.messages
 SET
     status = $3,
     external_message_id = $1,
+    provider = $5,
     updated_at = NOW(),
-    last_failure_reason = $4
-WHERE id = $2;
+    last_failure_reason = $4,
+    deferred_reason = NULL,
+    lease_expires_at = NULL
+WHERE id = $2
+  AND status = 'sending'
+  AND claimed_by = $6;
+
+-- name: RecoverExpiredMessages :many
+UPDATE notifications.messages
+SET
+    status = CASE
+        WHEN recovery_count >= sqlc.arg(max_recoveries)::int THEN 'failed'::notifications.message_status
+        ELSE 'pending'::notifications.message_status
+    END,
+    recovery_count = recovery_count + 1,
+    lease_expires_at = NULL,
+    last_failure_reason = 'lease expired while sending',
+    updated_at = NOW()
+WHERE status = 'sending'
+  AND lease_expires_at < NOW()
+RETURNING
+    id,
+    status,
+    external_message_id,
+    last_failure_reason,
+    callback_url;
+
+-- name: ReleaseMessage :execrows
+-- Returns a claimed message to 'pending' without consuming the attempt counted by the claim.
+-- Like UpdateMessageStatus, only the instance holding the claim may release it.
+UPDATE notifications.messages
+SET
+    status = 'pending',
+    attempt_count = GREATEST(attempt_count - 1, 0),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    deferred_reason = sqlc.arg(deferred_reason),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'sending'
+  AND claimed_by = sqlc.arg(claimed_by);
+
+-- name: RequeueMessage :exec
+UPDATE notifications.messages
+SET
+    status = 'pending',
+    attempt_count = 0,
+    recovery_count = 0,
+    next_attempt_at = NOW(),
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: ScheduleMessageRetry :execrows
+-- Like UpdateMessageStatus, only the instance holding the claim may schedule the retry.
+UPDATE notifications.messages
+SET
+    status = 'pending',
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_failure_reason = sqlc.arg(last_failure_reason),
+    deferred_reason = NULL,
+    lease_expires_at = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND status = 'sending'
+  AND claimed_by = sqlc.arg(claimed_by);
+
+-- name: CancelMessage :execrows
+-- Only pending messages are cancelled. A row locked by a concurrent claim is re-checked once the
+-- claim commits, so a message that moved to 'sending' is left alone.
+UPDATE notifications.messages
+SET
+    status = 'cancelled',
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'pending';
+
+-- name: GetMessageIDByExternalID :one
+SELECT id
+FROM notifications.messages
+WHERE provider = $1
+  AND external_message_id = $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ApplyDeliveryReceipt :execrows
+-- Only sent messages take a receipt, so a late or repeated receipt cannot overwrite the first one.
+UPDATE notifications.messages
+SET
+    status = $2,
+    last_failure_reason = $3,
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'sent';
+
+-- name: CancelBatchMessages :many
+UPDATE notifications.messages
+SET
+    status = 'cancelled',
+    updated_at = NOW()
+WHERE batch_id = $1
+  AND status = 'pending'
+RETURNING
+    id,
+    callback_url;
+
+-- name: BatchExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM notifications.messages
+    WHERE batch_id = $1
+);
 
 -- name: GetAllSentMessages :many
 SELECT
     id,
     content,
-    recipient_phone_number,
+    recipient,
     status,
     external_message_id,
     created_at,
     updated_at
 FROM notifications.messages
-WHERE status = 'sent'
+WHERE status IN ('sent', 'delivered', 'undelivered')
 ORDER BY updated_at DESC
 LIMIT $1 OFFSET $2;
 
+-- name: GetMessageByID :one
+SELECT
+    id,
+    content,
+    recipient,
+    channel,
+    subject,
+    timezone,
+    callback_url,
+    status,
+    external_message_id,
+    provider,
+    last_failure_reason,
+    deferred_reason,
+    attempt_count,
+    max_attempts,
+    priority,
+    next_attempt_at,
+    batch_id,
+    send_at,
+    created_at,
+    updated_at
+FROM notifications.messages
+WHERE id = $1;
+
+-- name: ListMessages :many
+-- Keyset paginated on (updated_at, id); pass the last row of the previous page as the cursor.
+SELECT
+    id,
+    content,
+    recipient,
+    channel,
+    subject,
+    timezone,
+    callback_url,
+    status,
+    external_message_id,
+    provider,
+    last_failure_reason,
+    deferred_reason,
+    attempt_count,
+    max_attempts,
+    priority,
+    batch_id,
+    send_at,
+    created_at,
+    updated_at
+FROM notifications.messages
+WHERE (cardinality(sqlc.arg(statuses)::text[]) = 0 OR status::text = ANY(sqlc.arg(statuses)::text[]))
+  AND (sqlc.narg(recipient)::text IS NULL OR recipient = sqlc.narg(recipient)::text)
+  AND (sqlc.narg(external_message_id)::text IS NULL OR external_message_id = sqlc.narg(external_message_id)::text)
+  AND (sqlc.arg(created_after)::timestamptz IS NULL OR created_at >= sqlc.arg(created_after)::timestamptz)
+  AND (sqlc.arg(created_before)::timestamptz IS NULL OR created_at < sqlc.arg(created_before)::timestamptz)
+  AND (sqlc.arg(updated_after)::timestamptz IS NULL OR updated_at >= sqlc.arg(updated_after)::timestamptz)
+  AND (sqlc.arg(updated_before)::timestamptz IS NULL OR updated_at < sqlc.arg(updated_before)::timestamptz)
+  AND (sqlc.arg(cursor_updated_at)::timestamptz IS NULL OR (updated_at, id) < (sqlc.arg(cursor_updated_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListRecentDuplicates :many
+-- Returns, for each recipient, the latest message with the same content created or sent since the given time.
+-- Failed, cancelled and undelivered messages never reached the recipient, so they are not duplicated by a new one.
+SELECT DISTINCT ON (recipient)
+    recipient,
+    id
+FROM notifications.messages
+WHERE recipient = ANY(sqlc.arg(recipients)::text[])
+  AND content_hash = sqlc.arg(content_hash)
+  AND channel = sqlc.arg(channel)
+  AND status NOT IN ('failed', 'cancelled', 'undelivered')
+  AND (created_at >= sqlc.arg(since)::timestamptz OR (status IN ('sent', 'delivered') AND updated_at >= sqlc.arg(since)::timestamptz))
+ORDER BY recipient, created_at DESC;
+
 -- name: CreateMessage :one
 INSERT INTO notifications.messages (
     id,
     content,
-    recipient_phone_number,
-    status
+    recipient,
+    channel,
+    status,
+    max_attempts,
+    batch_id,
+    priority,
+    subject,
+    timezone,
+    callback_url,
+    content_hash,
+    send_at
 ) VALUES (
-    $1, $2, $3, 'pending'
+    $1, $2, $3, $4, 'pending', $5, $6, $7, $8, $9, $10, $11, COALESCE(sqlc.arg(send_at)::timestamptz, NOW())
 )
 RETURNING id;   
\ No newline at end of file
@@ -0,0 +1,47 @@
+-- name: CreateTemplate :one
+INSERT INTO notifications.templates (
+    id,
+    name,
+    subject,
+    body
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING id, name, subject, body, created_at, updated_at;
+
+-- name: GetTemplate :one
+SELECT
+    id,
+    name,
+    subject,
+    body,
+    created_at,
+    updated_at
+FROM notifications.templates
+WHERE id = $1;
+
+-- name: ListTemplates :many
+SELECT
+    id,
+    name,
+    subject,
+    body,
+    created_at,
+    updated_at
+FROM notifications.templates
+ORDER BY name ASC
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateTemplate :one
+UPDATE notifications.templates
+SET
+    name = $2,
+    subject = $3,
+    body = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, subject, body, created_at, updated_at;
+
+-- name: DeleteTemplate :execrows
+DELETE FROM notifications.templates
+WHERE id = $1;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    ADD COLUMN claimed_by VARCHAR(255) NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS claimed_by;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    ADD COLUMN lease_expires_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN recovery_count INTEGER NOT NULL DEFAULT 0;
+
+-- Rows already stuck in 'sending' get an expired lease so the sweeper can recover them.
+UPDATE notifications.messages
+SET lease_expires_at = updated_at
+WHERE status = 'sending';
+
+CREATE INDEX idx_sending_messages_lease ON notifications.messages (lease_expires_at) WHERE status = 'sending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_sending_messages_lease;
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS recovery_count,
+    DROP COLUMN IF EXISTS lease_expires_at;
+-- +goose StatementEnd
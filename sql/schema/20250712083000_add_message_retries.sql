@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    ADD COLUMN attempt_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN max_attempts INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_pending_messages_next_attempt ON notifications.messages (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_pending_messages_next_attempt;
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS attempt_count;
+-- +goose StatementEnd
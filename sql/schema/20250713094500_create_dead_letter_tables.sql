@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notifications.message_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    message_id UUID NOT NULL REFERENCES notifications.messages (id) ON DELETE CASCADE,
+    attempt_number INTEGER NOT NULL,
+    instance_id VARCHAR(255) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    error TEXT NULL,
+    external_message_id VARCHAR(255) NULL,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_message_attempts_message_id ON notifications.message_attempts (message_id, attempted_at);
+
+CREATE TABLE notifications.dead_letter_messages (
+    message_id UUID PRIMARY KEY REFERENCES notifications.messages (id) ON DELETE CASCADE,
+    failure_reason TEXT NOT NULL,
+    attempt_count INTEGER NOT NULL,
+    attempts JSONB NOT NULL DEFAULT '[]'::jsonb,
+    dead_lettered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dead_letter_messages_dead_lettered_at ON notifications.dead_letter_messages (dead_lettered_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications.dead_letter_messages;
+DROP TABLE IF EXISTS notifications.message_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    ADD COLUMN batch_id UUID NULL;
+
+CREATE INDEX idx_messages_batch_id ON notifications.messages (batch_id) WHERE batch_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_messages_batch_id;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS batch_id;
+-- +goose StatementEnd
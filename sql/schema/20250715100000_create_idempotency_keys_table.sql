@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Idempotency-Key values seen on message creation. status_code and response_body (which holds the
+-- created message IDs) are NULL while the original request is still in progress.
+CREATE TABLE notifications.idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT NULL,
+    response_body JSONB NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON notifications.idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications.idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Supports keyset pagination on (updated_at, id) used by the message query API.
+CREATE INDEX idx_messages_updated_at_id ON notifications.messages (updated_at DESC, id DESC);
+CREATE INDEX idx_messages_recipient ON notifications.messages (recipient_phone_number);
+CREATE INDEX idx_messages_external_message_id ON notifications.messages (external_message_id) WHERE external_message_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_messages_external_message_id;
+DROP INDEX IF EXISTS notifications.idx_messages_recipient;
+DROP INDEX IF EXISTS notifications.idx_messages_updated_at_id;
+-- +goose StatementEnd
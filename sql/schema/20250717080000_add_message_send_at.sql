@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    ADD COLUMN send_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_pending_messages_send_at ON notifications.messages (send_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_pending_messages_send_at;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS send_at;
+-- +goose StatementEnd
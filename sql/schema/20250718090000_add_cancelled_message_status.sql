@@ -0,0 +1,17 @@
+-- +goose NO TRANSACTION
+-- ALTER TYPE ... ADD VALUE cannot be used in the transaction that adds it.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE notifications.message_status ADD VALUE IF NOT EXISTS 'cancelled';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Postgres cannot drop an enum value, so the value is kept and cancelled messages are marked failed instead.
+UPDATE notifications.messages
+SET
+    status = 'failed',
+    last_failure_reason = 'cancelled'
+WHERE status = 'cancelled';
+-- +goose StatementEnd
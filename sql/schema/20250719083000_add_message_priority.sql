@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Lower values are dispatched first: 0 critical, 1 high, 2 normal, 3 bulk.
+ALTER TABLE notifications.messages
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 2;
+
+ALTER TABLE notifications.messages
+    ADD CONSTRAINT chk_messages_priority CHECK (priority BETWEEN 0 AND 3);
+
+CREATE INDEX idx_pending_messages_priority ON notifications.messages (priority, created_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_pending_messages_priority;
+
+ALTER TABLE notifications.messages
+    DROP CONSTRAINT IF EXISTS chk_messages_priority;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The recipient is no longer always a phone number: it may be an email address, a device token or a chat channel.
+ALTER TABLE notifications.messages
+    RENAME COLUMN recipient_phone_number TO recipient;
+
+ALTER TABLE notifications.messages
+    ALTER COLUMN recipient TYPE VARCHAR(320);
+
+ALTER TABLE notifications.messages
+    ADD COLUMN channel VARCHAR(32) NOT NULL DEFAULT 'sms';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS channel;
+
+-- Fails if a recipient longer than a phone number was stored in the meantime.
+ALTER TABLE notifications.messages
+    ALTER COLUMN recipient TYPE VARCHAR(20);
+
+ALTER TABLE notifications.messages
+    RENAME COLUMN recipient TO recipient_phone_number;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Subject line for channels that carry one, such as email. 998 is the RFC 5322 line length limit.
+ALTER TABLE notifications.messages
+    ADD COLUMN subject VARCHAR(998) NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS subject;
+-- +goose StatementEnd
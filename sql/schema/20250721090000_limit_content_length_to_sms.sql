@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Only sms content is limited; email bodies and chat messages may be much longer.
+ALTER TABLE notifications.messages
+    DROP CONSTRAINT IF EXISTS content_length_check;
+
+ALTER TABLE notifications.messages
+    ADD CONSTRAINT content_length_check CHECK (channel <> 'sms' OR char_length(content) <= 250);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    DROP CONSTRAINT IF EXISTS content_length_check;
+
+-- Not validated, so longer messages stored in the meantime do not block the rollback.
+ALTER TABLE notifications.messages
+    ADD CONSTRAINT content_length_check CHECK (char_length(content) <= 250) NOT VALID;
+-- +goose StatementEnd
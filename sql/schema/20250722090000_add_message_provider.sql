@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Name of the provider that accepted the message, for channels spread over several providers.
+ALTER TABLE notifications.messages
+    ADD COLUMN provider VARCHAR(64) NULL;
+
+-- Name of the provider the attempt ended on.
+ALTER TABLE notifications.message_attempts
+    ADD COLUMN provider VARCHAR(64) NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications.message_attempts
+    DROP COLUMN IF EXISTS provider;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS provider;
+-- +goose StatementEnd
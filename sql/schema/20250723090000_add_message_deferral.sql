@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- IANA time zone of the recipient, used to hold messages back during quiet hours.
+ALTER TABLE notifications.messages
+    ADD COLUMN timezone VARCHAR(64) NULL;
+
+-- Why a pending message is held back without being attempted, e.g. quiet hours or a per-recipient limit.
+ALTER TABLE notifications.messages
+    ADD COLUMN deferred_reason TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS deferred_reason;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SHA-256 of the content, so duplicate messages to a recipient are found without comparing the text.
+ALTER TABLE notifications.messages
+    ADD COLUMN content_hash BYTEA NULL;
+
+UPDATE notifications.messages
+SET content_hash = sha256(convert_to(content, 'UTF8'));
+
+CREATE INDEX idx_messages_recipient_content_hash ON notifications.messages (recipient, content_hash, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS notifications.idx_messages_recipient_content_hash;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS content_hash;
+-- +goose StatementEnd
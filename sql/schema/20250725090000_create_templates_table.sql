@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Reusable message content rendered with Go text/template when messages are created from it.
+CREATE TABLE notifications.templates (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    subject TEXT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications.templates;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- URL notified of the terminal status of the message, if any.
+ALTER TABLE notifications.messages
+    ADD COLUMN callback_url TEXT NULL;
+
+-- Status events waiting to be, or already, posted to the callback URL of their message.
+-- Deliveries are claimed with a lease like messages, so one left 'delivering' by a crashed instance is picked up again.
+CREATE TABLE notifications.callback_deliveries (
+    id UUID PRIMARY KEY,
+    message_id UUID NOT NULL REFERENCES notifications.messages(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivering', 'delivered', 'failed')),
+    attempt_count INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    claimed_by VARCHAR(255) NULL,
+    lease_expires_at TIMESTAMP WITH TIME ZONE NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_callback_deliveries_due ON notifications.callback_deliveries (next_attempt_at)
+    WHERE status IN ('pending', 'delivering');
+
+CREATE INDEX idx_callback_deliveries_finished ON notifications.callback_deliveries (updated_at)
+    WHERE status IN ('delivered', 'failed');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications.callback_deliveries;
+
+ALTER TABLE notifications.messages
+    DROP COLUMN IF EXISTS callback_url;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose NO TRANSACTION
+-- ALTER TYPE ... ADD VALUE cannot be used in the transaction that adds it.
+
+-- +goose Up
+-- +goose StatementBegin
+-- Final states reported by the provider's delivery receipts for sent messages.
+ALTER TYPE notifications.message_status ADD VALUE IF NOT EXISTS 'delivered';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TYPE notifications.message_status ADD VALUE IF NOT EXISTS 'undelivered';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Postgres cannot drop an enum value, so the values are kept and the messages go back to sent.
+UPDATE notifications.messages
+SET
+    status = 'sent'
+WHERE status IN ('delivered', 'undelivered');
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keys clients authenticate with. Only the SHA-256 of each key is stored, along with its first
+-- characters so it can be recognised in listings. Revoked keys are kept for auditing.
+CREATE TABLE notifications.api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications.api_keys;
+-- +goose StatementEnd
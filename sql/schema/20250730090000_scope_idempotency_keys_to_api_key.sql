@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Idempotency-Key values are chosen by clients, so each API key gets its own namespace of them.
+-- api_key_id is empty for requests made while authentication is disabled.
+ALTER TABLE notifications.idempotency_keys
+    ADD COLUMN api_key_id VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE notifications.idempotency_keys
+    DROP CONSTRAINT idempotency_keys_pkey;
+
+ALTER TABLE notifications.idempotency_keys
+    ADD PRIMARY KEY (api_key_id, key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Keep a single record per key, so the key alone can be the primary key again.
+DELETE FROM notifications.idempotency_keys a
+USING notifications.idempotency_keys b
+WHERE a.key = b.key
+  AND a.api_key_id > b.api_key_id;
+
+ALTER TABLE notifications.idempotency_keys
+    DROP CONSTRAINT idempotency_keys_pkey;
+
+ALTER TABLE notifications.idempotency_keys
+    DROP COLUMN api_key_id;
+
+ALTER TABLE notifications.idempotency_keys
+    ADD PRIMARY KEY (key);
+-- +goose StatementEnd